@@ -11,11 +11,9 @@ import (
 	cfv1 "github.com/cloudflare/cloudflare-go"
 	"github.com/cloudflare/cloudflare-go/v3"
 	"github.com/cloudflare/cloudflare-go/v3/option"
-	"github.com/hashicorp/terraform-plugin-framework/providerserver"
-	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
 	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/acctest/vcr"
 	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/consts"
-	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/provider"
 )
 
 var (
@@ -33,13 +31,14 @@ var (
 	TestAccCloudflareAltZoneName string = "terraform2.cfapi.net"
 )
 
-var TestAccProtoV6ProviderFactories = map[string]func() (tfprotov6.ProviderServer, error){
-	"cloudflare-extended": func() (tfprotov6.ProviderServer, error) {
-		return providerserver.NewProtocol6(provider.New("dev")())(), nil
-	},
-}
+// Syntactically valid API token accepted by the offline mock API server.
+const MockAPIToken = "mockmockmockmockmockmockmockmockmockmock"
 
 func TestAccPreCheck(t *testing.T) {
+	if mode, _ := vcr.ModeFromEnv(); mode == vcr.ModeReplay && !recorder(t).Replaying() {
+		t.Skipf("no cassette recorded at %s, record one with %s=%s", vcr.CassettePath(t), vcr.ModeEnvVarKey, vcr.ModeRecord)
+	}
+
 	TestAccPreCheck_Credentials(t)
 }
 
@@ -259,7 +258,7 @@ func LoadTestCase(filename string, parameters ...interface{}) string {
 //
 //	resource.Test(t, resource.TestCase{
 //		PreCheck:                 func() { acctest.TestAccPreCheck(t) },
-//		ProtoV6ProviderFactories: acctest.TestAccProtoV6ProviderFactories,
+//		ProtoV6ProviderFactories: acctest.TestAccProtoV6ProviderFactories(t),
 //		Steps: []resource.TestStep{
 //			{
 //				Config: myConfig(),
@@ -0,0 +1,76 @@
+package acctest
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+)
+
+// AppliedDiagnostics collects the diagnostics the provider returns when
+// applying resource changes, which Terraform only prints.
+type AppliedDiagnostics struct {
+	mu    sync.Mutex
+	diags []*tfprotov6.Diagnostic
+}
+
+// TestAccProtoV6ProviderFactoriesWithDiagnostics returns the provider factories
+// of the acceptance test t, along with the diagnostics their providers return
+// when applying resource changes.
+func TestAccProtoV6ProviderFactoriesWithDiagnostics(t *testing.T) (map[string]func() (tfprotov6.ProviderServer, error), *AppliedDiagnostics) {
+	applied := &AppliedDiagnostics{}
+	factories := TestAccProtoV6ProviderFactories(t)
+	for name, factory := range factories {
+		factories[name] = func() (tfprotov6.ProviderServer, error) {
+			server, err := factory()
+			if err != nil {
+				return nil, err
+			}
+			return diagnosticsServer{server, applied}, nil
+		}
+	}
+
+	return factories, applied
+}
+
+// TestCheckWarning checks that a warning with the given summary, and a detail
+// matching detail, was returned since the previous check.
+func (a *AppliedDiagnostics) TestCheckWarning(summary string, detail *regexp.Regexp) func(*terraform.State) error {
+	return func(*terraform.State) error {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+
+		diags := a.diags
+		a.diags = nil
+
+		found := slices.ContainsFunc(diags, func(d *tfprotov6.Diagnostic) bool {
+			return d.Severity == tfprotov6.DiagnosticSeverityWarning && d.Summary == summary && detail.MatchString(d.Detail)
+		})
+		if !found {
+			return fmt.Errorf("no warning %q with detail matching %q among %d applied diagnostics", summary, detail, len(diags))
+		}
+		return nil
+	}
+}
+
+// diagnosticsServer is a provider server recording the diagnostics returned
+// when applying resource changes.
+type diagnosticsServer struct {
+	tfprotov6.ProviderServer
+	applied *AppliedDiagnostics
+}
+
+func (s diagnosticsServer) ApplyResourceChange(ctx context.Context, req *tfprotov6.ApplyResourceChangeRequest) (*tfprotov6.ApplyResourceChangeResponse, error) {
+	resp, err := s.ProviderServer.ApplyResourceChange(ctx, req)
+	if resp != nil {
+		s.applied.mu.Lock()
+		s.applied.diags = append(s.applied.diags, resp.Diagnostics...)
+		s.applied.mu.Unlock()
+	}
+	return resp, err
+}
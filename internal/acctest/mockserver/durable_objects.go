@@ -0,0 +1,173 @@
+package mockserver
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Error code returned when the migrations of a script upload can't be applied.
+const codeMigrationFailed = 10074
+
+type classRename struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type classTransfer struct {
+	From       string `json:"from"`
+	FromScript string `json:"from_script"`
+	To         string `json:"to"`
+}
+
+type migrationStep struct {
+	NewClasses         []string        `json:"new_classes"`
+	NewSqliteClasses   []string        `json:"new_sqlite_classes"`
+	RenamedClasses     []classRename   `json:"renamed_classes"`
+	DeletedClasses     []string        `json:"deleted_classes"`
+	TransferredClasses []classTransfer `json:"transferred_classes"`
+}
+
+type workerScriptMigrations struct {
+	OldTag string          `json:"old_tag"`
+	NewTag string          `json:"new_tag"`
+	Steps  []migrationStep `json:"steps"`
+	migrationStep
+}
+
+type durableObjectClass struct {
+	name   string
+	sqlite bool
+}
+
+type durableObjectNamespace struct {
+	ID        string `json:"id"`
+	Class     string `json:"class"`
+	Name      string `json:"name"`
+	Script    string `json:"script"`
+	UseSqlite bool   `json:"use_sqlite"`
+}
+
+func (s *Server) registerDurableObjectsRoutes(mux *http.ServeMux) {
+	route(mux, http.MethodGet, "/accounts/{account_id}/workers/durable_objects/namespaces", s.listDurableObjectNamespaces)
+}
+
+// WorkerScriptMigrationTag returns the tag of the last migration applied to the
+// named script.
+func (s *Server) WorkerScriptMigrationTag(accountID, name string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	script, ok := s.scripts[key(accountID, name)]
+	if !ok {
+		return ""
+	}
+	return script.MigrationTag
+}
+
+func (s *Server) listDurableObjectNamespaces(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prefix := r.PathValue("account_id") + "/"
+	namespaces := []durableObjectNamespace{}
+	for k, script := range s.scripts {
+		if !strings.HasPrefix(k, prefix) {
+			continue
+		}
+		for _, class := range script.classes {
+			id := sha256.Sum256([]byte(k + "/" + class.name))
+			namespaces = append(namespaces, durableObjectNamespace{
+				ID:        hex.EncodeToString(id[:16]),
+				Class:     class.name,
+				Name:      script.ID + "_" + class.name,
+				Script:    script.ID,
+				UseSqlite: class.sqlite,
+			})
+		}
+	}
+	sort.Slice(namespaces, func(i, j int) bool { return namespaces[i].Name < namespaces[j].Name })
+
+	writeResult(w, http.StatusOK, namespaces)
+}
+
+// applyMigrations returns the migration tag and Durable Object classes of a
+// script after applying the migrations of an upload. Like the API, a migration
+// whose new tag is already deployed is skipped.
+func applyMigrations(tag string, classes []durableObjectClass, raw json.RawMessage) (string, []durableObjectClass, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return tag, classes, nil
+	}
+
+	var migrations workerScriptMigrations
+	if err := json.Unmarshal(raw, &migrations); err != nil {
+		return "", nil, fmt.Errorf("invalid migrations: %w", err)
+	}
+
+	if migrations.NewTag != "" && migrations.NewTag == tag {
+		return tag, classes, nil
+	}
+	if migrations.OldTag != tag {
+		return "", nil, fmt.Errorf("migration old_tag %q does not match the current tag %q", migrations.OldTag, tag)
+	}
+
+	classes = slices.Clone(classes)
+	for _, step := range append([]migrationStep{migrations.migrationStep}, migrations.Steps...) {
+		var err error
+		classes, err = applyMigrationStep(classes, step)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+
+	return migrations.NewTag, classes, nil
+}
+
+func applyMigrationStep(classes []durableObjectClass, step migrationStep) ([]durableObjectClass, error) {
+	index := func(name string) int {
+		return slices.IndexFunc(classes, func(c durableObjectClass) bool { return c.name == name })
+	}
+
+	for _, name := range step.NewClasses {
+		if index(name) >= 0 {
+			return nil, fmt.Errorf("cannot create class %q, it already exists", name)
+		}
+		classes = append(classes, durableObjectClass{name: name})
+	}
+	for _, name := range step.NewSqliteClasses {
+		if index(name) >= 0 {
+			return nil, fmt.Errorf("cannot create class %q, it already exists", name)
+		}
+		classes = append(classes, durableObjectClass{name: name, sqlite: true})
+	}
+	for _, rename := range step.RenamedClasses {
+		i := index(rename.From)
+		if i < 0 {
+			return nil, fmt.Errorf("cannot rename class %q, it does not exist", rename.From)
+		}
+		if index(rename.To) >= 0 {
+			return nil, fmt.Errorf("cannot rename class %q to %q, it already exists", rename.From, rename.To)
+		}
+		classes[i].name = rename.To
+	}
+	for _, name := range step.DeletedClasses {
+		i := index(name)
+		if i < 0 {
+			return nil, fmt.Errorf("cannot delete class %q, it does not exist", name)
+		}
+		classes = slices.Delete(classes, i, i+1)
+	}
+	for _, transfer := range step.TransferredClasses {
+		if index(transfer.To) >= 0 {
+			return nil, fmt.Errorf("cannot transfer to class %q, it already exists", transfer.To)
+		}
+		classes = append(classes, durableObjectClass{name: transfer.To})
+	}
+
+	return classes, nil
+}
@@ -0,0 +1,247 @@
+package mockserver
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// Error code returned when a notification rule overlaps an existing rule of
+// the bucket.
+const codeR2RuleConflict = 11013
+
+type r2NotificationRule struct {
+	Actions   []string `json:"actions"`
+	CreatedAt string   `json:"createdAt"`
+	Prefix    string   `json:"prefix"`
+	RuleID    string   `json:"ruleId"`
+	Suffix    string   `json:"suffix"`
+
+	// hiddenReads is the number of reads of the configuration that still
+	// miss the rule
+	hiddenReads int
+}
+
+type r2NotificationQueue struct {
+	QueueID   string               `json:"queueId"`
+	QueueName string               `json:"queueName"`
+	Rules     []r2NotificationRule `json:"rules"`
+}
+
+type bucketNotifications struct {
+	BucketName string                 `json:"bucketName"`
+	Queues     []*r2NotificationQueue `json:"queues"`
+}
+
+func (s *Server) registerEventNotificationsRoutes(mux *http.ServeMux) {
+	const base = "/accounts/{account_id}/event_notifications/r2/{bucket_name}/configuration"
+
+	route(mux, http.MethodGet, base, s.getR2NotificationConfiguration)
+	route(mux, http.MethodPut, base+"/queues/{queue_id}", s.putR2NotificationRules)
+	route(mux, http.MethodDelete, base+"/queues/{queue_id}", s.deleteR2NotificationRules)
+}
+
+// R2NotificationRuleCount returns the number of notification rules configured
+// for a queue on a bucket.
+func (s *Server) R2NotificationRuleCount(accountID, bucketName, queueID string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	config, ok := s.notifications[key(accountID, bucketName)]
+	if !ok {
+		return 0
+	}
+	for _, q := range config.Queues {
+		if sameQueueID(q.QueueID, queueID) {
+			return len(q.Rules)
+		}
+	}
+	return 0
+}
+
+// DelayR2NotificationRules makes rules added from now on only show up in the
+// notification configuration of their bucket after it has been read n times,
+// like rules that take a while to propagate.
+func (s *Server) DelayR2NotificationRules(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.r2RuleDelay = n
+}
+
+func (s *Server) getR2NotificationConfiguration(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	config := s.bucketNotifications(r.PathValue("account_id"), r.PathValue("bucket_name"))
+
+	visible := bucketNotifications{
+		BucketName: config.BucketName,
+		Queues:     []*r2NotificationQueue{},
+	}
+	for _, q := range config.Queues {
+		vq := &r2NotificationQueue{QueueID: q.QueueID, QueueName: q.QueueName, Rules: []r2NotificationRule{}}
+		for i := range q.Rules {
+			if q.Rules[i].hiddenReads > 0 {
+				q.Rules[i].hiddenReads--
+				continue
+			}
+			vq.Rules = append(vq.Rules, q.Rules[i])
+		}
+		// a queue shows up along with its first rule
+		if len(vq.Rules) > 0 || len(q.Rules) == 0 {
+			visible.Queues = append(visible.Queues, vq)
+		}
+	}
+
+	writeResult(w, http.StatusOK, visible)
+}
+
+func (s *Server) putR2NotificationRules(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Rules []struct {
+			Actions []string `json:"actions"`
+			Prefix  string   `json:"prefix"`
+			Suffix  string   `json:"suffix"`
+		} `json:"rules"`
+	}
+	if !decodeBody(w, r, &body) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	accountID := r.PathValue("account_id")
+	queueID := r.PathValue("queue_id")
+	q, ok := s.queues[key(accountID, strings.ReplaceAll(queueID, "-", ""))]
+	if !ok {
+		writeError(w, http.StatusNotFound, codeQueueNotFound, "queue not found")
+		return
+	}
+
+	config := s.bucketNotifications(accountID, r.PathValue("bucket_name"))
+	var target *r2NotificationQueue
+	for _, existing := range config.Queues {
+		if sameQueueID(existing.QueueID, queueID) {
+			target = existing
+		}
+	}
+	isNew := target == nil
+	if isNew {
+		// added to the configuration along with its first rules
+		target = &r2NotificationQueue{
+			QueueID:   hyphenateID(q.QueueID),
+			QueueName: q.QueueName,
+		}
+	}
+
+	ts := now()
+	added := make([]r2NotificationRule, 0, len(body.Rules))
+	for _, rule := range body.Rules {
+		added = append(added, r2NotificationRule{
+			Actions:   rule.Actions,
+			CreatedAt: ts,
+			Prefix:    rule.Prefix,
+			RuleID:    newID(),
+			Suffix:    rule.Suffix,
+
+			hiddenReads: s.r2RuleDelay,
+		})
+	}
+
+	// an object event may only match one rule of the bucket, so the rules
+	// are added all or none
+	for i, rule := range added {
+		conflict := slices.ContainsFunc(added[:i], rule.overlaps)
+		for _, existing := range config.Queues {
+			conflict = conflict || slices.ContainsFunc(existing.Rules, rule.overlaps)
+		}
+		if conflict {
+			writeError(w, http.StatusConflict, codeR2RuleConflict, "notification rule overlaps an existing rule")
+			return
+		}
+	}
+	if isNew {
+		config.Queues = append(config.Queues, target)
+	}
+	target.Rules = append(target.Rules, added...)
+
+	writeResult(w, http.StatusOK, map[string]any{})
+}
+
+func (s *Server) deleteR2NotificationRules(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		RuleIDs []string `json:"ruleIds"`
+	}
+	// The rule IDs are optional; without them every rule for the queue is
+	// removed.
+	if raw, _ := io.ReadAll(r.Body); len(raw) > 0 {
+		if err := json.Unmarshal(raw, &body); err != nil {
+			writeError(w, http.StatusBadRequest, codeInvalidRequest, "invalid request body: "+err.Error())
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	config := s.bucketNotifications(r.PathValue("account_id"), r.PathValue("bucket_name"))
+	config.Queues = slices.DeleteFunc(config.Queues, func(q *r2NotificationQueue) bool {
+		if !sameQueueID(q.QueueID, r.PathValue("queue_id")) {
+			return false
+		}
+		if len(body.RuleIDs) == 0 {
+			return true
+		}
+		q.Rules = slices.DeleteFunc(q.Rules, func(rule r2NotificationRule) bool {
+			return slices.Contains(body.RuleIDs, rule.RuleID)
+		})
+		return len(q.Rules) == 0
+	})
+
+	writeResult(w, http.StatusOK, map[string]any{})
+}
+
+// overlaps reports whether an object event could match both rules: they share
+// an action, and their prefixes and suffixes match overlapping keys.
+func (r r2NotificationRule) overlaps(other r2NotificationRule) bool {
+	sharesAction := slices.ContainsFunc(r.Actions, func(action string) bool {
+		return slices.Contains(other.Actions, action)
+	})
+
+	return sharesAction &&
+		(strings.HasPrefix(r.Prefix, other.Prefix) || strings.HasPrefix(other.Prefix, r.Prefix)) &&
+		(strings.HasSuffix(r.Suffix, other.Suffix) || strings.HasSuffix(other.Suffix, r.Suffix))
+}
+
+// bucketNotifications returns the notification configuration of a bucket,
+// creating an empty one if none exists yet. Callers must hold s.mu.
+func (s *Server) bucketNotifications(accountID, bucketName string) *bucketNotifications {
+	k := key(accountID, bucketName)
+	config, ok := s.notifications[k]
+	if !ok {
+		config = &bucketNotifications{
+			BucketName: bucketName,
+			Queues:     []*r2NotificationQueue{},
+		}
+		s.notifications[k] = config
+	}
+
+	return config
+}
+
+// hyphenateID formats a 32 character hex ID as a UUID, which is how the event
+// notifications API reports queue IDs.
+func hyphenateID(id string) string {
+	if len(id) != 32 {
+		return id
+	}
+	return id[0:8] + "-" + id[8:12] + "-" + id[12:16] + "-" + id[16:20] + "-" + id[20:]
+}
+
+func sameQueueID(a, b string) bool {
+	return strings.ReplaceAll(a, "-", "") == strings.ReplaceAll(b, "-", "")
+}
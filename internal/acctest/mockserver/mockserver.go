@@ -0,0 +1,263 @@
+// Package mockserver provides an in-process fake of the subset of the
+// Cloudflare v4 API used by this provider, allowing resource tests to run
+// end-to-end without a live account.
+//
+// Resource tests run against the fake with acctest.NewOfflineTest, which points
+// the provider at it through the `base_url` attribute:
+//
+//	srv := acctest.NewOfflineTest(t)
+//	config := srv.ProviderConfig() + myResourceConfig()
+package mockserver
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	// Path prefix the fake API is served under, mirroring the production
+	// `https://api.cloudflare.com/client/v4/` base URL.
+	apiPrefix = "/client/v4"
+
+	// Error code returned when a request carries no credentials.
+	codeAuthentication = 10000
+
+	// Error code returned when a request body cannot be decoded.
+	codeInvalidRequest = 10001
+
+	// Error code returned when a request is rate limited.
+	codeRateLimited = 971
+
+	// Error code returned for an injected server error.
+	codeServerError = 10013
+)
+
+// Server is a stateful fake of the Cloudflare v4 API. All state is held in
+// memory and discarded when the server is closed.
+type Server struct {
+	*httptest.Server
+
+	mu            sync.Mutex
+	indexes       map[string]*vectorizeIndex
+	scripts       map[string]*workerScript
+	queues        map[string]*queue
+	notifications map[string]*bucketNotifications
+	failures      []failure
+
+	// reads of the bucket notification configuration that miss newly added
+	// rules, see DelayR2NotificationRules
+	r2RuleDelay int
+}
+
+// failure is an injected error response, served to the next request with a
+// matching method, or to any request when method is empty.
+type failure struct {
+	method string
+	status int
+}
+
+// New starts a fake API server that is closed automatically when the test
+// and all of its subtests complete.
+func New(t testing.TB) *Server {
+	s := &Server{
+		indexes:       make(map[string]*vectorizeIndex),
+		scripts:       make(map[string]*workerScript),
+		queues:        make(map[string]*queue),
+		notifications: make(map[string]*bucketNotifications),
+	}
+
+	mux := http.NewServeMux()
+	s.registerVectorizeRoutes(mux)
+	s.registerWorkersRoutes(mux)
+	s.registerDurableObjectsRoutes(mux)
+	s.registerQueuesRoutes(mux)
+	s.registerEventNotificationsRoutes(mux)
+
+	s.Server = httptest.NewServer(requireAuth(s.injectFailures(mux)))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+// BaseURL returns the value to use for the provider `base_url` attribute.
+func (s *Server) BaseURL() string {
+	return s.URL + apiPrefix + "/"
+}
+
+// route registers handler for the given method and API path, relative to the
+// v4 base URL, e.g. "GET /accounts/{account_id}/queues".
+func route(mux *http.ServeMux, method, path string, handler http.HandlerFunc) {
+	mux.HandleFunc(method+" "+apiPrefix+path, handler)
+}
+
+// FailNextRequests makes the next n requests fail with status, before they
+// reach the fake API. Rate limited requests carry a `Retry-After` of a second.
+func (s *Server) FailNextRequests(n int, status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for range n {
+		s.failures = append(s.failures, failure{status: status})
+	}
+}
+
+// FailNextRequestsWithMethod is like FailNextRequests, but only fails
+// requests with the given HTTP method, letting the others through.
+func (s *Server) FailNextRequestsWithMethod(method string, n int, status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for range n {
+		s.failures = append(s.failures, failure{method: method, status: status})
+	}
+}
+
+// PendingFailures returns the number of injected failures not yet served.
+func (s *Server) PendingFailures() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.failures)
+}
+
+// Leftovers describes the objects created through the API that still exist,
+// which once every resource is destroyed should be none. Queues seeded with
+// CreateQueue are not created through the API, but consumers and notification
+// rules attached to them are.
+func (s *Server) Leftovers() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var leftovers []string
+	for k := range s.indexes {
+		leftovers = append(leftovers, "vectorize index "+k)
+	}
+	for k := range s.scripts {
+		leftovers = append(leftovers, "worker script "+k)
+	}
+	for k, q := range s.queues {
+		if !q.seeded {
+			leftovers = append(leftovers, "queue "+k)
+		}
+		for _, c := range q.consumers {
+			leftovers = append(leftovers, "consumer "+c.ConsumerID+" of queue "+k)
+		}
+	}
+	for k, config := range s.notifications {
+		for _, q := range config.Queues {
+			if len(q.Rules) > 0 {
+				leftovers = append(leftovers, "notification rules of bucket "+k+" for queue "+q.QueueID)
+			}
+		}
+	}
+
+	sort.Strings(leftovers)
+	return leftovers
+}
+
+func (s *Server) injectFailures(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		status := 0
+		for i, f := range s.failures {
+			if f.method == "" || f.method == r.Method {
+				status = f.status
+				s.failures = append(s.failures[:i], s.failures[i+1:]...)
+				break
+			}
+		}
+		s.mu.Unlock()
+
+		switch {
+		case status == http.StatusTooManyRequests:
+			w.Header().Set("Retry-After", "1")
+			writeError(w, status, codeRateLimited, "Please wait and consider throttling your request speed")
+		case status != 0:
+			writeError(w, status, codeServerError, http.StatusText(status))
+		default:
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+func requireAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" &&
+			r.Header.Get("X-Auth-Key") == "" &&
+			r.Header.Get("X-Auth-User-Service-Key") == "" {
+			writeError(w, http.StatusForbidden, codeAuthentication, "Authentication error")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+type responseInfo struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type envelope struct {
+	Errors   []responseInfo `json:"errors"`
+	Messages []responseInfo `json:"messages"`
+	Success  bool           `json:"success"`
+	Result   any            `json:"result"`
+}
+
+func writeResult(w http.ResponseWriter, status int, result any) {
+	writeJSON(w, status, envelope{
+		Errors:   []responseInfo{},
+		Messages: []responseInfo{},
+		Success:  true,
+		Result:   result,
+	})
+}
+
+func writeError(w http.ResponseWriter, status int, code int, message string) {
+	writeJSON(w, status, envelope{
+		Errors:   []responseInfo{{Code: code, Message: message}},
+		Messages: []responseInfo{},
+		Success:  false,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func decodeBody(w http.ResponseWriter, r *http.Request, into any) bool {
+	if err := json.NewDecoder(r.Body).Decode(into); err != nil {
+		writeError(w, http.StatusBadRequest, codeInvalidRequest, "invalid request body: "+err.Error())
+		return false
+	}
+
+	return true
+}
+
+// key joins path segments into a map key scoped by account.
+func key(parts ...string) string {
+	return strings.Join(parts, "/")
+}
+
+// newID returns a random 32 character hex identifier, the format the API uses
+// for queue, consumer and rule IDs.
+func newID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func now() string {
+	return time.Now().UTC().Format(time.RFC3339Nano)
+}
@@ -0,0 +1,382 @@
+package mockserver_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/cloudflare/cloudflare-go/v3"
+	"github.com/cloudflare/cloudflare-go/v3/event_notifications"
+	"github.com/cloudflare/cloudflare-go/v3/option"
+	"github.com/cloudflare/cloudflare-go/v3/queues"
+	"github.com/cloudflare/cloudflare-go/v3/vectorize"
+	"github.com/cloudflare/cloudflare-go/v3/workers"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/acctest"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/acctest/mockserver"
+)
+
+const accountID = "f037e56e89293a057740de681ac9abbe"
+
+func newClient(srv *mockserver.Server) *cloudflare.Client {
+	return cloudflare.NewClient(
+		option.WithBaseURL(srv.BaseURL()),
+		option.WithAPIToken(acctest.MockAPIToken),
+		option.WithMaxRetries(0),
+	)
+}
+
+func TestMockServer_RequiresAuthentication(t *testing.T) {
+	t.Parallel()
+	srv := mockserver.New(t)
+	client := cloudflare.NewClient(option.WithBaseURL(srv.BaseURL()), option.WithMaxRetries(0))
+
+	_, err := client.Vectorize.Indexes.Get(context.Background(), "missing", vectorize.IndexGetParams{AccountID: cloudflare.F(accountID)})
+
+	var apiErr *cloudflare.Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected 403 api error, got %v", err)
+	}
+}
+
+func TestMockServer_VectorizeIndex(t *testing.T) {
+	t.Parallel()
+	srv := mockserver.New(t)
+	client := newClient(srv)
+	ctx := context.Background()
+
+	_, err := client.Vectorize.Indexes.New(ctx, vectorize.IndexNewParams{
+		AccountID: cloudflare.F(accountID),
+		Name:      cloudflare.F("index"),
+		Config: cloudflare.F(vectorize.IndexNewParamsConfigUnion(vectorize.IndexNewParamsConfig{
+			Dimensions: cloudflare.F(int64(32)),
+			Metric:     cloudflare.F(vectorize.IndexNewParamsConfigMetricCosine),
+		})),
+	})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	_, err = client.Vectorize.Indexes.MetadataIndex.New(ctx, "index", vectorize.IndexMetadataIndexNewParams{
+		AccountID:    cloudflare.F(accountID),
+		PropertyName: cloudflare.F("genre"),
+		IndexType:    cloudflare.F(vectorize.IndexMetadataIndexNewParamsIndexTypeString),
+	})
+	if err != nil {
+		t.Fatalf("create metadata index: %v", err)
+	}
+
+	index, err := client.Vectorize.Indexes.Get(ctx, "index", vectorize.IndexGetParams{AccountID: cloudflare.F(accountID)})
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if index.Config.Dimensions != 32 || index.Config.Metric != vectorize.IndexDimensionConfigurationMetricCosine {
+		t.Errorf("unexpected config: %+v", index.Config)
+	}
+
+	list, err := client.Vectorize.Indexes.MetadataIndex.List(ctx, "index", vectorize.IndexMetadataIndexListParams{AccountID: cloudflare.F(accountID)})
+	if err != nil {
+		t.Fatalf("list metadata indexes: %v", err)
+	}
+	if len(list.MetadataIndexes) != 1 || list.MetadataIndexes[0].PropertyName != "genre" {
+		t.Errorf("unexpected metadata indexes: %+v", list.MetadataIndexes)
+	}
+
+	_, err = client.Vectorize.Indexes.Delete(ctx, "index", vectorize.IndexDeleteParams{AccountID: cloudflare.F(accountID)})
+	if err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	_, err = client.Vectorize.Indexes.Get(ctx, "index", vectorize.IndexGetParams{AccountID: cloudflare.F(accountID)})
+	var apiErr *cloudflare.Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404 after delete, got %v", err)
+	}
+}
+
+func TestMockServer_WorkersScript(t *testing.T) {
+	t.Parallel()
+	srv := mockserver.New(t)
+	client := newClient(srv)
+	ctx := context.Background()
+
+	body := "--boundary\r\n" +
+		"Content-Disposition: form-data; name=\"metadata\"\r\n\r\n" +
+		`{"main_module":"index.js","compatibility_date":"2024-10-22","bindings":[{"type":"secret_text","name":"SECRET","text":"hunter2"}]}` + "\r\n" +
+		"--boundary\r\n" +
+		"Content-Disposition: form-data; name=\"index.js\"; filename=\"index.js\"\r\n" +
+		"Content-Type: text/javascript+module\r\n\r\n" +
+		"export default {};\r\n" +
+		"--boundary--\r\n"
+
+	script, err := client.Workers.Scripts.Update(ctx, "worker", workers.ScriptUpdateParams{AccountID: cloudflare.F(accountID)},
+		option.WithRequestBody("multipart/form-data; boundary=boundary", []byte(body)),
+	)
+	if err != nil {
+		t.Fatalf("upload: %v", err)
+	}
+	if script.ID != "worker" || script.Etag == "" {
+		t.Errorf("unexpected upload response: %+v", script)
+	}
+
+	res := new(http.Response)
+	err = client.Get(ctx, "accounts/"+accountID+"/workers/scripts/worker/settings", nil, &res)
+	if err != nil {
+		t.Fatalf("get settings: %v", err)
+	}
+	settings, _ := io.ReadAll(res.Body)
+	if got := string(settings); !strings.Contains(got, `"compatibility_date":"2024-10-22"`) || strings.Contains(got, "hunter2") {
+		t.Errorf("unexpected settings: %s", got)
+	}
+
+	err = client.Workers.Scripts.Delete(ctx, "worker", workers.ScriptDeleteParams{AccountID: cloudflare.F(accountID)})
+	if err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if srv.WorkerScriptExists(accountID, "worker") {
+		t.Error("script still exists after delete")
+	}
+}
+
+func TestMockServer_Queue(t *testing.T) {
+	t.Parallel()
+	srv := mockserver.New(t)
+	client := newClient(srv)
+	ctx := context.Background()
+
+	queue, err := client.Queues.New(ctx, queues.QueueNewParams{
+		AccountID: cloudflare.F(accountID),
+		QueueName: cloudflare.F("queue"),
+	})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	_, err = client.Queues.New(ctx, queues.QueueNewParams{
+		AccountID: cloudflare.F(accountID),
+		QueueName: cloudflare.F("queue"),
+	})
+	var apiErr *cloudflare.Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusConflict {
+		t.Fatalf("expected a conflict for a duplicate queue name, got %v", err)
+	}
+
+	updated, err := client.Queues.Update(ctx, queue.QueueID, queues.QueueUpdateParams{AccountID: cloudflare.F(accountID)},
+		option.WithRequestBody("application/json", []byte(`{"queue_name":"renamed"}`)),
+	)
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if updated.QueueID != queue.QueueID || updated.QueueName != "renamed" {
+		t.Errorf("unexpected queue after update: %+v", updated)
+	}
+
+	_, err = client.Queues.Delete(ctx, queue.QueueID, queues.QueueDeleteParams{AccountID: cloudflare.F(accountID)})
+	if err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if srv.QueueExists(accountID, queue.QueueID) {
+		t.Errorf("expected queue %s to be deleted", queue.QueueID)
+	}
+}
+
+func TestMockServer_QueueConsumer(t *testing.T) {
+	t.Parallel()
+	srv := mockserver.New(t)
+	client := newClient(srv)
+	ctx := context.Background()
+	queueID := srv.CreateQueue(accountID, "queue")
+
+	_, err := client.Queues.Consumers.New(ctx, queueID, queues.ConsumerNewParams{AccountID: cloudflare.F(accountID)},
+		option.WithRequestBody("application/json", []byte(`{"type":"worker","script_name":"worker"}`)),
+	)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	consumers, err := client.Queues.Consumers.Get(ctx, queueID, queues.ConsumerGetParams{AccountID: cloudflare.F(accountID)})
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if len(*consumers) != 1 || (*consumers)[0].Service != "worker" || (*consumers)[0].Settings.BatchSize != 10 {
+		t.Errorf("unexpected consumers: %+v", *consumers)
+	}
+}
+
+func TestMockServer_QueueMessages(t *testing.T) {
+	t.Parallel()
+	srv := mockserver.New(t)
+	client := newClient(srv)
+	ctx := context.Background()
+	queueID := srv.CreateQueue(accountID, "queue")
+	srv.SendQueueMessage(accountID, queueID, "first")
+	srv.SendQueueMessage(accountID, queueID, "second")
+
+	res := new(http.Response)
+	_, err := client.Queues.Messages.Pull(ctx, queueID, queues.MessagePullParams{AccountID: cloudflare.F(accountID)},
+		option.WithRequestBody("application/json", []byte(`{"batch_size":1}`)),
+		option.WithResponseBodyInto(&res),
+	)
+	if err != nil {
+		t.Fatalf("pull: %v", err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	var pulled struct {
+		Result struct {
+			MessageBacklogCount int `json:"message_backlog_count"`
+			Messages            []struct {
+				Body    string `json:"body"`
+				LeaseID string `json:"lease_id"`
+			} `json:"messages"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(body, &pulled); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(pulled.Result.Messages) != 1 || pulled.Result.Messages[0].Body != "first" || pulled.Result.MessageBacklogCount != 1 {
+		t.Fatalf("unexpected pull: %s", body)
+	}
+
+	_, err = client.Queues.Messages.Ack(ctx, queueID, queues.MessageAckParams{
+		AccountID: cloudflare.F(accountID),
+		Acks:      cloudflare.F([]queues.MessageAckParamsAck{{LeaseID: cloudflare.F(pulled.Result.Messages[0].LeaseID)}}),
+	})
+	if err != nil {
+		t.Fatalf("ack: %v", err)
+	}
+	if n := srv.QueueMessageCount(accountID, queueID); n != 1 {
+		t.Errorf("expected one message left after ack, got %d", n)
+	}
+}
+
+func TestMockServer_R2EventNotification(t *testing.T) {
+	t.Parallel()
+	srv := mockserver.New(t)
+	client := newClient(srv)
+	ctx := context.Background()
+	queueID := srv.CreateQueue(accountID, "queue")
+
+	_, err := client.EventNotifications.R2.Configuration.Queues.Update(ctx, "bucket", queueID, event_notifications.R2ConfigurationQueueUpdateParams{
+		AccountID: cloudflare.F(accountID),
+		Rules: cloudflare.F([]event_notifications.R2ConfigurationQueueUpdateParamsRule{{
+			Actions: cloudflare.F([]event_notifications.R2ConfigurationQueueUpdateParamsRulesAction{event_notifications.R2ConfigurationQueueUpdateParamsRulesActionPutObject}),
+			Suffix:  cloudflare.F(".png"),
+		}}),
+	})
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	config, err := client.EventNotifications.R2.Configuration.Get(ctx, "bucket", event_notifications.R2ConfigurationGetParams{AccountID: cloudflare.F(accountID)})
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if len(config.Queues) != 1 || config.Queues[0].QueueName != "queue" || len(config.Queues[0].Rules) != 1 {
+		t.Fatalf("unexpected configuration: %+v", config)
+	}
+
+	// rules matching the same objects and actions are rejected
+	_, err = client.EventNotifications.R2.Configuration.Queues.Update(ctx, "bucket", queueID, event_notifications.R2ConfigurationQueueUpdateParams{
+		AccountID: cloudflare.F(accountID),
+		Rules: cloudflare.F([]event_notifications.R2ConfigurationQueueUpdateParamsRule{{
+			Actions: cloudflare.F([]event_notifications.R2ConfigurationQueueUpdateParamsRulesAction{event_notifications.R2ConfigurationQueueUpdateParamsRulesActionPutObject}),
+			Prefix:  cloudflare.F("images/"),
+			Suffix:  cloudflare.F(".png"),
+		}}),
+	})
+	var apiErr *cloudflare.Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusConflict {
+		t.Fatalf("expected 409 api error for an overlapping rule, got %v", err)
+	}
+
+	_, err = client.EventNotifications.R2.Configuration.Queues.Delete(ctx, "bucket", queueID, event_notifications.R2ConfigurationQueueDeleteParams{AccountID: cloudflare.F(accountID)})
+	if err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if n := srv.R2NotificationRuleCount(accountID, "bucket", queueID); n != 0 {
+		t.Errorf("expected no rules after delete, got %d", n)
+	}
+}
+
+func TestMockServer_FailNextRequests(t *testing.T) {
+	t.Parallel()
+	srv := mockserver.New(t)
+	client := newClient(srv)
+	ctx := context.Background()
+	srv.FailNextRequests(1, http.StatusTooManyRequests)
+	srv.FailNextRequests(1, http.StatusBadGateway)
+
+	for _, want := range []int{http.StatusTooManyRequests, http.StatusBadGateway} {
+		_, err := client.Queues.List(ctx, queues.QueueListParams{AccountID: cloudflare.F(accountID)})
+
+		var apiErr *cloudflare.Error
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != want {
+			t.Fatalf("expected %d api error, got %v", want, err)
+		}
+		if want == http.StatusTooManyRequests && apiErr.Response.Header.Get("Retry-After") == "" {
+			t.Errorf("expected a Retry-After header on the rate limited response")
+		}
+	}
+
+	if n := srv.PendingFailures(); n != 0 {
+		t.Errorf("expected no pending failures, got %d", n)
+	}
+	if _, err := client.Queues.List(ctx, queues.QueueListParams{AccountID: cloudflare.F(accountID)}); err != nil {
+		t.Fatalf("list after failures: %v", err)
+	}
+}
+
+func TestMockServer_FailNextRequestsWithMethod(t *testing.T) {
+	t.Parallel()
+	srv := mockserver.New(t)
+	client := newClient(srv)
+	ctx := context.Background()
+	queueID := srv.CreateQueue(accountID, "queue")
+	srv.FailNextRequestsWithMethod(http.MethodDelete, 1, http.StatusInternalServerError)
+
+	if _, err := client.Queues.List(ctx, queues.QueueListParams{AccountID: cloudflare.F(accountID)}); err != nil {
+		t.Fatalf("list: %v", err)
+	}
+
+	_, err := client.Queues.Delete(ctx, queueID, queues.QueueDeleteParams{AccountID: cloudflare.F(accountID)})
+	var apiErr *cloudflare.Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("expected 500 api error, got %v", err)
+	}
+	if !srv.QueueExists(accountID, queueID) {
+		t.Errorf("expected the failed delete to leave the queue")
+	}
+}
+
+func TestMockServer_Leftovers(t *testing.T) {
+	t.Parallel()
+	srv := mockserver.New(t)
+	client := newClient(srv)
+	ctx := context.Background()
+	queueID := srv.CreateQueue(accountID, "queue")
+
+	if leftovers := srv.Leftovers(); len(leftovers) != 0 {
+		t.Fatalf("expected seeded queues not to be left over, got %v", leftovers)
+	}
+
+	_, err := client.Queues.Consumers.New(ctx, queueID, queues.ConsumerNewParams{AccountID: cloudflare.F(accountID)},
+		option.WithRequestBody("application/json", []byte(`{"type":"worker","script_name":"worker"}`)),
+	)
+	if err != nil {
+		t.Fatalf("create consumer: %v", err)
+	}
+	created, err := client.Queues.New(ctx, queues.QueueNewParams{AccountID: cloudflare.F(accountID), QueueName: cloudflare.F("created")})
+	if err != nil {
+		t.Fatalf("create queue: %v", err)
+	}
+
+	got := srv.Leftovers()
+	if len(got) != 2 ||
+		!strings.HasPrefix(got[0], "consumer ") || !strings.HasSuffix(got[0], " of queue "+accountID+"/"+queueID) ||
+		got[1] != "queue "+accountID+"/"+created.QueueID {
+		t.Errorf("unexpected leftovers %q", got)
+	}
+}
@@ -0,0 +1,172 @@
+package mockserver
+
+import (
+	"net/http"
+	"time"
+)
+
+const (
+	// Number of messages pulled when the request sets no batch size.
+	defaultPullBatchSize = 5
+
+	// Time a pulled message is leased for when the request sets no
+	// visibility timeout.
+	defaultVisibilityTimeout = 30 * time.Second
+)
+
+type queueMessage struct {
+	ID          string  `json:"id"`
+	Attempts    float64 `json:"attempts"`
+	Body        string  `json:"body"`
+	LeaseID     string  `json:"lease_id"`
+	TimestampMs float64 `json:"timestamp_ms"`
+
+	// leasedUntil is when the message becomes available to pull again.
+	leasedUntil time.Time
+}
+
+type pullQueueMessagesRequest struct {
+	BatchSize           *float64 `json:"batch_size"`
+	VisibilityTimeoutMs *float64 `json:"visibility_timeout_ms"`
+}
+
+type pullQueueMessagesResponse struct {
+	MessageBacklogCount int             `json:"message_backlog_count"`
+	Messages            []*queueMessage `json:"messages"`
+}
+
+type ackQueueMessagesRequest struct {
+	Acks []struct {
+		LeaseID string `json:"lease_id"`
+	} `json:"acks"`
+	Retries []struct {
+		LeaseID      string  `json:"lease_id"`
+		DelaySeconds float64 `json:"delay_seconds"`
+	} `json:"retries"`
+}
+
+type ackQueueMessagesResponse struct {
+	AckCount   int      `json:"ackCount"`
+	RetryCount int      `json:"retryCount"`
+	Warnings   []string `json:"warnings"`
+}
+
+// SendQueueMessage enqueues a message with a text body, as a producer would,
+// and returns its ID.
+func (s *Server) SendQueueMessage(accountID, queueID, body string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	q, ok := s.queues[key(accountID, queueID)]
+	if !ok {
+		return ""
+	}
+
+	m := &queueMessage{
+		ID:          newID(),
+		Body:        body,
+		TimestampMs: float64(time.Now().UnixMilli()),
+	}
+	q.messages = append(q.messages, m)
+
+	return m.ID
+}
+
+// QueueMessageCount returns the number of messages in a queue that have not
+// been acknowledged, leased or not.
+func (s *Server) QueueMessageCount(accountID, queueID string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	q, ok := s.queues[key(accountID, queueID)]
+	if !ok {
+		return 0
+	}
+	return len(q.messages)
+}
+
+// pullQueueMessages leases a batch of the messages that are not leased yet,
+// counting an attempt for each of them.
+func (s *Server) pullQueueMessages(w http.ResponseWriter, r *http.Request) {
+	var body pullQueueMessagesRequest
+	if !decodeBody(w, r, &body) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	q, ok := s.lookupQueue(w, r)
+	if !ok {
+		return
+	}
+
+	batchSize := defaultPullBatchSize
+	if body.BatchSize != nil {
+		batchSize = int(*body.BatchSize)
+	}
+	timeout := defaultVisibilityTimeout
+	if body.VisibilityTimeoutMs != nil {
+		timeout = time.Duration(*body.VisibilityTimeoutMs) * time.Millisecond
+	}
+
+	ts := time.Now()
+	res := pullQueueMessagesResponse{Messages: []*queueMessage{}}
+	for _, m := range q.messages {
+		if m.leasedUntil.After(ts) {
+			continue
+		}
+		if len(res.Messages) == batchSize {
+			res.MessageBacklogCount++
+			continue
+		}
+
+		m.Attempts++
+		m.LeaseID = newID()
+		m.leasedUntil = ts.Add(timeout)
+		res.Messages = append(res.Messages, m)
+	}
+
+	writeResult(w, http.StatusOK, res)
+}
+
+// ackQueueMessages deletes the acknowledged messages and makes the retried
+// ones available again once their delay has passed. Unknown lease IDs are
+// ignored.
+func (s *Server) ackQueueMessages(w http.ResponseWriter, r *http.Request) {
+	var body ackQueueMessagesRequest
+	if !decodeBody(w, r, &body) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	q, ok := s.lookupQueue(w, r)
+	if !ok {
+		return
+	}
+
+	res := ackQueueMessagesResponse{Warnings: []string{}}
+	for _, ack := range body.Acks {
+		for i, m := range q.messages {
+			if m.LeaseID != "" && m.LeaseID == ack.LeaseID {
+				q.messages = append(q.messages[:i], q.messages[i+1:]...)
+				res.AckCount++
+				break
+			}
+		}
+	}
+	for _, retry := range body.Retries {
+		for _, m := range q.messages {
+			if m.LeaseID != "" && m.LeaseID == retry.LeaseID {
+				m.LeaseID = ""
+				m.leasedUntil = time.Now().Add(time.Duration(retry.DelaySeconds * float64(time.Second)))
+				res.RetryCount++
+				break
+			}
+		}
+	}
+
+	writeResult(w, http.StatusOK, res)
+}
@@ -0,0 +1,465 @@
+package mockserver
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+)
+
+const (
+	// Error code returned when a queue does not exist.
+	codeQueueNotFound = 11000
+
+	// Error code returned when a queue consumer does not exist.
+	codeQueueConsumerNotFound = 11001
+
+	// Error code returned when a queue name is already in use.
+	codeQueueNameTaken = 11009
+)
+
+type queueSettings struct {
+	DeliveryDelay          float64 `json:"delivery_delay"`
+	DeliveryPaused         bool    `json:"delivery_paused"`
+	MessageRetentionPeriod float64 `json:"message_retention_period"`
+}
+
+// defaultQueueSettings are the settings of a queue created without any, the
+// retention period being four days.
+var defaultQueueSettings = queueSettings{
+	MessageRetentionPeriod: 345600,
+}
+
+type queue struct {
+	QueueID    string        `json:"queue_id"`
+	QueueName  string        `json:"queue_name"`
+	CreatedOn  string        `json:"created_on"`
+	ModifiedOn string        `json:"modified_on"`
+	Settings   queueSettings `json:"settings"`
+
+	accountID string
+	consumers []*queueConsumer
+	messages  []*queueMessage
+
+	// seeded queues are created by CreateQueue rather than through the API
+	seeded bool
+}
+
+// queueResponse is a queue as returned by the API, along with its producers
+// and consumers.
+type queueResponse struct {
+	*queue
+	Consumers           []*queueConsumer `json:"consumers"`
+	ConsumersTotalCount int              `json:"consumers_total_count"`
+	Producers           []queueProducer  `json:"producers"`
+	ProducersTotalCount int              `json:"producers_total_count"`
+}
+
+type queueProducer struct {
+	Type       string `json:"type"`
+	Script     string `json:"script,omitempty"`
+	BucketName string `json:"bucket_name,omitempty"`
+}
+
+type queueRequest struct {
+	QueueName string `json:"queue_name"`
+	Settings  struct {
+		DeliveryDelay          *float64 `json:"delivery_delay"`
+		DeliveryPaused         *bool    `json:"delivery_paused"`
+		MessageRetentionPeriod *float64 `json:"message_retention_period"`
+	} `json:"settings"`
+}
+
+type queueConsumerSettings struct {
+	BatchSize           *float64 `json:"batch_size,omitempty"`
+	MaxConcurrency      *float64 `json:"max_concurrency,omitempty"`
+	MaxRetries          *float64 `json:"max_retries,omitempty"`
+	MaxWaitTimeMs       *float64 `json:"max_wait_time_ms,omitempty"`
+	RetryDelay          *float64 `json:"retry_delay,omitempty"`
+	VisibilityTimeoutMs *float64 `json:"visibility_timeout_ms,omitempty"`
+}
+
+type queueConsumer struct {
+	ConsumerID      string                `json:"consumer_id"`
+	CreatedOn       string                `json:"created_on"`
+	DeadLetterQueue string                `json:"dead_letter_queue,omitempty"`
+	Environment     string                `json:"environment"`
+	QueueName       string                `json:"queue_name"`
+	ScriptName      string                `json:"script_name,omitempty"`
+	Service         string                `json:"service"`
+	Settings        queueConsumerSettings `json:"settings"`
+	Type            string                `json:"type"`
+}
+
+type queueConsumerRequest struct {
+	DeadLetterQueue string                `json:"dead_letter_queue"`
+	Environment     string                `json:"environment"`
+	ScriptName      string                `json:"script_name"`
+	Settings        queueConsumerSettings `json:"settings"`
+	Type            string                `json:"type"`
+}
+
+func (s *Server) registerQueuesRoutes(mux *http.ServeMux) {
+	const base = "/accounts/{account_id}/queues"
+
+	route(mux, http.MethodGet, base, s.listQueues)
+	route(mux, http.MethodPost, base, s.createQueue)
+	route(mux, http.MethodGet, base+"/{queue_id}", s.getQueue)
+	route(mux, http.MethodPut, base+"/{queue_id}", s.updateQueue)
+	route(mux, http.MethodDelete, base+"/{queue_id}", s.deleteQueue)
+	route(mux, http.MethodGet, base+"/{queue_id}/consumers", s.listQueueConsumers)
+	route(mux, http.MethodPost, base+"/{queue_id}/consumers", s.createQueueConsumer)
+	route(mux, http.MethodPut, base+"/{queue_id}/consumers/{consumer_id}", s.updateQueueConsumer)
+	route(mux, http.MethodDelete, base+"/{queue_id}/consumers/{consumer_id}", s.deleteQueueConsumer)
+	route(mux, http.MethodPost, base+"/{queue_id}/messages/pull", s.pullQueueMessages)
+	route(mux, http.MethodPost, base+"/{queue_id}/messages/ack", s.ackQueueMessages)
+}
+
+// CreateQueue seeds a queue in the account and returns its ID, for tests of
+// resources that attach to an existing queue.
+func (s *Server) CreateQueue(accountID, name string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	q := newQueue(accountID, name)
+	q.seeded = true
+	s.queues[key(accountID, q.QueueID)] = q
+
+	return q.QueueID
+}
+
+// QueueExists reports whether the queue exists in the account.
+func (s *Server) QueueExists(accountID, queueID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.queues[key(accountID, queueID)]
+	return ok
+}
+
+// QueueConsumerCount returns the number of consumers attached to a queue.
+func (s *Server) QueueConsumerCount(accountID, queueID string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	q, ok := s.queues[key(accountID, queueID)]
+	if !ok {
+		return 0
+	}
+	return len(q.consumers)
+}
+
+func (s *Server) listQueues(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	queues := []queueResponse{}
+	for _, q := range s.queues {
+		if q.accountID == r.PathValue("account_id") {
+			queues = append(queues, s.queueResponse(q))
+		}
+	}
+	sort.Slice(queues, func(i, j int) bool { return queues[i].QueueName < queues[j].QueueName })
+
+	writeResult(w, http.StatusOK, queues)
+}
+
+func (s *Server) createQueue(w http.ResponseWriter, r *http.Request) {
+	var body queueRequest
+	if !decodeBody(w, r, &body) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	accountID := r.PathValue("account_id")
+	if !s.validQueueRequest(w, accountID, "", body) {
+		return
+	}
+
+	q := newQueue(accountID, body.QueueName)
+	applyQueueRequest(q, body)
+	s.queues[key(accountID, q.QueueID)] = q
+
+	writeResult(w, http.StatusOK, s.queueResponse(q))
+}
+
+// updateQueue replaces the name and settings of a queue. Like the API, it
+// doesn't support partial updates, so unset settings revert to the defaults.
+func (s *Server) updateQueue(w http.ResponseWriter, r *http.Request) {
+	var body queueRequest
+	if !decodeBody(w, r, &body) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	q, ok := s.lookupQueue(w, r)
+	if !ok {
+		return
+	}
+	if !s.validQueueRequest(w, q.accountID, q.QueueID, body) {
+		return
+	}
+
+	q.QueueName = body.QueueName
+	q.Settings = defaultQueueSettings
+	applyQueueRequest(q, body)
+	q.ModifiedOn = now()
+	for _, consumer := range q.consumers {
+		consumer.QueueName = q.QueueName
+	}
+
+	writeResult(w, http.StatusOK, s.queueResponse(q))
+}
+
+func (s *Server) deleteQueue(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	q, ok := s.lookupQueue(w, r)
+	if !ok {
+		return
+	}
+	delete(s.queues, key(q.accountID, q.QueueID))
+
+	writeResult(w, http.StatusOK, nil)
+}
+
+func (s *Server) getQueue(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	q, ok := s.lookupQueue(w, r)
+	if !ok {
+		return
+	}
+
+	writeResult(w, http.StatusOK, s.queueResponse(q))
+}
+
+func (s *Server) listQueueConsumers(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	q, ok := s.lookupQueue(w, r)
+	if !ok {
+		return
+	}
+
+	consumers := q.consumers
+	if consumers == nil {
+		consumers = []*queueConsumer{}
+	}
+
+	writeResult(w, http.StatusOK, consumers)
+}
+
+func (s *Server) createQueueConsumer(w http.ResponseWriter, r *http.Request) {
+	var body queueConsumerRequest
+	if !decodeBody(w, r, &body) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	q, ok := s.lookupQueue(w, r)
+	if !ok {
+		return
+	}
+
+	consumer := &queueConsumer{
+		ConsumerID: newID(),
+		CreatedOn:  now(),
+		QueueName:  q.QueueName,
+	}
+	applyQueueConsumerRequest(consumer, body)
+	q.consumers = append(q.consumers, consumer)
+
+	writeResult(w, http.StatusOK, consumer)
+}
+
+func (s *Server) updateQueueConsumer(w http.ResponseWriter, r *http.Request) {
+	var body queueConsumerRequest
+	if !decodeBody(w, r, &body) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	q, ok := s.lookupQueue(w, r)
+	if !ok {
+		return
+	}
+
+	for _, consumer := range q.consumers {
+		if consumer.ConsumerID == r.PathValue("consumer_id") {
+			applyQueueConsumerRequest(consumer, body)
+			writeResult(w, http.StatusOK, consumer)
+			return
+		}
+	}
+
+	writeError(w, http.StatusNotFound, codeQueueConsumerNotFound, "queue consumer not found")
+}
+
+func (s *Server) deleteQueueConsumer(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	q, ok := s.lookupQueue(w, r)
+	if !ok {
+		return
+	}
+
+	for i, consumer := range q.consumers {
+		if consumer.ConsumerID == r.PathValue("consumer_id") {
+			q.consumers = append(q.consumers[:i], q.consumers[i+1:]...)
+			writeResult(w, http.StatusOK, nil)
+			return
+		}
+	}
+
+	writeError(w, http.StatusNotFound, codeQueueConsumerNotFound, "queue consumer not found")
+}
+
+// applyQueueConsumerRequest copies the writable fields of a create or update
+// request onto consumer, filling in the API defaults for unset settings.
+func applyQueueConsumerRequest(consumer *queueConsumer, body queueConsumerRequest) {
+	consumer.Type = body.Type
+	if consumer.Type == "" {
+		consumer.Type = "worker"
+	}
+	consumer.DeadLetterQueue = body.DeadLetterQueue
+	consumer.Environment = body.Environment
+
+	consumer.ScriptName = ""
+	consumer.Service = ""
+	if consumer.Type == "worker" {
+		consumer.ScriptName = body.ScriptName
+		consumer.Service = body.ScriptName
+	}
+
+	// only the settings of the consumer type are kept, and worker consumers
+	// without max_concurrency scale automatically
+	consumer.Settings = queueConsumerSettings{
+		BatchSize:  withDefault(body.Settings.BatchSize, 10),
+		MaxRetries: withDefault(body.Settings.MaxRetries, 3),
+		RetryDelay: withDefault(body.Settings.RetryDelay, 0),
+	}
+	switch consumer.Type {
+	case "worker":
+		consumer.Settings.MaxConcurrency = body.Settings.MaxConcurrency
+		consumer.Settings.MaxWaitTimeMs = withDefault(body.Settings.MaxWaitTimeMs, 5000)
+	case "http_pull":
+		consumer.Settings.VisibilityTimeoutMs = withDefault(body.Settings.VisibilityTimeoutMs, 30000)
+	}
+}
+
+// lookupQueue resolves the queue referenced in the request path, writing a
+// not found error when it does not exist. Callers must hold s.mu.
+func (s *Server) lookupQueue(w http.ResponseWriter, r *http.Request) (*queue, bool) {
+	q, ok := s.queues[key(r.PathValue("account_id"), r.PathValue("queue_id"))]
+	if !ok {
+		writeError(w, http.StatusNotFound, codeQueueNotFound, "queue not found")
+		return nil, false
+	}
+
+	return q, true
+}
+
+func withDefault(v *float64, fallback float64) *float64 {
+	if v != nil {
+		return v
+	}
+	return &fallback
+}
+
+func newQueue(accountID, name string) *queue {
+	ts := now()
+	return &queue{
+		QueueID:    newID(),
+		QueueName:  name,
+		CreatedOn:  ts,
+		ModifiedOn: ts,
+		Settings:   defaultQueueSettings,
+		accountID:  accountID,
+	}
+}
+
+// validQueueRequest checks the name of a created or updated queue, writing an
+// error when it is missing or taken by another queue. Callers must hold s.mu.
+func (s *Server) validQueueRequest(w http.ResponseWriter, accountID, queueID string, body queueRequest) bool {
+	if body.QueueName == "" {
+		writeError(w, http.StatusBadRequest, codeInvalidRequest, "queue_name is required")
+		return false
+	}
+	for _, q := range s.queues {
+		if q.accountID == accountID && q.QueueID != queueID && q.QueueName == body.QueueName {
+			writeError(w, http.StatusConflict, codeQueueNameTaken, "queue name is already taken")
+			return false
+		}
+	}
+
+	return true
+}
+
+func applyQueueRequest(q *queue, body queueRequest) {
+	if body.Settings.DeliveryDelay != nil {
+		q.Settings.DeliveryDelay = *body.Settings.DeliveryDelay
+	}
+	if body.Settings.DeliveryPaused != nil {
+		q.Settings.DeliveryPaused = *body.Settings.DeliveryPaused
+	}
+	if body.Settings.MessageRetentionPeriod != nil {
+		q.Settings.MessageRetentionPeriod = *body.Settings.MessageRetentionPeriod
+	}
+}
+
+// queueResponse returns the API view of a queue. Worker scripts with a binding
+// to the queue and buckets sending event notifications to it are its
+// producers. Callers must hold s.mu.
+func (s *Server) queueResponse(q *queue) queueResponse {
+	res := queueResponse{
+		queue:     q,
+		Consumers: q.consumers,
+		Producers: []queueProducer{},
+	}
+	if res.Consumers == nil {
+		res.Consumers = []*queueConsumer{}
+	}
+
+	prefix := q.accountID + "/"
+	for k, script := range s.scripts {
+		if !strings.HasPrefix(k, prefix) {
+			continue
+		}
+		for _, binding := range script.metadata.Bindings {
+			if binding["type"] == "queue" && binding["queue_name"] == q.QueueName {
+				res.Producers = append(res.Producers, queueProducer{Type: "worker", Script: script.ID})
+				break
+			}
+		}
+	}
+	for k, config := range s.notifications {
+		if !strings.HasPrefix(k, prefix) {
+			continue
+		}
+		for _, target := range config.Queues {
+			if sameQueueID(target.QueueID, q.QueueID) {
+				res.Producers = append(res.Producers, queueProducer{Type: "r2_bucket", BucketName: config.BucketName})
+			}
+		}
+	}
+	sort.Slice(res.Producers, func(i, j int) bool {
+		return res.Producers[i].Script+res.Producers[i].BucketName < res.Producers[j].Script+res.Producers[j].BucketName
+	})
+
+	res.ConsumersTotalCount = len(res.Consumers)
+	res.ProducersTotalCount = len(res.Producers)
+
+	return res
+}
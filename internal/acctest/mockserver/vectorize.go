@@ -0,0 +1,204 @@
+package mockserver
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Error code returned when a Vectorize index does not exist.
+const codeVectorizeIndexNotFound = 3000
+
+type vectorizeIndexConfig struct {
+	Dimensions int64  `json:"dimensions"`
+	Metric     string `json:"metric"`
+}
+
+type vectorizeIndex struct {
+	Config      vectorizeIndexConfig `json:"config"`
+	CreatedOn   string               `json:"created_on"`
+	Description string               `json:"description"`
+	ModifiedOn  string               `json:"modified_on"`
+	Name        string               `json:"name"`
+
+	metadataIndexes map[string]string
+}
+
+type vectorizeMetadataIndex struct {
+	IndexType    string `json:"indexType"`
+	PropertyName string `json:"propertyName"`
+}
+
+type vectorizeMutation struct {
+	MutationID string `json:"mutationId"`
+}
+
+func (s *Server) registerVectorizeRoutes(mux *http.ServeMux) {
+	const base = "/accounts/{account_id}/vectorize/v2/indexes"
+
+	route(mux, http.MethodPost, base, s.createVectorizeIndex)
+	route(mux, http.MethodGet, base, s.listVectorizeIndexes)
+	route(mux, http.MethodGet, base+"/{index_name}", s.getVectorizeIndex)
+	route(mux, http.MethodDelete, base+"/{index_name}", s.deleteVectorizeIndex)
+	route(mux, http.MethodPost, base+"/{index_name}/metadata_index/create", s.createVectorizeMetadataIndex)
+	route(mux, http.MethodGet, base+"/{index_name}/metadata_index/list", s.listVectorizeMetadataIndexes)
+	route(mux, http.MethodPost, base+"/{index_name}/metadata_index/delete", s.deleteVectorizeMetadataIndex)
+}
+
+// VectorizeIndexExists reports whether the named index exists in the account.
+func (s *Server) VectorizeIndexExists(accountID, name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.indexes[key(accountID, name)]
+	return ok
+}
+
+func (s *Server) createVectorizeIndex(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Name        string               `json:"name"`
+		Description string               `json:"description"`
+		Config      vectorizeIndexConfig `json:"config"`
+	}
+	if !decodeBody(w, r, &body) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k := key(r.PathValue("account_id"), body.Name)
+	if _, exists := s.indexes[k]; exists {
+		writeError(w, http.StatusConflict, 3002, "vectorize.index.duplicate_name")
+		return
+	}
+
+	ts := now()
+	index := &vectorizeIndex{
+		Config:          body.Config,
+		CreatedOn:       ts,
+		Description:     body.Description,
+		ModifiedOn:      ts,
+		Name:            body.Name,
+		metadataIndexes: make(map[string]string),
+	}
+	s.indexes[k] = index
+
+	writeResult(w, http.StatusOK, index)
+}
+
+func (s *Server) listVectorizeIndexes(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prefix := r.PathValue("account_id") + "/"
+	indexes := []*vectorizeIndex{}
+	for k, index := range s.indexes {
+		if strings.HasPrefix(k, prefix) {
+			indexes = append(indexes, index)
+		}
+	}
+	sort.Slice(indexes, func(i, j int) bool { return indexes[i].Name < indexes[j].Name })
+
+	writeResult(w, http.StatusOK, indexes)
+}
+
+func (s *Server) getVectorizeIndex(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	index, ok := s.lookupVectorizeIndex(w, r)
+	if !ok {
+		return
+	}
+
+	writeResult(w, http.StatusOK, index)
+}
+
+func (s *Server) deleteVectorizeIndex(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.lookupVectorizeIndex(w, r); !ok {
+		return
+	}
+	delete(s.indexes, key(r.PathValue("account_id"), r.PathValue("index_name")))
+
+	writeResult(w, http.StatusOK, nil)
+}
+
+func (s *Server) createVectorizeMetadataIndex(w http.ResponseWriter, r *http.Request) {
+	var body vectorizeMetadataIndex
+	if !decodeBody(w, r, &body) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	index, ok := s.lookupVectorizeIndex(w, r)
+	if !ok {
+		return
+	}
+	if _, exists := index.metadataIndexes[body.PropertyName]; exists {
+		writeError(w, http.StatusConflict, 3005, "vectorize.metadata_index.duplicate_property")
+		return
+	}
+	index.metadataIndexes[body.PropertyName] = body.IndexType
+
+	writeResult(w, http.StatusOK, vectorizeMutation{MutationID: newID()})
+}
+
+func (s *Server) listVectorizeMetadataIndexes(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	index, ok := s.lookupVectorizeIndex(w, r)
+	if !ok {
+		return
+	}
+
+	metadataIndexes := []vectorizeMetadataIndex{}
+	for propertyName, indexType := range index.metadataIndexes {
+		metadataIndexes = append(metadataIndexes, vectorizeMetadataIndex{IndexType: indexType, PropertyName: propertyName})
+	}
+	sort.Slice(metadataIndexes, func(i, j int) bool {
+		return metadataIndexes[i].PropertyName < metadataIndexes[j].PropertyName
+	})
+
+	writeResult(w, http.StatusOK, map[string]any{"metadataIndexes": metadataIndexes})
+}
+
+func (s *Server) deleteVectorizeMetadataIndex(w http.ResponseWriter, r *http.Request) {
+	var body vectorizeMetadataIndex
+	if !decodeBody(w, r, &body) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	index, ok := s.lookupVectorizeIndex(w, r)
+	if !ok {
+		return
+	}
+	if _, exists := index.metadataIndexes[body.PropertyName]; !exists {
+		writeError(w, http.StatusNotFound, 3006, "vectorize.metadata_index.not_found")
+		return
+	}
+	delete(index.metadataIndexes, body.PropertyName)
+
+	writeResult(w, http.StatusOK, vectorizeMutation{MutationID: newID()})
+}
+
+// lookupVectorizeIndex resolves the index named in the request path, writing
+// a not found error when it does not exist. Callers must hold s.mu.
+func (s *Server) lookupVectorizeIndex(w http.ResponseWriter, r *http.Request) (*vectorizeIndex, bool) {
+	index, ok := s.indexes[key(r.PathValue("account_id"), r.PathValue("index_name"))]
+	if !ok {
+		writeError(w, http.StatusNotFound, codeVectorizeIndexNotFound, "vectorize.index.not_found")
+		return nil, false
+	}
+
+	return index, true
+}
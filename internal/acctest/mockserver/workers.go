@@ -0,0 +1,380 @@
+package mockserver
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"sort"
+	"strings"
+)
+
+// Error code returned when a Worker script does not exist.
+const codeWorkerScriptNotFound = 10007
+
+type workerScriptPlacement struct {
+	Mode string `json:"mode,omitempty"`
+}
+
+// workerScriptMetadata is the subset of the multipart upload `metadata` part
+// that the fake understands.
+type workerScriptMetadata struct {
+	Bindings           []map[string]any      `json:"bindings,omitempty"`
+	BodyPart           string                `json:"body_part,omitempty"`
+	CompatibilityDate  string                `json:"compatibility_date,omitempty"`
+	CompatibilityFlags []string              `json:"compatibility_flags,omitempty"`
+	Logpush            *bool                 `json:"logpush,omitempty"`
+	MainModule         string                `json:"main_module,omitempty"`
+	Migrations         json.RawMessage       `json:"migrations,omitempty"`
+	Placement          workerScriptPlacement `json:"placement,omitempty"`
+	Tags               []string              `json:"tags,omitempty"`
+	TailConsumers      []map[string]any      `json:"tail_consumers,omitempty"`
+	UsageModel         string                `json:"usage_model,omitempty"`
+}
+
+type workerScriptPart struct {
+	name        string
+	contentType string
+	content     []byte
+}
+
+type workerScript struct {
+	ID            string           `json:"id"`
+	CreatedOn     string           `json:"created_on"`
+	ModifiedOn    string           `json:"modified_on"`
+	Etag          string           `json:"etag"`
+	Logpush       bool             `json:"logpush"`
+	PlacementMode string           `json:"placement_mode,omitempty"`
+	StartupTimeMs int64            `json:"startup_time_ms"`
+	TailConsumers []map[string]any `json:"tail_consumers"`
+	UsageModel    string           `json:"usage_model"`
+	MigrationTag  string           `json:"migration_tag,omitempty"`
+
+	metadata workerScriptMetadata
+	parts    []workerScriptPart
+	classes  []durableObjectClass
+}
+
+type workerScriptSettings struct {
+	Bindings           []map[string]any      `json:"bindings"`
+	CompatibilityDate  string                `json:"compatibility_date"`
+	CompatibilityFlags []string              `json:"compatibility_flags"`
+	Logpush            bool                  `json:"logpush"`
+	Placement          workerScriptPlacement `json:"placement"`
+	Tags               []string              `json:"tags"`
+	TailConsumers      []map[string]any      `json:"tail_consumers"`
+	UsageModel         string                `json:"usage_model"`
+	MigrationTag       string                `json:"migration_tag,omitempty"`
+}
+
+func (s *Server) registerWorkersRoutes(mux *http.ServeMux) {
+	const base = "/accounts/{account_id}/workers/scripts"
+
+	route(mux, http.MethodGet, base, s.listWorkerScripts)
+	route(mux, http.MethodPut, base+"/{script_name}", s.uploadWorkerScript)
+	route(mux, http.MethodGet, base+"/{script_name}", s.getWorkerScriptContent)
+	route(mux, http.MethodDelete, base+"/{script_name}", s.deleteWorkerScript)
+	route(mux, http.MethodGet, base+"/{script_name}/content/v2", s.getWorkerScriptContent)
+	route(mux, http.MethodGet, base+"/{script_name}/settings", s.getWorkerScriptSettings)
+	route(mux, http.MethodGet, base+"/{script_name}/script-settings", s.getWorkerScriptScriptSettings)
+	route(mux, http.MethodPatch, base+"/{script_name}/script-settings", s.editWorkerScriptScriptSettings)
+}
+
+// WorkerScriptExists reports whether the named script exists in the account.
+func (s *Server) WorkerScriptExists(accountID, name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.scripts[key(accountID, name)]
+	return ok
+}
+
+// WorkerScriptPart returns the content type and content of a part uploaded
+// with the named script.
+func (s *Server) WorkerScriptPart(accountID, scriptName, partName string) (contentType string, content []byte, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	script, ok := s.scripts[key(accountID, scriptName)]
+	if !ok {
+		return "", nil, false
+	}
+	for _, p := range script.parts {
+		if p.name == partName {
+			return p.contentType, p.content, true
+		}
+	}
+	return "", nil, false
+}
+
+func (s *Server) listWorkerScripts(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prefix := r.PathValue("account_id") + "/"
+	scripts := []*workerScript{}
+	for k, script := range s.scripts {
+		if strings.HasPrefix(k, prefix) {
+			scripts = append(scripts, script)
+		}
+	}
+	sort.Slice(scripts, func(i, j int) bool { return scripts[i].ID < scripts[j].ID })
+
+	writeResult(w, http.StatusOK, scripts)
+}
+
+func (s *Server) uploadWorkerScript(w http.ResponseWriter, r *http.Request) {
+	reader, err := r.MultipartReader()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, codeInvalidRequest, "expected multipart/form-data upload: "+err.Error())
+		return
+	}
+
+	var metadata workerScriptMetadata
+	var parts []workerScriptPart
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			writeError(w, http.StatusBadRequest, codeInvalidRequest, "malformed multipart upload: "+err.Error())
+			return
+		}
+
+		content, err := io.ReadAll(part)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, codeInvalidRequest, "malformed multipart upload: "+err.Error())
+			return
+		}
+
+		if part.FormName() == "metadata" {
+			if err := json.Unmarshal(content, &metadata); err != nil {
+				writeError(w, http.StatusBadRequest, codeInvalidRequest, "invalid metadata: "+err.Error())
+				return
+			}
+			continue
+		}
+
+		parts = append(parts, workerScriptPart{
+			name:        part.FormName(),
+			contentType: part.Header.Get("Content-Type"),
+			content:     content,
+		})
+	}
+
+	entrypoint := metadata.MainModule
+	if entrypoint == "" {
+		entrypoint = metadata.BodyPart
+	}
+	if !hasPart(parts, entrypoint) {
+		writeError(w, http.StatusBadRequest, 10021, fmt.Sprintf("main module or body part %q was not uploaded", entrypoint))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k := key(r.PathValue("account_id"), r.PathValue("script_name"))
+	ts := now()
+	script, ok := s.scripts[k]
+	if !ok {
+		script = &workerScript{
+			ID:        r.PathValue("script_name"),
+			CreatedOn: ts,
+		}
+	}
+
+	tag, classes, err := applyMigrations(script.MigrationTag, script.classes, metadata.Migrations)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, codeMigrationFailed, err.Error())
+		return
+	}
+	script.MigrationTag = tag
+	script.classes = classes
+	s.scripts[k] = script
+
+	hash := sha256.New()
+	for _, p := range parts {
+		hash.Write(p.content)
+	}
+
+	script.ModifiedOn = ts
+	script.Etag = hex.EncodeToString(hash.Sum(nil))
+	script.PlacementMode = metadata.Placement.Mode
+	script.UsageModel = metadata.UsageModel
+	if script.UsageModel == "" {
+		script.UsageModel = "bundled"
+	}
+	if metadata.Logpush != nil {
+		script.Logpush = *metadata.Logpush
+	}
+	script.TailConsumers = metadata.TailConsumers
+	if script.TailConsumers == nil {
+		script.TailConsumers = []map[string]any{}
+	}
+	script.metadata = metadata
+	script.parts = parts
+
+	writeResult(w, http.StatusOK, script)
+}
+
+func (s *Server) getWorkerScriptContent(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	script, ok := s.lookupWorkerScript(w, r)
+	if !ok {
+		return
+	}
+
+	buf := bytes.NewBuffer(nil)
+	writer := multipart.NewWriter(buf)
+	for _, p := range script.parts {
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, p.name, p.name))
+		h.Set("Content-Type", p.contentType)
+		pw, err := writer.CreatePart(h)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, 10013, err.Error())
+			return
+		}
+		_, _ = pw.Write(p.content)
+	}
+	_ = writer.Close()
+
+	entrypoint := script.metadata.MainModule
+	if entrypoint == "" {
+		entrypoint = script.metadata.BodyPart
+	}
+	w.Header().Set("Content-Type", writer.FormDataContentType())
+	w.Header().Set("cf-entrypoint", entrypoint)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(buf.Bytes())
+}
+
+func (s *Server) deleteWorkerScript(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.lookupWorkerScript(w, r); !ok {
+		return
+	}
+	delete(s.scripts, key(r.PathValue("account_id"), r.PathValue("script_name")))
+
+	writeResult(w, http.StatusOK, nil)
+}
+
+func (s *Server) getWorkerScriptSettings(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	script, ok := s.lookupWorkerScript(w, r)
+	if !ok {
+		return
+	}
+
+	bindings := []map[string]any{}
+	for _, b := range script.metadata.Bindings {
+		binding := make(map[string]any, len(b))
+		for k, v := range b {
+			binding[k] = v
+		}
+		// The API never returns the value of a secret.
+		if binding["type"] == "secret_text" {
+			delete(binding, "text")
+		}
+		bindings = append(bindings, binding)
+	}
+
+	flags := script.metadata.CompatibilityFlags
+	if flags == nil {
+		flags = []string{}
+	}
+	tags := script.metadata.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	writeResult(w, http.StatusOK, workerScriptSettings{
+		Bindings:           bindings,
+		CompatibilityDate:  script.metadata.CompatibilityDate,
+		CompatibilityFlags: flags,
+		Logpush:            script.Logpush,
+		Placement:          script.metadata.Placement,
+		Tags:               tags,
+		TailConsumers:      script.TailConsumers,
+		UsageModel:         script.UsageModel,
+		MigrationTag:       script.MigrationTag,
+	})
+}
+
+func (s *Server) getWorkerScriptScriptSettings(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	script, ok := s.lookupWorkerScript(w, r)
+	if !ok {
+		return
+	}
+
+	writeResult(w, http.StatusOK, map[string]any{
+		"logpush":        script.Logpush,
+		"tail_consumers": script.TailConsumers,
+	})
+}
+
+func (s *Server) editWorkerScriptScriptSettings(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Logpush       *bool            `json:"logpush"`
+		TailConsumers []map[string]any `json:"tail_consumers"`
+	}
+	if !decodeBody(w, r, &body) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	script, ok := s.lookupWorkerScript(w, r)
+	if !ok {
+		return
+	}
+	if body.Logpush != nil {
+		script.Logpush = *body.Logpush
+	}
+	if body.TailConsumers != nil {
+		script.TailConsumers = body.TailConsumers
+	}
+
+	writeResult(w, http.StatusOK, map[string]any{
+		"logpush":        script.Logpush,
+		"tail_consumers": script.TailConsumers,
+	})
+}
+
+// lookupWorkerScript resolves the script named in the request path, writing a
+// not found error when it does not exist. Callers must hold s.mu.
+func (s *Server) lookupWorkerScript(w http.ResponseWriter, r *http.Request) (*workerScript, bool) {
+	script, ok := s.scripts[key(r.PathValue("account_id"), r.PathValue("script_name"))]
+	if !ok {
+		writeError(w, http.StatusNotFound, codeWorkerScriptNotFound, "workers.api.error.script_not_found")
+		return nil, false
+	}
+
+	return script, true
+}
+
+func hasPart(parts []workerScriptPart, name string) bool {
+	for _, p := range parts {
+		if p.name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+package acctest
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/cloudflare/cloudflare-go/v3"
+	"github.com/cloudflare/cloudflare-go/v3/option"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/acctest/mockserver"
+)
+
+// OfflineTest is a resource test run against its own offline mock API server,
+// so that it needs neither credentials nor an account. Unlike acceptance tests
+// it runs without TF_ACC, whenever a Terraform CLI is available.
+//
+// Example:
+//
+//	srv := acctest.NewOfflineTest(t)
+//	srv.Test(resource.TestCase{
+//		Steps: []resource.TestStep{{
+//			Config: srv.ProviderConfig() + LoadTestCase("basic.tf", rnd, srv.AccountID),
+//		}},
+//	})
+type OfflineTest struct {
+	*mockserver.Server
+
+	// AccountID is the account the test creates objects in.
+	AccountID string
+
+	t *testing.T
+}
+
+// NewOfflineTest starts the mock API server of the test t, which runs in
+// parallel. The test is skipped when no Terraform CLI is found, rather than
+// downloading one.
+func NewOfflineTest(t *testing.T) *OfflineTest {
+	t.Parallel()
+
+	if os.Getenv("TF_ACC_TERRAFORM_PATH") == "" && os.Getenv("TF_ACC_TERRAFORM_VERSION") == "" {
+		if _, err := exec.LookPath("terraform"); err != nil {
+			t.Skip("offline tests require a Terraform CLI in the PATH, or TF_ACC_TERRAFORM_PATH or TF_ACC_TERRAFORM_VERSION to be set")
+		}
+	}
+
+	return &OfflineTest{
+		Server:    mockserver.New(t),
+		AccountID: TestAccCloudflareAccountID,
+		t:         t,
+	}
+}
+
+// ProviderConfig returns a provider block that points the provider at the mock
+// API server, with the given provider attributes, e.g. `max_retries = 0`.
+func (o *OfflineTest) ProviderConfig(attributes ...string) string {
+	lines := append([]string{
+		fmt.Sprintf("base_url = %q", o.BaseURL()),
+		fmt.Sprintf("api_token = %q", MockAPIToken),
+	}, attributes...)
+
+	return "\nprovider \"cloudflare-extended\" {\n  " + strings.Join(lines, "\n  ") + "\n}\n"
+}
+
+// APIClient returns an API client for the mock API server, for changing
+// objects out-of-band during the test.
+func (o *OfflineTest) APIClient() *cloudflare.Client {
+	return MockClient(o.BaseURL())
+}
+
+// CheckDestroy checks that every object created through the mock API server
+// is gone once the resources of the test are destroyed.
+func (o *OfflineTest) CheckDestroy(*terraform.State) error {
+	if leftovers := o.Leftovers(); len(leftovers) > 0 {
+		return fmt.Errorf("objects left after destroy:\n%s", strings.Join(leftovers, "\n"))
+	}
+	return nil
+}
+
+// Test runs the test case c against the mock API server. The provider
+// factories and the destroy check default to those of the test.
+func (o *OfflineTest) Test(c resource.TestCase) {
+	o.t.Helper()
+
+	if c.ProtoV6ProviderFactories == nil {
+		c.ProtoV6ProviderFactories = TestAccProtoV6ProviderFactories(o.t)
+	}
+	if c.CheckDestroy == nil {
+		c.CheckDestroy = o.CheckDestroy
+	}
+
+	resource.UnitTest(o.t, c)
+}
+
+// MockClient returns an API client for the offline mock API server served from
+// baseURL, for changing objects out-of-band during a test.
+func MockClient(baseURL string) *cloudflare.Client {
+	return cloudflare.NewClient(
+		option.WithBaseURL(baseURL),
+		option.WithAPIToken(MockAPIToken),
+	)
+}
@@ -0,0 +1,93 @@
+package acctest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// ReadResource configures the provider of the test t from the environment and
+// reads the resource of type typeName whose state has the given attributes,
+// all others being null. It returns the attributes of the state read, without
+// going through Terraform, so that replaying a cassette doesn't require it.
+func ReadResource(t *testing.T, typeName string, attributes map[string]tftypes.Value) map[string]tftypes.Value {
+	t.Helper()
+	ctx := context.Background()
+
+	server, err := TestAccProtoV6ProviderFactories(t)["cloudflare-extended"]()
+	if err != nil {
+		t.Fatalf("failed to start provider: %s", err)
+	}
+
+	schemas, err := server.GetProviderSchema(ctx, &tfprotov6.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatalf("failed to get provider schema: %s", err)
+	}
+	checkDiagnostics(t, "get provider schema", schemas.Diagnostics)
+
+	resourceSchema, ok := schemas.ResourceSchemas[typeName]
+	if !ok {
+		t.Fatalf("provider has no resource %s", typeName)
+	}
+
+	config := dynamicValue(t, schemas.Provider, nil)
+	configured, err := server.ConfigureProvider(ctx, &tfprotov6.ConfigureProviderRequest{Config: config})
+	if err != nil {
+		t.Fatalf("failed to configure provider: %s", err)
+	}
+	checkDiagnostics(t, "configure provider", configured.Diagnostics)
+
+	read, err := server.ReadResource(ctx, &tfprotov6.ReadResourceRequest{
+		TypeName:     typeName,
+		CurrentState: dynamicValue(t, resourceSchema, attributes),
+	})
+	if err != nil {
+		t.Fatalf("failed to read %s: %s", typeName, err)
+	}
+	checkDiagnostics(t, "read "+typeName, read.Diagnostics)
+
+	state, err := read.NewState.Unmarshal(resourceSchema.ValueType())
+	if err != nil {
+		t.Fatalf("failed to decode state of %s: %s", typeName, err)
+	}
+
+	values := make(map[string]tftypes.Value)
+	if err := state.As(&values); err != nil {
+		t.Fatalf("failed to decode state of %s: %s", typeName, err)
+	}
+	return values
+}
+
+// dynamicValue returns an object of the given schema with the given
+// attributes, all others being null.
+func dynamicValue(t *testing.T, schema *tfprotov6.Schema, attributes map[string]tftypes.Value) *tfprotov6.DynamicValue {
+	t.Helper()
+
+	typ := schema.ValueType().(tftypes.Object)
+	values := make(map[string]tftypes.Value, len(typ.AttributeTypes))
+	for name, attrType := range typ.AttributeTypes {
+		if value, ok := attributes[name]; ok {
+			values[name] = value
+			continue
+		}
+		values[name] = tftypes.NewValue(attrType, nil)
+	}
+
+	value, err := tfprotov6.NewDynamicValue(typ, tftypes.NewValue(typ, values))
+	if err != nil {
+		t.Fatalf("failed to encode value: %s", err)
+	}
+	return &value
+}
+
+func checkDiagnostics(t *testing.T, action string, diags []*tfprotov6.Diagnostic) {
+	t.Helper()
+
+	for _, d := range diags {
+		if d.Severity == tfprotov6.DiagnosticSeverityError {
+			t.Fatalf("failed to %s: %s: %s", action, d.Summary, d.Detail)
+		}
+	}
+}
@@ -0,0 +1,264 @@
+package acctest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/cloudflare/cloudflare-go/v3"
+	"github.com/cloudflare/cloudflare-go/v3/event_notifications"
+	"github.com/cloudflare/cloudflare-go/v3/option"
+	"github.com/cloudflare/cloudflare-go/v3/queues"
+	"github.com/cloudflare/cloudflare-go/v3/vectorize"
+	"github.com/cloudflare/cloudflare-go/v3/workers"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/services/queue_consumer"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/utils"
+)
+
+// sweepFunc deletes the leftover test objects of one resource type from an
+// account.
+type sweepFunc func(ctx context.Context, client *cloudflare.Client, accountID string) error
+
+// sweepers are the test sweepers of every resource type of the provider, run
+// against the account given to `go test -sweep=<account>`. They only delete
+// objects named by utils.GenerateRandomResourceName, or attached to the
+// fixtures the acceptance tests read from the environment.
+var sweepers = map[string]*resource.Sweeper{
+	"cloudflare-extended_vectorize_index": {
+		Name: "cloudflare-extended_vectorize_index",
+		F:    sweeper(sweepVectorizeIndexes),
+	},
+	"cloudflare-extended_queue_consumer": {
+		Name: "cloudflare-extended_queue_consumer",
+		F:    sweeper(sweepQueueConsumers),
+	},
+	"cloudflare-extended_r2_event_notification": {
+		Name: "cloudflare-extended_r2_event_notification",
+		F:    sweeper(sweepR2EventNotifications),
+	},
+	"cloudflare-extended_r2_bucket_event_notifications": {
+		Name: "cloudflare-extended_r2_bucket_event_notifications",
+		// the tests of both resources configure the same bucket fixture
+		F: sweeper(sweepR2EventNotifications),
+	},
+	"cloudflare-extended_queue": {
+		Name: "cloudflare-extended_queue",
+		Dependencies: []string{
+			"cloudflare-extended_queue_consumer",
+			"cloudflare-extended_r2_event_notification",
+			"cloudflare-extended_r2_bucket_event_notifications",
+		},
+		F: sweeper(sweepQueues),
+	},
+	"cloudflare-extended_workers_script": {
+		Name: "cloudflare-extended_workers_script",
+		// consumers and event notifications refer to scripts and queues
+		Dependencies: []string{
+			"cloudflare-extended_queue_consumer",
+			"cloudflare-extended_r2_event_notification",
+			"cloudflare-extended_r2_bucket_event_notifications",
+		},
+		F: sweeper(sweepWorkersScripts),
+	},
+}
+
+var addedSweepers = make(map[string]bool)
+
+// AddTestSweepers registers the test sweeper of a resource type along with the
+// sweepers it depends on. Each package only runs the sweepers registered in its
+// own test binary, so registering the dependencies too keeps the sweep in
+// dependency order whichever packages are run.
+func AddTestSweepers(resourceType string) {
+	s, ok := sweepers[resourceType]
+	if !ok {
+		log.Fatalf("[ERR] no test sweeper for resource type %s", resourceType)
+	}
+	if addedSweepers[resourceType] {
+		return
+	}
+	addedSweepers[resourceType] = true
+
+	for _, dependency := range s.Dependencies {
+		AddTestSweepers(dependency)
+	}
+	resource.AddTestSweepers(resourceType, s)
+}
+
+func sweeper(f sweepFunc) resource.SweeperFunc {
+	return func(accountID string) error {
+		return f(context.Background(), SharedClient(), accountID)
+	}
+}
+
+func sweepVectorizeIndexes(ctx context.Context, client *cloudflare.Client, accountID string) error {
+	indexes := client.Vectorize.Indexes.ListAutoPaging(ctx, vectorize.IndexListParams{
+		AccountID: cloudflare.F(accountID),
+	})
+
+	var errs []error
+	for indexes.Next() {
+		name := indexes.Current().Name
+		if !utils.IsTestResourceName(name) {
+			continue
+		}
+
+		log.Printf("[INFO] Deleting Vectorize index %s", name)
+		_, err := client.Vectorize.Indexes.Delete(ctx, name, vectorize.IndexDeleteParams{
+			AccountID: cloudflare.F(accountID),
+		})
+		if err != nil && !utils.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("failed to delete Vectorize index %s: %w", name, err))
+		}
+	}
+
+	return errors.Join(append(errs, indexes.Err())...)
+}
+
+func sweepQueues(ctx context.Context, client *cloudflare.Client, accountID string) error {
+	list := client.Queues.ListAutoPaging(ctx, queues.QueueListParams{
+		AccountID: cloudflare.F(accountID),
+	})
+
+	var errs []error
+	for list.Next() {
+		queue := list.Current()
+		if !utils.IsTestResourceName(queue.QueueName) {
+			continue
+		}
+
+		log.Printf("[INFO] Deleting queue %s", queue.QueueName)
+		_, err := client.Queues.Delete(ctx, queue.QueueID, queues.QueueDeleteParams{
+			AccountID: cloudflare.F(accountID),
+		})
+		if err != nil && !utils.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("failed to delete queue %s: %w", queue.QueueName, err))
+		}
+	}
+
+	return errors.Join(append(errs, list.Err())...)
+}
+
+// sweepQueueConsumers deletes the consumers of test queues, and the consumers
+// that are test scripts of any other queue, such as the CLOUDFLARE_QUEUE_ID
+// fixture.
+func sweepQueueConsumers(ctx context.Context, client *cloudflare.Client, accountID string) error {
+	list := client.Queues.ListAutoPaging(ctx, queues.QueueListParams{
+		AccountID: cloudflare.F(accountID),
+	})
+
+	var errs []error
+	for list.Next() {
+		queue := list.Current()
+		sweepQueue := utils.IsTestResourceName(queue.QueueName)
+
+		var env queue_consumer.QueueConsumersResultEnvelope
+		_, err := client.Queues.Consumers.Get(
+			ctx,
+			queue.QueueID,
+			queues.ConsumerGetParams{AccountID: cloudflare.F(accountID)},
+			option.WithResponseBodyInto(&env),
+		)
+		if err != nil {
+			if !utils.IsNotFound(err) {
+				errs = append(errs, fmt.Errorf("failed to list consumers of queue %s: %w", queue.QueueName, err))
+			}
+			continue
+		}
+
+		for _, consumer := range env.Result {
+			// older API versions report the script name as `service`
+			script := consumer.ScriptName
+			if script == "" {
+				script = consumer.Service
+			}
+			if !sweepQueue && !utils.IsTestResourceName(script) {
+				continue
+			}
+
+			log.Printf("[INFO] Deleting consumer %s of queue %s", script, queue.QueueName)
+			_, err := client.Queues.Consumers.Delete(ctx, queue.QueueID, consumer.ConsumerID, queues.ConsumerDeleteParams{
+				AccountID: cloudflare.F(accountID),
+			})
+			if err != nil && !utils.IsNotFound(err) {
+				errs = append(errs, fmt.Errorf("failed to delete consumer %s of queue %s: %w", script, queue.QueueName, err))
+			}
+		}
+	}
+
+	return errors.Join(append(errs, list.Err())...)
+}
+
+// sweepR2EventNotifications deletes the event notifications of the
+// R2_BUCKET_NAME fixture that send to test queues or to the
+// CLOUDFLARE_QUEUE_ID fixture.
+func sweepR2EventNotifications(ctx context.Context, client *cloudflare.Client, accountID string) error {
+	bucketName := os.Getenv("R2_BUCKET_NAME")
+	if bucketName == "" {
+		log.Printf("[INFO] Skipping R2 event notifications, R2_BUCKET_NAME is not set")
+		return nil
+	}
+	fixtureQueueID := normalizeQueueID(os.Getenv("CLOUDFLARE_QUEUE_ID"))
+
+	config, err := client.EventNotifications.R2.Configuration.Get(ctx, bucketName, event_notifications.R2ConfigurationGetParams{
+		AccountID: cloudflare.F(accountID),
+	})
+	if utils.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get event notifications of bucket %s: %w", bucketName, err)
+	}
+
+	var errs []error
+	for _, queue := range config.Queues {
+		if !utils.IsTestResourceName(queue.QueueName) &&
+			(fixtureQueueID == "" || normalizeQueueID(queue.QueueID) != fixtureQueueID) {
+			continue
+		}
+
+		log.Printf("[INFO] Deleting event notifications of bucket %s for queue %s", bucketName, queue.QueueName)
+		_, err := client.EventNotifications.R2.Configuration.Queues.Delete(ctx, bucketName, queue.QueueID, event_notifications.R2ConfigurationQueueDeleteParams{
+			AccountID: cloudflare.F(accountID),
+		})
+		if err != nil && !utils.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("failed to delete event notifications of bucket %s for queue %s: %w", bucketName, queue.QueueName, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func sweepWorkersScripts(ctx context.Context, client *cloudflare.Client, accountID string) error {
+	scripts := client.Workers.Scripts.ListAutoPaging(ctx, workers.ScriptListParams{
+		AccountID: cloudflare.F(accountID),
+	})
+
+	var errs []error
+	for scripts.Next() {
+		name := scripts.Current().ID
+		if !utils.IsTestResourceName(name) {
+			continue
+		}
+
+		log.Printf("[INFO] Deleting Workers script %s", name)
+		// force deletes the Durable Objects of the script along with it
+		err := client.Workers.Scripts.Delete(ctx, name, workers.ScriptDeleteParams{
+			AccountID: cloudflare.F(accountID),
+			Force:     cloudflare.F(true),
+		})
+		if err != nil && !utils.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("failed to delete Workers script %s: %w", name, err))
+		}
+	}
+
+	return errors.Join(append(errs, scripts.Err())...)
+}
+
+// normalizeQueueID strips the hyphens some APIs format queue IDs with.
+func normalizeQueueID(id string) string {
+	return strings.ReplaceAll(id, "-", "")
+}
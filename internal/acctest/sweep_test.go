@@ -0,0 +1,136 @@
+package acctest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cloudflare/cloudflare-go/v3"
+	"github.com/cloudflare/cloudflare-go/v3/event_notifications"
+	"github.com/cloudflare/cloudflare-go/v3/option"
+	"github.com/cloudflare/cloudflare-go/v3/queues"
+	"github.com/cloudflare/cloudflare-go/v3/vectorize"
+	"github.com/cloudflare/cloudflare-go/v3/workers"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/acctest/mockserver"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/utils"
+)
+
+func TestSweepers_Offline(t *testing.T) {
+	srv := mockserver.New(t)
+	client := MockClient(srv.BaseURL())
+	ctx := context.Background()
+	accountID := TestAccCloudflareAccountID
+	account := cloudflare.F(accountID)
+
+	leaked := utils.GenerateRandomResourceName()
+	kept := "production"
+	bucketName := "terraform-acctest-bucket"
+	fixtureQueueID := srv.CreateQueue(accountID, "terraform-acctest-queue")
+	leakedQueueID := srv.CreateQueue(accountID, leaked)
+	keptQueueID := srv.CreateQueue(accountID, kept)
+
+	t.Setenv("CLOUDFLARE_QUEUE_ID", fixtureQueueID)
+	t.Setenv("R2_BUCKET_NAME", bucketName)
+
+	for _, name := range []string{leaked, kept} {
+		_, err := client.Vectorize.Indexes.New(ctx, vectorize.IndexNewParams{
+			AccountID: account,
+			Name:      cloudflare.F(name),
+			Config: cloudflare.F(vectorize.IndexNewParamsConfigUnion(vectorize.IndexNewParamsConfig{
+				Dimensions: cloudflare.F(int64(32)),
+				Metric:     cloudflare.F(vectorize.IndexNewParamsConfigMetricCosine),
+			})),
+		})
+		if err != nil {
+			t.Fatalf("create index %s: %v", name, err)
+		}
+
+		body := "--boundary\r\n" +
+			"Content-Disposition: form-data; name=\"metadata\"\r\n\r\n" +
+			`{"main_module":"index.js"}` + "\r\n" +
+			"--boundary\r\n" +
+			"Content-Disposition: form-data; name=\"index.js\"; filename=\"index.js\"\r\n" +
+			"Content-Type: text/javascript+module\r\n\r\n" +
+			"export default {};\r\n" +
+			"--boundary--\r\n"
+		_, err = client.Workers.Scripts.Update(ctx, name, workers.ScriptUpdateParams{AccountID: account},
+			option.WithRequestBody("multipart/form-data; boundary=boundary", []byte(body)),
+		)
+		if err != nil {
+			t.Fatalf("upload script %s: %v", name, err)
+		}
+	}
+
+	// leaked through the queue and the script, and kept ones of the fixture
+	// queue and of another queue
+	consumers := []struct{ queueID, script string }{
+		{leakedQueueID, kept},
+		{fixtureQueueID, leaked},
+		{fixtureQueueID, kept},
+		{keptQueueID, leaked},
+		{keptQueueID, kept},
+	}
+	for _, c := range consumers {
+		_, err := client.Queues.Consumers.New(ctx, c.queueID, queues.ConsumerNewParams{AccountID: account},
+			option.WithRequestBody("application/json", []byte(`{"type":"worker","script_name":"`+c.script+`"}`)),
+		)
+		if err != nil {
+			t.Fatalf("create consumer: %v", err)
+		}
+	}
+
+	// rules of a bucket may not overlap, so each queue gets its own prefix
+	for _, queueID := range []string{fixtureQueueID, leakedQueueID, keptQueueID} {
+		_, err := client.EventNotifications.R2.Configuration.Queues.Update(ctx, bucketName, queueID, event_notifications.R2ConfigurationQueueUpdateParams{
+			AccountID: account,
+			Rules: cloudflare.F([]event_notifications.R2ConfigurationQueueUpdateParamsRule{{
+				Actions: cloudflare.F([]event_notifications.R2ConfigurationQueueUpdateParamsRulesAction{
+					event_notifications.R2ConfigurationQueueUpdateParamsRulesActionPutObject,
+				}),
+				Prefix: cloudflare.F(queueID + "/"),
+			}}),
+		})
+		if err != nil {
+			t.Fatalf("create event notification: %v", err)
+		}
+	}
+
+	// in the order `go test -sweep` runs them
+	for _, sweep := range []sweepFunc{sweepVectorizeIndexes, sweepQueueConsumers, sweepR2EventNotifications, sweepQueues, sweepWorkersScripts} {
+		if err := sweep(ctx, client, accountID); err != nil {
+			t.Fatalf("sweep: %v", err)
+		}
+	}
+
+	if srv.VectorizeIndexExists(accountID, leaked) || !srv.VectorizeIndexExists(accountID, kept) {
+		t.Errorf("expected only index %s to be swept", leaked)
+	}
+	if srv.WorkerScriptExists(accountID, leaked) || !srv.WorkerScriptExists(accountID, kept) {
+		t.Errorf("expected only script %s to be swept", leaked)
+	}
+	if srv.QueueExists(accountID, leakedQueueID) || !srv.QueueExists(accountID, keptQueueID) || !srv.QueueExists(accountID, fixtureQueueID) {
+		t.Errorf("expected only queue %s to be swept", leaked)
+	}
+	for queueID, want := range map[string]int{fixtureQueueID: 1, leakedQueueID: 0, keptQueueID: 1} {
+		if n := srv.QueueConsumerCount(accountID, queueID); n != want {
+			t.Errorf("expected %d consumers of queue %s, got %d", want, queueID, n)
+		}
+	}
+	for queueID, want := range map[string]int{fixtureQueueID: 0, leakedQueueID: 0, keptQueueID: 1} {
+		if n := srv.R2NotificationRuleCount(accountID, bucketName, queueID); n != want {
+			t.Errorf("expected %d event notification rules for queue %s, got %d", want, queueID, n)
+		}
+	}
+}
+
+func TestAddTestSweepers_Dependencies(t *testing.T) {
+	for name, s := range sweepers {
+		if s.Name != name {
+			t.Errorf("sweeper %s is registered as %s", s.Name, name)
+		}
+		for _, dependency := range s.Dependencies {
+			if _, ok := sweepers[dependency]; !ok {
+				t.Errorf("sweeper %s depends on unknown sweeper %s", name, dependency)
+			}
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package acctest
+
+import (
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/cloudflare/cloudflare-go/v3"
+	"github.com/cloudflare/cloudflare-go/v3/option"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/acctest/vcr"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/consts"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/provider"
+)
+
+// vcrPlaceholders are the values recorded in cassettes in place of the
+// environment acceptance tests read. When a test is replayed, its environment
+// is set to them, so it builds the same configuration as when it was recorded.
+var vcrPlaceholders = map[string]string{
+	consts.AccountIDEnvVarKey: TestAccCloudflareAccountID,
+	"CLOUDFLARE_QUEUE_ID":     "0f1e2d3c4b5a69788796a5b4c3d2e1f0",
+	"CLOUDFLARE_WORKER_NAME":  "terraform-acctest-worker",
+	"R2_BUCKET_NAME":          "terraform-acctest-bucket",
+}
+
+// vcrSecrets are the environment variables whose values must never end up in
+// a cassette.
+var vcrSecrets = []string{
+	consts.APITokenEnvVarKey,
+	consts.APIKeyEnvVarKey,
+	consts.EmailEnvVarKey,
+	consts.APIUserServiceKeyEnvVarKey,
+}
+
+var (
+	recordersMu sync.Mutex
+	recorders   = make(map[testing.TB]*vcr.Recorder)
+)
+
+// TestAccProtoV6ProviderFactories returns the provider factories of the
+// acceptance test t. The provider sends its requests through the recorder of
+// the test, selected by CLOUDFLARE_VCR_MODE.
+func TestAccProtoV6ProviderFactories(t *testing.T) map[string]func() (tfprotov6.ProviderServer, error) {
+	httpClient := HTTPClient(t)
+
+	return map[string]func() (tfprotov6.ProviderServer, error){
+		"cloudflare-extended": func() (tfprotov6.ProviderServer, error) {
+			return providerserver.NewProtocol6(provider.NewWithHTTPClient("dev", httpClient)())(), nil
+		},
+	}
+}
+
+// Parallel signals that the acceptance test t runs in parallel, unless it
+// replays a cassette: replayed tests set their environment, which parallel
+// tests cannot.
+func Parallel(t *testing.T) {
+	if recorder(t).Replaying() {
+		return
+	}
+	t.Parallel()
+}
+
+// Client returns an API client for checking objects out-of-band during the
+// acceptance test t, sending its requests through the recorder of the test.
+func Client(t *testing.T) *cloudflare.Client {
+	return cloudflare.NewClient(
+		option.WithAPIToken(os.Getenv(consts.APITokenEnvVarKey)),
+		option.WithHTTPClient(HTTPClient(t)),
+	)
+}
+
+// HTTPClient returns an HTTP client sending its requests through the recorder
+// of the acceptance test t. Requests to the offline mock API server, which
+// listens on a loopback address, are never recorded.
+func HTTPClient(t *testing.T) *http.Client {
+	return &http.Client{Transport: loopbackTransport{recorder(t)}}
+}
+
+// loopbackTransport sends requests to loopback addresses directly, and every
+// other request through the recorder.
+type loopbackTransport struct {
+	recorder http.RoundTripper
+}
+
+func (l loopbackTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := req.URL.Hostname()
+	if ip := net.ParseIP(host); host == "localhost" || (ip != nil && ip.IsLoopback()) {
+		return http.DefaultTransport.RoundTrip(req)
+	}
+	return l.recorder.RoundTrip(req)
+}
+
+// RandomResourceName returns a name for a resource created by the acceptance
+// test t, which is the same every time its cassette is replayed.
+func RandomResourceName(t *testing.T) string {
+	return recorder(t).ResourceName()
+}
+
+func recorder(t *testing.T) *vcr.Recorder {
+	t.Helper()
+
+	recordersMu.Lock()
+	defer recordersMu.Unlock()
+
+	if r, ok := recorders[t]; ok {
+		return r
+	}
+
+	mode, err := vcr.ModeFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := vcr.New(t, mode, vcr.CassettePath(t))
+	for key, placeholder := range vcrPlaceholders {
+		r.Scrub(os.Getenv(key), placeholder)
+	}
+	for _, key := range vcrSecrets {
+		r.Scrub(os.Getenv(key), "[redacted]")
+	}
+
+	// a replayed test builds the same configuration as when it was recorded,
+	// and never sees real credentials
+	if r.Replaying() {
+		for _, key := range vcrSecrets {
+			t.Setenv(key, "")
+			os.Unsetenv(key)
+		}
+		for key, placeholder := range vcrPlaceholders {
+			t.Setenv(key, placeholder)
+		}
+		// the provider requires credentials, even though no request is sent
+		t.Setenv(consts.APITokenEnvVarKey, MockAPIToken)
+	}
+
+	recorders[t] = r
+	t.Cleanup(func() {
+		recordersMu.Lock()
+		defer recordersMu.Unlock()
+
+		delete(recorders, t)
+	})
+
+	return r
+}
@@ -0,0 +1,456 @@
+// Package vcr records the API traffic of acceptance tests to cassettes and
+// replays it, so that they can run without a live account.
+//
+// The mode is selected with the CLOUDFLARE_VCR_MODE environment variable:
+//
+//   - unset: requests go to the API and nothing is recorded.
+//   - "record": requests go to the API and, once the test passes, are written
+//     to a cassette under the `testdata/cassettes` directory of the package.
+//   - "replay": requests are answered from the cassette, without network
+//     access. Requests of tests without a cassette are rejected.
+//
+// Cassettes never hold credentials: only the method, path, query and body of
+// requests are recorded, secret values in bodies are redacted, and values
+// registered with [Recorder.Scrub] are replaced by placeholders.
+package vcr
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/logging"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/utils"
+)
+
+// ModeEnvVarKey is the environment variable selecting the mode of recorders.
+const ModeEnvVarKey = "CLOUDFLARE_VCR_MODE"
+
+// Mode is what a recorder does with the requests of a test.
+type Mode string
+
+const (
+	// ModeLive sends requests to the API without recording them.
+	ModeLive Mode = ""
+
+	// ModeRecord sends requests to the API and records them.
+	ModeRecord Mode = "record"
+
+	// ModeReplay answers requests from a cassette.
+	ModeReplay Mode = "replay"
+)
+
+// ModeFromEnv returns the mode selected by the environment.
+func ModeFromEnv() (Mode, error) {
+	switch mode := Mode(os.Getenv(ModeEnvVarKey)); mode {
+	case ModeLive, ModeRecord, ModeReplay:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid %s %q, must be %q or %q", ModeEnvVarKey, mode, ModeRecord, ModeReplay)
+	}
+}
+
+// Cassette is the recorded API traffic of a test, in the order it was sent.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a request and the response the API answered it with.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is a recorded request. URL holds the path and query only.
+type Request struct {
+	Method      string `json:"method"`
+	URL         string `json:"url"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        Body   `json:"body,omitempty"`
+}
+
+// Response is a recorded response.
+type Response struct {
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        Body   `json:"body,omitempty"`
+}
+
+// Body is recorded as a string, or base64 encoded when it isn't valid UTF-8,
+// e.g. a WebAssembly module of a script upload.
+type Body []byte
+
+func (b Body) MarshalJSON() ([]byte, error) {
+	if utf8.Valid(b) {
+		return json.Marshal(string(b))
+	}
+	return json.Marshal(map[string]string{"base64": base64.StdEncoding.EncodeToString(b)})
+}
+
+func (b *Body) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*b = Body(s)
+		return nil
+	}
+
+	var encoded struct {
+		Base64 string `json:"base64"`
+	}
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return err
+	}
+	decoded, err := base64.StdEncoding.DecodeString(encoded.Base64)
+	if err != nil {
+		return err
+	}
+	*b = decoded
+	return nil
+}
+
+// Recorder is the HTTP transport of a single test.
+type Recorder struct {
+	t         testing.TB
+	mode      Mode
+	path      string
+	transport http.RoundTripper
+
+	mu       sync.Mutex
+	scrubs   map[string]string
+	names    int
+	recorded bool
+	cassette Cassette
+	used     []bool
+}
+
+// New returns the recorder of test t for the cassette at path. In replay mode
+// a missing cassette answers no request; in record mode it is written once the
+// test passes, if it sent any.
+func New(t testing.TB, mode Mode, path string) *Recorder {
+	r := &Recorder{
+		t:         t,
+		mode:      mode,
+		path:      path,
+		transport: http.DefaultTransport,
+		scrubs:    make(map[string]string),
+	}
+
+	switch mode {
+	case ModeReplay:
+		content, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("failed to read cassette: %s", err)
+		}
+		if err := json.Unmarshal(content, &r.cassette); err != nil {
+			t.Fatalf("failed to parse cassette %s: %s", path, err)
+		}
+		r.used = make([]bool, len(r.cassette.Interactions))
+		r.recorded = true
+	case ModeRecord:
+		t.Cleanup(func() {
+			if t.Failed() {
+				t.Logf("not writing cassette %s of a failed test", path)
+				return
+			}
+			if len(r.cassette.Interactions) == 0 {
+				return
+			}
+			if err := r.save(); err != nil {
+				t.Errorf("failed to write cassette: %s", err)
+			}
+		})
+	}
+
+	return r
+}
+
+// CassettePath returns the path of the cassette of test t, relative to the
+// directory of its package.
+func CassettePath(t testing.TB) string {
+	name := strings.NewReplacer("/", "_", " ", "_").Replace(t.Name())
+	return filepath.Join("testdata", "cassettes", name+".json")
+}
+
+// Mode returns the mode of the recorder.
+func (r *Recorder) Mode() Mode {
+	return r.mode
+}
+
+// Replaying reports whether the recorder answers requests from a cassette.
+func (r *Recorder) Replaying() bool {
+	return r.mode == ModeReplay && r.recorded
+}
+
+// HTTPClient returns an HTTP client sending its requests through the recorder.
+func (r *Recorder) HTTPClient() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// Scrub replaces every occurrence of value in recorded requests and responses
+// with placeholder.
+func (r *Recorder) Scrub(value, placeholder string) {
+	if value == "" || value == placeholder {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.scrubs[value] = placeholder
+}
+
+// ResourceName returns a name for a resource created by the test. It is random
+// when the API is live, and recorded as a placeholder derived from the name of
+// the test, so that a replay uses the same names as the cassette.
+func (r *Recorder) ResourceName() string {
+	r.mu.Lock()
+	hash := fnv.New64a()
+	fmt.Fprintf(hash, "%s/%d", r.t.Name(), r.names)
+	r.names++
+	r.mu.Unlock()
+
+	sum := hash.Sum64()
+	placeholder := make([]byte, utils.ResourceNameLength)
+	for i := range placeholder {
+		placeholder[i] = utils.CharSetAlpha[sum%uint64(len(utils.CharSetAlpha))]
+		sum /= uint64(len(utils.CharSetAlpha))
+	}
+
+	if r.mode == ModeReplay {
+		return utils.ResourceNamePrefix + string(placeholder)
+	}
+
+	name := utils.GenerateRandomResourceName()
+	r.Scrub(name, utils.ResourceNamePrefix+string(placeholder))
+	return name
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	switch r.mode {
+	case ModeReplay:
+		return r.replay(req, body)
+	case ModeRecord:
+		return r.record(req, body)
+	default:
+		return r.transport.RoundTrip(req)
+	}
+}
+
+func (r *Recorder) record(req *http.Request, body []byte) (*http.Response, error) {
+	resp, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{
+		Request: r.scrubRequest(req, body),
+		Response: Response{
+			Status:      resp.StatusCode,
+			ContentType: resp.Header.Get("Content-Type"),
+			Body:        r.scrubBody(resp.Header.Get("Content-Type"), respBody),
+		},
+	})
+
+	return resp, nil
+}
+
+func (r *Recorder) replay(req *http.Request, body []byte) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	want := r.scrubRequest(req, body)
+	for i, interaction := range r.cassette.Interactions {
+		if r.used[i] || !matches(interaction.Request, want) {
+			continue
+		}
+		r.used[i] = true
+
+		header := http.Header{}
+		if interaction.Response.ContentType != "" {
+			header.Set("Content-Type", interaction.Response.ContentType)
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", interaction.Response.Status, http.StatusText(interaction.Response.Status)),
+			StatusCode:    interaction.Response.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          io.NopCloser(bytes.NewReader(interaction.Response.Body)),
+			ContentLength: int64(len(interaction.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	// answered with a client error, so that it isn't retried
+	message := fmt.Sprintf("no recorded interaction in %s for %s %s", r.path, want.Method, want.URL)
+	r.t.Errorf("vcr: %s", message)
+	errBody, _ := json.Marshal(map[string]any{
+		"success":  false,
+		"errors":   []map[string]any{{"code": 0, "message": message}},
+		"messages": []any{},
+		"result":   nil,
+	})
+	return &http.Response{
+		Status:     "400 Bad Request",
+		StatusCode: http.StatusBadRequest,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader(errBody)),
+		Request:    req,
+	}, nil
+}
+
+func (r *Recorder) save() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	content, err := json.MarshalIndent(r.cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(r.path, append(content, '\n'), 0o644)
+}
+
+func (r *Recorder) scrubRequest(req *http.Request, body []byte) Request {
+	requestURL := req.URL.EscapedPath()
+	if query := req.URL.Query(); len(query) > 0 {
+		// sorted, so the order parameters are added in doesn't matter
+		requestURL += "?" + query.Encode()
+	}
+	if unescaped, err := url.PathUnescape(requestURL); err == nil {
+		requestURL = unescaped
+	}
+
+	return Request{
+		Method:      req.Method,
+		URL:         r.scrub(requestURL),
+		ContentType: mediaType(req.Header.Get("Content-Type")),
+		Body:        r.scrubBody(req.Header.Get("Content-Type"), body),
+	}
+}
+
+func (r *Recorder) scrubBody(contentType string, body []byte) Body {
+	if len(body) == 0 {
+		return nil
+	}
+
+	return Body(r.scrub(string(logging.Redact(contentType, body))))
+}
+
+// scrub replaces the registered values in s, longest first, so that a value
+// containing another is replaced as a whole.
+func (r *Recorder) scrub(s string) string {
+	values := make([]string, 0, len(r.scrubs))
+	for value := range r.scrubs {
+		values = append(values, value)
+	}
+	sort.Slice(values, func(i, j int) bool { return len(values[i]) > len(values[j]) })
+
+	pairs := make([]string, 0, 2*len(values))
+	for _, value := range values {
+		pairs = append(pairs, value, r.scrubs[value])
+	}
+
+	return strings.NewReplacer(pairs...).Replace(s)
+}
+
+func matches(recorded, req Request) bool {
+	return recorded.Method == req.Method &&
+		recorded.URL == req.URL &&
+		normalizeBody(recorded.ContentType, recorded.Body) == normalizeBody(req.ContentType, req.Body)
+}
+
+// normalizeBody returns body in a form that doesn't change between runs of a
+// test: JSON with sorted keys, and multipart bodies without their random
+// boundary.
+func normalizeBody(contentType string, body []byte) string {
+	media, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return string(body)
+	}
+
+	switch {
+	case strings.HasPrefix(media, "multipart/"):
+		if boundary := boundaryOf(body, params["boundary"]); boundary != "" {
+			return strings.ReplaceAll(string(body), boundary, "boundary")
+		}
+	case media == "application/json" || strings.HasSuffix(media, "+json"):
+		var value any
+		if err := json.Unmarshal(body, &value); err == nil {
+			normalized, _ := json.Marshal(value)
+			return string(normalized)
+		}
+	}
+
+	return string(body)
+}
+
+// boundaryOf returns the boundary of a multipart body. Recorded requests only
+// keep the media type of their content type, so the boundary is read from the
+// first line of the body instead.
+func boundaryOf(body []byte, boundary string) string {
+	if boundary != "" {
+		return boundary
+	}
+
+	line, _, _ := bytes.Cut(body, []byte("\r\n"))
+	return strings.TrimPrefix(string(line), "--")
+}
+
+func mediaType(contentType string) string {
+	media, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return contentType
+	}
+
+	// the boundary of multipart bodies is random
+	delete(params, "boundary")
+	return mime.FormatMediaType(media, params)
+}
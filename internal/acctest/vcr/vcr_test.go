@@ -0,0 +1,134 @@
+package vcr_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cloudflare/cloudflare-go/v3"
+	"github.com/cloudflare/cloudflare-go/v3/option"
+	"github.com/cloudflare/cloudflare-go/v3/vectorize"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/acctest"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/acctest/mockserver"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/acctest/vcr"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/utils"
+)
+
+const (
+	accountID   = "0123456789abcdef0123456789abcdef"
+	placeholder = "f037e56e89293a057740de681ac9abbe"
+)
+
+// exercise creates, reads and deletes a Vectorize index, returning the
+// dimensions read back.
+func exercise(t *testing.T, client *cloudflare.Client, accountID string) int64 {
+	ctx := context.Background()
+
+	_, err := client.Vectorize.Indexes.New(ctx, vectorize.IndexNewParams{
+		AccountID: cloudflare.F(accountID),
+		Name:      cloudflare.F("index"),
+		Config: cloudflare.F(vectorize.IndexNewParamsConfigUnion(vectorize.IndexNewParamsConfig{
+			Dimensions: cloudflare.F(int64(32)),
+			Metric:     cloudflare.F(vectorize.IndexNewParamsConfigMetricCosine),
+		})),
+	})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	index, err := client.Vectorize.Indexes.Get(ctx, "index", vectorize.IndexGetParams{AccountID: cloudflare.F(accountID)})
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+
+	if _, err := client.Vectorize.Indexes.Delete(ctx, "index", vectorize.IndexDeleteParams{AccountID: cloudflare.F(accountID)}); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	return index.Config.Dimensions
+}
+
+func TestRecorder_RecordReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassettes", "test.json")
+
+	t.Run("record", func(t *testing.T) {
+		srv := mockserver.New(t)
+		recorder := vcr.New(t, vcr.ModeRecord, path)
+		recorder.Scrub(accountID, placeholder)
+
+		client := cloudflare.NewClient(
+			option.WithBaseURL(srv.BaseURL()),
+			option.WithAPIToken(acctest.MockAPIToken),
+			option.WithHTTPClient(recorder.HTTPClient()),
+		)
+		if got := exercise(t, client, accountID); got != 32 {
+			t.Errorf("got dimensions %d, want 32", got)
+		}
+	})
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("cassette was not written: %s", err)
+	}
+	for _, secret := range []string{accountID, acctest.MockAPIToken} {
+		if strings.Contains(string(content), secret) {
+			t.Errorf("cassette contains %q: %s", secret, content)
+		}
+	}
+
+	t.Run("replay", func(t *testing.T) {
+		recorder := vcr.New(t, vcr.ModeReplay, path)
+
+		// nothing listens here, every response comes from the cassette
+		client := cloudflare.NewClient(
+			option.WithBaseURL("http://127.0.0.1:1/client/v4/"),
+			option.WithAPIToken(acctest.MockAPIToken),
+			option.WithHTTPClient(recorder.HTTPClient()),
+			option.WithMaxRetries(0),
+		)
+		if got := exercise(t, client, placeholder); got != 32 {
+			t.Errorf("got dimensions %d, want 32", got)
+		}
+	})
+}
+
+func TestRecorder_ReplayWithoutCassette(t *testing.T) {
+	recorder := vcr.New(t, vcr.ModeReplay, filepath.Join(t.TempDir(), "missing.json"))
+
+	if recorder.Replaying() {
+		t.Error("expected a recorder without cassette not to replay")
+	}
+}
+
+func TestRecorder_RecordNothing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassettes", "test.json")
+
+	t.Run("record", func(t *testing.T) {
+		vcr.New(t, vcr.ModeRecord, path)
+	})
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected no cassette for a test without requests, got %v", err)
+	}
+}
+
+func TestRecorder_ResourceName(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "empty.json")
+	if err := os.WriteFile(path, []byte(`{"interactions":[]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	first, second := vcr.New(t, vcr.ModeReplay, path), vcr.New(t, vcr.ModeReplay, path)
+	for range 3 {
+		if a, b := first.ResourceName(), second.ResourceName(); a != b || !utils.IsTestResourceName(a) {
+			t.Errorf("replayed resource names %q and %q differ", a, b)
+		}
+	}
+
+	live := vcr.New(t, vcr.ModeLive, "")
+	if a, b := live.ResourceName(), live.ResourceName(); a == b {
+		t.Errorf("live resource names %q and %q are the same", a, b)
+	}
+}
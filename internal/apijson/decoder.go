@@ -114,6 +114,9 @@ type decoderEntry struct {
 	dateFormat     string
 	root           bool
 	tfSkipBehavior TerraformUpdateBehavior
+	// decoders built for [UnmarshalComputed] skip non-computed fields, so they
+	// must not be reused by [Unmarshal]
+	computedOnly bool
 }
 
 func (d *decoderBuilder) unmarshal(raw []byte, to any) error {
@@ -131,6 +134,7 @@ func (d *decoderBuilder) typeDecoder(t reflect.Type) decoderFunc {
 		dateFormat:     d.dateFormat,
 		root:           d.root,
 		tfSkipBehavior: d.updateBehavior,
+		computedOnly:   d.unmarshalComputedOnly,
 	}
 
 	if fi, ok := decoders.Load(entry); ok {
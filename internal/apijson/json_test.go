@@ -1261,6 +1261,31 @@ func TestDecodeComputedOnly(t *testing.T) {
 	}
 }
 
+type ComputedOnlyEnvelope struct {
+	Result StructWithComputedFields `json:"result"`
+}
+
+func TestDecodeAfterComputedOnly(t *testing.T) {
+	buf := `{"result":{"str":"str","comp_str":"comp_str"}}`
+
+	// the decoder built for UnmarshalComputed must not be reused by Unmarshal
+	computed := ComputedOnlyEnvelope{}
+	if err := UnmarshalComputed([]byte(buf), &computed); err != nil {
+		t.Fatalf("deserialization of %v failed with error %v", buf, err)
+	}
+	if !computed.Result.RegStr.IsNull() {
+		t.Fatalf("expected UnmarshalComputed to leave str unset, got %s", computed.Result.RegStr)
+	}
+
+	all := ComputedOnlyEnvelope{}
+	if err := Unmarshal([]byte(buf), &all); err != nil {
+		t.Fatalf("deserialization of %v failed with error %v", buf, err)
+	}
+	if all.Result.RegStr.ValueString() != "str" {
+		t.Fatalf("expected Unmarshal to set str, got %s", all.Result.RegStr)
+	}
+}
+
 func merge[T interface{}](test_array ...map[string]T) map[string]T {
 	out := make(map[string]T)
 	for _, tests := range test_array {
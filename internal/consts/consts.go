@@ -34,6 +34,9 @@ const (
 	// Schema key for the account ID configuration.
 	AccountIDSchemaKey = "account_id"
 
+	// Environment variable key for the provider default account ID.
+	AccountIDEnvVarKey = "CLOUDFLARE_ACCOUNT_ID"
+
 	// Schema description for `account_id` field.
 	AccountIDSchemaDescription = "The account identifier to target for the resource."
 
@@ -54,4 +57,38 @@ const (
 
 	// Environment variable key for the client base URL.
 	BaseURLEnvVarKey = "CLOUDFLARE_BASE_URL"
+
+	// Schema key for the maximum number of retries of a failed request.
+	MaxRetriesSchemaKey = "max_retries"
+
+	// Environment variable key for the maximum number of retries.
+	MaxRetriesEnvVarKey = "CLOUDFLARE_MAX_RETRIES"
+
+	// Schema key for the minimum backoff between retries, in seconds.
+	MinBackoffSchemaKey = "min_backoff"
+
+	// Environment variable key for the minimum backoff between retries.
+	MinBackoffEnvVarKey = "CLOUDFLARE_MIN_BACKOFF"
+
+	// Schema key for the maximum backoff between retries, in seconds.
+	MaxBackoffSchemaKey = "max_backoff"
+
+	// Environment variable key for the maximum backoff between retries.
+	MaxBackoffEnvVarKey = "CLOUDFLARE_MAX_BACKOFF"
+
+	// Schema key for the maximum number of API requests in flight.
+	MaxConcurrentRequestsSchemaKey = "max_concurrent_requests"
+
+	// Environment variable key for the maximum number of API requests in flight.
+	MaxConcurrentRequestsEnvVarKey = "CLOUDFLARE_MAX_CONCURRENT_REQUESTS"
+
+	// Schema key for the maximum rate of API requests.
+	RequestsPerSecondSchemaKey = "requests_per_second"
+
+	// Environment variable key for the maximum rate of API requests.
+	RequestsPerSecondEnvVarKey = "CLOUDFLARE_REQUESTS_PER_SECOND"
+
+	// Environment variable key for the size above which logged request and
+	// response bodies are truncated.
+	LogMaxBodySizeEnvVarKey = "CLOUDFLARE_LOG_MAX_BODY_SIZE"
 )
@@ -1,28 +1,50 @@
+// Package logging logs the requests of the API client to tflog, with secrets
+// redacted and large bodies truncated or summarized.
+//
+// Bodies longer than CLOUDFLARE_LOG_MAX_BODY_SIZE bytes (16 KiB by default, 0
+// for no limit) are truncated.
 package logging
 
 import (
 	"bytes"
 	"context"
-	"fmt"
 	"io"
 	"net/http"
 	"slices"
+	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/cloudflare/cloudflare-go/v3/option"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/consts"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/utils"
 )
 
+// defaultMaxBodySize is the size above which logged bodies are truncated.
+const defaultMaxBodySize = 16 * 1024
+
+var sensitiveHeaderNames = []string{"x-auth-email", "x-auth-key", "x-auth-user-service-key", "authorization"}
+
 func Middleware(ctx context.Context) option.Middleware {
 	return func(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
 		if req != nil {
 			LogRequest(ctx, req)
 		}
 
+		start := time.Now()
 		resp, err := next(req)
 
 		if resp != nil {
-			LogResponse(ctx, resp)
+			LogResponse(ctx, resp, time.Since(start))
+		} else if err != nil && req != nil {
+			tflog.Debug(ctx, "Cloudflare API request failed", map[string]any{
+				"http_method":      req.Method,
+				"http_path":        req.URL.Path,
+				"http_duration_ms": time.Since(start).Milliseconds(),
+				"error":            err.Error(),
+			})
 		}
 
 		return resp, err
@@ -30,50 +52,42 @@ func Middleware(ctx context.Context) option.Middleware {
 }
 
 func LogRequest(ctx context.Context, req *http.Request) error {
-	sensitiveHeaderNames := []string{"x-auth-email", "x-auth-key", "x-auth-user-service-key", "authorization"}
-
-	lines := []string{fmt.Sprintf("\n%s %s %s", req.Method, req.URL.Path, req.Proto)}
-
-	// Log headers
-	for name, values := range req.Header {
-		for _, value := range values {
-
-			if slices.Contains(sensitiveHeaderNames, strings.ToLower(name)) {
-				value = "[redacted]"
-			}
-
-			lines = append(lines, fmt.Sprintf("> %s: %s", strings.ToLower(name), value))
-		}
+	fields := map[string]any{
+		"http_method":          req.Method,
+		"http_path":            req.URL.Path,
+		"http_request_headers": logHeaders(req.Header),
 	}
 
 	if req.Body != nil {
-		// Read the body without mutating the original response
+		// Read the body without mutating the original request
 		bodyBytes, err := io.ReadAll(req.Body)
 		if err != nil {
 			return err
 		}
 
-		// Restore the original body to the response so it can be read again
+		// Restore the original body to the request so it can be read again
 		req.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
 
-		// Log the body
-		lines = append(lines, ">\n", string(bodyBytes), "\n")
+		fields["http_request_body"] = logBody(req.Header.Get("Content-Type"), bodyBytes)
 	}
 
-	tflog.Debug(ctx, strings.Join(lines, "\n"))
+	tflog.Debug(ctx, "Cloudflare API request", fields)
 
 	return nil
 }
 
-func LogResponse(ctx context.Context, resp *http.Response) error {
-	// Log the status code
-	lines := []string{fmt.Sprintf("\n< %s %s", resp.Proto, resp.Status)}
-
-	// Log headers
-	for name, values := range resp.Header {
-		for _, value := range values {
-			lines = append(lines, fmt.Sprintf("< %s: %s", strings.ToLower(name), value))
-		}
+func LogResponse(ctx context.Context, resp *http.Response, duration time.Duration) error {
+	fields := map[string]any{
+		"http_status":           resp.StatusCode,
+		"http_duration_ms":      duration.Milliseconds(),
+		"http_response_headers": logHeaders(resp.Header),
+	}
+	if resp.Request != nil {
+		fields["http_method"] = resp.Request.Method
+		fields["http_path"] = resp.Request.URL.Path
+	}
+	if ray := resp.Header.Get("cf-ray"); ray != "" {
+		fields["cf_ray"] = ray
 	}
 
 	// Read the body without mutating the original response
@@ -85,10 +99,50 @@ func LogResponse(ctx context.Context, resp *http.Response) error {
 	// Restore the original body to the response so it can be read again
 	resp.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
 
-	lines = append(lines, "<\n", string(bodyBytes), "\n")
+	fields["http_response_body"] = logBody(resp.Header.Get("Content-Type"), bodyBytes)
 
-	// Log the body
-	tflog.Debug(ctx, strings.Join(lines, "\n"))
+	tflog.Debug(ctx, "Cloudflare API response", fields)
 
 	return nil
 }
+
+func logHeaders(header http.Header) map[string]string {
+	headers := make(map[string]string, len(header))
+	for name, values := range header {
+		name = strings.ToLower(name)
+		if slices.Contains(sensitiveHeaderNames, name) {
+			headers[name] = redacted
+			continue
+		}
+		headers[name] = strings.Join(values, ", ")
+	}
+
+	return headers
+}
+
+// logBody returns body as it is logged, redacted and truncated.
+func logBody(contentType string, body []byte) string {
+	return truncate(string(redactBody(contentType, body)), maxBodySize())
+}
+
+func maxBodySize() int {
+	size, err := strconv.Atoi(utils.GetDefaultFromEnv(consts.LogMaxBodySizeEnvVarKey, ""))
+	if err != nil || size < 0 {
+		return defaultMaxBodySize
+	}
+	return size
+}
+
+func truncate(body string, limit int) string {
+	if limit == 0 || len(body) <= limit {
+		return body
+	}
+
+	// back off to the start of a rune, so a multi-byte character is not split
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(body[cut]) {
+		cut--
+	}
+
+	return body[:cut] + "... [truncated " + strconv.Itoa(len(body)-cut) + " bytes]"
+}
@@ -0,0 +1,99 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/consts"
+)
+
+func TestMiddleware(t *testing.T) {
+	t.Setenv(consts.LogMaxBodySizeEnvVarKey, "")
+
+	var output bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &output)
+
+	req, err := http.NewRequest(http.MethodPut, "https://api.cloudflare.com/client/v4/accounts/a/queues/q", strings.NewReader(`{"queue_name":"q"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer hunter2")
+	req.Header.Set("Content-Type", "application/json")
+
+	_, err = Middleware(ctx)(req, func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Cf-Ray": {"8d5a1b2c3d4e5f60-AMS"}, "Content-Type": {"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(`{"success":true}`)),
+			Request:    req,
+		}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := tflogtest.MultilineJSONDecode(&output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected a request and a response entry, got %d", len(entries))
+	}
+
+	request, response := entries[0], entries[1]
+	for field, want := range map[string]any{
+		"http_method":       "PUT",
+		"http_path":         "/client/v4/accounts/a/queues/q",
+		"http_request_body": `{"queue_name":"q"}`,
+	} {
+		if request[field] != want {
+			t.Errorf("request %s = %v, want %v", field, request[field], want)
+		}
+	}
+	if headers, _ := request["http_request_headers"].(map[string]any); headers["authorization"] != redacted {
+		t.Errorf("authorization header was not redacted: %v", request["http_request_headers"])
+	}
+	for field, want := range map[string]any{
+		"http_method":        "PUT",
+		"http_status":        float64(http.StatusOK),
+		"cf_ray":             "8d5a1b2c3d4e5f60-AMS",
+		"http_response_body": `{"success":true}`,
+	} {
+		if response[field] != want {
+			t.Errorf("response %s = %v, want %v", field, response[field], want)
+		}
+	}
+	if _, ok := response["http_duration_ms"]; !ok {
+		t.Errorf("response has no duration: %v", response)
+	}
+	if raw, _ := json.Marshal(entries); strings.Contains(string(raw), "hunter2") {
+		t.Errorf("credentials were logged: %s", raw)
+	}
+}
+
+func TestLogBody_Truncated(t *testing.T) {
+	t.Setenv(consts.LogMaxBodySizeEnvVarKey, "8")
+
+	got := logBody("text/plain", []byte("0123456789abcdef"))
+
+	if want := "01234567... [truncated 8 bytes]"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestLogBody_TruncatedOnRuneBoundary(t *testing.T) {
+	t.Setenv(consts.LogMaxBodySizeEnvVarKey, "8")
+
+	// the limit falls inside the three bytes of "€"
+	got := logBody("text/plain", []byte("012345€6789"))
+
+	if want := "012345... [truncated 7 bytes]"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
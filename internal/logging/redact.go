@@ -0,0 +1,205 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"strings"
+)
+
+const redacted = "[redacted]"
+
+// redactBody returns body with secret values replaced, so it can be logged.
+// JSON bodies are redacted. Multipart bodies are summarized with the name,
+// content type and size of every part, plus the redacted content of JSON parts
+// (e.g. the metadata part of a Workers script upload). Anything else is
+// returned as is.
+func redactBody(contentType string, body []byte) []byte {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return body
+	}
+
+	switch {
+	case strings.HasPrefix(mediaType, "multipart/"):
+		return summarizeMultipart(body, params["boundary"])
+	case isJSON(mediaType):
+		return redactJSON(body)
+	}
+
+	return body
+}
+
+// Redact returns body with secret values replaced, keeping everything else as
+// is. Unlike the logged form of a body, multipart bodies keep the content of
+// every part, with only their JSON parts redacted.
+func Redact(contentType string, body []byte) []byte {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return body
+	}
+
+	switch {
+	case strings.HasPrefix(mediaType, "multipart/"):
+		return redactMultipart(body, params["boundary"])
+	case isJSON(mediaType):
+		return redactJSON(body)
+	}
+
+	return body
+}
+
+func redactMultipart(body []byte, boundary string) []byte {
+	if boundary == "" {
+		return body
+	}
+
+	buf := &bytes.Buffer{}
+	writer := multipart.NewWriter(buf)
+	if err := writer.SetBoundary(boundary); err != nil {
+		return body
+	}
+
+	reader := multipart.NewReader(bytes.NewReader(body), boundary)
+	for {
+		part, err := reader.NextRawPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return body
+		}
+
+		content, err := io.ReadAll(part)
+		if err != nil {
+			return body
+		}
+
+		// the metadata part of a script upload is sent without a content type
+		if part.FormName() == "metadata" || isJSON(part.Header.Get("Content-Type")) {
+			content = redactJSON(content)
+		}
+
+		w, err := writer.CreatePart(part.Header)
+		if err != nil {
+			return body
+		}
+		if _, err := w.Write(content); err != nil {
+			return body
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return body
+	}
+
+	return buf.Bytes()
+}
+
+func summarizeMultipart(body []byte, boundary string) []byte {
+	if boundary == "" {
+		return body
+	}
+
+	buf := &bytes.Buffer{}
+	reader := multipart.NewReader(bytes.NewReader(body), boundary)
+	for {
+		part, err := reader.NextRawPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return body
+		}
+
+		content, err := io.ReadAll(part)
+		if err != nil {
+			return body
+		}
+
+		contentType := part.Header.Get("Content-Type")
+		fmt.Fprintf(buf, "part %q (%s, %d bytes)\n", part.FormName(), contentType, len(content))
+
+		// the metadata part of a script upload is sent without a content type
+		if part.FormName() == "metadata" || isJSON(contentType) {
+			buf.Write(redactJSON(content))
+			buf.WriteString("\n")
+		}
+	}
+
+	return buf.Bytes()
+}
+
+func redactJSON(body []byte) []byte {
+	var value any
+	if err := json.Unmarshal(body, &value); err != nil {
+		return body
+	}
+
+	if !redactValue(value) {
+		return body
+	}
+
+	redactedBody, err := json.Marshal(value)
+	if err != nil {
+		return body
+	}
+
+	return redactedBody
+}
+
+// redactValue replaces the text of secret bindings and the values of sensitive
+// keys found anywhere in value and reports whether anything was replaced.
+func redactValue(value any) (changed bool) {
+	switch v := value.(type) {
+	case map[string]any:
+		// the value of a Workers secret_text binding
+		if v["type"] == "secret_text" {
+			if _, ok := v["text"]; ok {
+				v["text"] = redacted
+				changed = true
+			}
+		}
+		for k, child := range v {
+			if child != nil && child != redacted && isSensitiveKey(k) {
+				v[k] = redacted
+				changed = true
+				continue
+			}
+			changed = redactValue(child) || changed
+		}
+	case []any:
+		for _, child := range v {
+			changed = redactValue(child) || changed
+		}
+	}
+
+	return changed
+}
+
+// sensitiveKeys are the JSON keys whose values are never logged, on their own
+// or as the suffix of a key, e.g. `client_secret` or `access_token`.
+var sensitiveKeys = []string{"secret", "token", "password", "api_key", "private_key", "secret_access_key"}
+
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitive := range sensitiveKeys {
+		if key == sensitive || strings.HasSuffix(key, "_"+sensitive) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
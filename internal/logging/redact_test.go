@@ -0,0 +1,94 @@
+package logging
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/textproto"
+	"strings"
+	"testing"
+)
+
+func TestRedactBody_JSON(t *testing.T) {
+	body := `{"bindings":[{"type":"secret_text","name":"SECRET","text":"hunter2"},{"type":"plain_text","name":"PLAIN","text":"visible"}]}`
+
+	got := string(redactBody("application/json", []byte(body)))
+
+	if strings.Contains(got, "hunter2") {
+		t.Errorf("secret was not redacted: %s", got)
+	}
+	if !strings.Contains(got, "visible") || !strings.Contains(got, redacted) {
+		t.Errorf("unexpected redacted body: %s", got)
+	}
+}
+
+func TestRedactBody_Multipart(t *testing.T) {
+	buf := &bytes.Buffer{}
+	writer := multipart.NewWriter(buf)
+	metadata, _ := writer.CreateFormField("metadata")
+	metadata.Write([]byte(`{"main_module":"index.js","bindings":[{"type":"secret_text","name":"SECRET","text":"hunter2"}]}`))
+	module, _ := writer.CreatePart(textproto.MIMEHeader{
+		"Content-Disposition": {`form-data; name="index.js"; filename="index.js"`},
+		"Content-Type":        {"application/javascript+module"},
+	})
+	module.Write([]byte(`export default { fetch() { return new Response("hunter2") } }`))
+	writer.Close()
+
+	got := string(redactBody(writer.FormDataContentType(), buf.Bytes()))
+
+	if strings.Contains(got, "hunter2") {
+		t.Errorf("expected neither the secret nor the module content to be logged: %s", got)
+	}
+	if !strings.Contains(got, `"text":"[redacted]"`) || !strings.Contains(got, `part "index.js" (application/javascript+module, 61 bytes)`) {
+		t.Errorf("unexpected redacted body: %s", got)
+	}
+}
+
+func TestRedactBody_SensitiveKeys(t *testing.T) {
+	body := `{"name":"consumer","settings":{"client_secret":"hunter2","access_token":"hunter3","password":{"value":"hunter4"}},"token_count":3}`
+
+	got := string(redactBody("application/json", []byte(body)))
+
+	for _, secret := range []string{"hunter2", "hunter3", "hunter4"} {
+		if strings.Contains(got, secret) {
+			t.Errorf("secret %q was not redacted: %s", secret, got)
+		}
+	}
+	if !strings.Contains(got, `"name":"consumer"`) || !strings.Contains(got, `"token_count":3`) {
+		t.Errorf("unexpected redacted body: %s", got)
+	}
+}
+
+func TestRedactBody_Unchanged(t *testing.T) {
+	for _, tc := range []struct{ contentType, body string }{
+		{"application/json", `{"type":"plain_text","text":"visible"}`},
+		{"application/json", `not json`},
+		{"text/plain", `{"type":"secret_text","text":"hunter2"}`},
+		{"", `{"type":"secret_text","text":"hunter2"}`},
+	} {
+		if got := string(redactBody(tc.contentType, []byte(tc.body))); got != tc.body {
+			t.Errorf("redactBody(%q, %q) = %q, want it unchanged", tc.contentType, tc.body, got)
+		}
+	}
+}
+
+func TestRedact_Multipart(t *testing.T) {
+	buf := &bytes.Buffer{}
+	writer := multipart.NewWriter(buf)
+	metadata, _ := writer.CreateFormField("metadata")
+	metadata.Write([]byte(`{"main_module":"index.js","bindings":[{"type":"secret_text","name":"SECRET","text":"hunter2"}]}`))
+	module, _ := writer.CreatePart(textproto.MIMEHeader{
+		"Content-Disposition": {`form-data; name="index.js"; filename="index.js"`},
+		"Content-Type":        {"application/javascript+module"},
+	})
+	module.Write([]byte(`export default { fetch() { return new Response("hunter2") } }`))
+	writer.Close()
+
+	got := string(Redact(writer.FormDataContentType(), buf.Bytes()))
+
+	if strings.Count(got, "hunter2") != 1 {
+		t.Errorf("expected only the module content to keep the literal: %s", got)
+	}
+	if !strings.Contains(got, `"text":"[redacted]"`) || !strings.Contains(got, `new Response("hunter2")`) {
+		t.Errorf("unexpected redacted body: %s", got)
+	}
+}
@@ -0,0 +1,38 @@
+package logging
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// LogRetry logs that a failed request is retried after delay.
+func LogRetry(ctx context.Context, req *http.Request, resp *http.Response, err error, attempt int, delay time.Duration) {
+	fields := retryFields(req, resp, err, attempt)
+	fields["delay"] = delay.String()
+
+	tflog.Warn(ctx, "retrying Cloudflare API request", fields)
+}
+
+// LogRetriesExhausted logs that a failed request is not retried any more.
+func LogRetriesExhausted(ctx context.Context, req *http.Request, resp *http.Response, err error, attempt int) {
+	tflog.Warn(ctx, "giving up on Cloudflare API request", retryFields(req, resp, err, attempt))
+}
+
+func retryFields(req *http.Request, resp *http.Response, err error, attempt int) map[string]any {
+	fields := map[string]any{
+		"method":  req.Method,
+		"path":    req.URL.Path,
+		"attempt": attempt,
+	}
+	if resp != nil {
+		fields["status"] = resp.StatusCode
+	}
+	if err != nil {
+		fields["error"] = err.Error()
+	}
+
+	return fields
+}
@@ -0,0 +1,26 @@
+package logging
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// LogWait logs that a resource has not reached its target state yet and is
+// refreshed again after delay.
+func LogWait(ctx context.Context, description, state string, err error, attempt int, delay time.Duration) {
+	fields := map[string]any{
+		"waiting_for": description,
+		"attempt":     attempt,
+		"delay":       delay.String(),
+	}
+	if state != "" {
+		fields["state"] = state
+	}
+	if err != nil {
+		fields["error"] = err.Error()
+	}
+
+	tflog.Debug(ctx, "waiting for Cloudflare resource", fields)
+}
@@ -0,0 +1,124 @@
+package provider
+
+import (
+	"fmt"
+
+	"github.com/cloudflare/cloudflare-go/v3/option"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/consts"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/utils"
+)
+
+// credentials are the resolved authentication settings of the provider.
+type credentials struct {
+	apiToken          string
+	apiKey            string
+	email             string
+	apiUserServiceKey string
+}
+
+// resolveCredentials returns the credentials to authenticate with. Credentials
+// set in the provider configuration take precedence; the environment is only
+// consulted when none are configured, so that the two are never mixed. The
+// email is resolved on its own as it only complements an API key.
+func resolveCredentials(data CloudflareExtendedProviderModel) (creds credentials, diags diag.Diagnostics) {
+	for _, attr := range []struct {
+		value types.String
+		key   string
+	}{
+		{data.APIToken, consts.APITokenSchemaKey},
+		{data.APIKey, consts.APIKeySchemaKey},
+		{data.Email, consts.EmailSchemaKey},
+		{data.APIUserServiceKey, consts.APIUserServiceKeySchemaKey},
+	} {
+		if attr.value.IsUnknown() {
+			diags.AddAttributeError(
+				path.Root(attr.key),
+				"unknown provider credentials",
+				fmt.Sprintf("The provider cannot be configured because `%s` is unknown until apply. Set it to a known value, or use the environment instead.", attr.key),
+			)
+		}
+	}
+	if diags.HasError() {
+		return
+	}
+
+	creds = credentials{
+		apiToken:          data.APIToken.ValueString(),
+		apiKey:            data.APIKey.ValueString(),
+		apiUserServiceKey: data.APIUserServiceKey.ValueString(),
+	}
+	source := "provider configuration"
+	if creds == (credentials{}) {
+		creds = credentials{
+			apiToken:          utils.GetDefaultFromEnv(consts.APITokenEnvVarKey, ""),
+			apiKey:            utils.GetDefaultFromEnv(consts.APIKeyEnvVarKey, ""),
+			apiUserServiceKey: utils.GetDefaultFromEnv(consts.APIUserServiceKeyEnvVarKey, ""),
+		}
+		source = "environment"
+	}
+	creds.email = data.Email.ValueString()
+	if creds.email == "" {
+		creds.email = utils.GetDefaultFromEnv(consts.EmailEnvVarKey, "")
+	}
+
+	var set []string
+	for _, c := range []struct{ value, name string }{
+		{creds.apiToken, consts.APITokenEnvVarKey},
+		{creds.apiKey, consts.APIKeyEnvVarKey},
+		{creds.apiUserServiceKey, consts.APIUserServiceKeyEnvVarKey},
+	} {
+		if c.value != "" {
+			set = append(set, c.name)
+		}
+	}
+
+	switch {
+	case len(set) == 0:
+		diags.AddError(
+			"missing Cloudflare credentials",
+			fmt.Sprintf(
+				"No credentials were found in the provider configuration or the environment. Set one of `%s`, `%s` (with `%s`) or `%s`, or the %s, %s (with %s) or %s environment variable.",
+				consts.APITokenSchemaKey, consts.APIKeySchemaKey, consts.EmailSchemaKey, consts.APIUserServiceKeySchemaKey,
+				consts.APITokenEnvVarKey, consts.APIKeyEnvVarKey, consts.EmailEnvVarKey, consts.APIUserServiceKeyEnvVarKey,
+			),
+		)
+	case len(set) > 1 && source == "environment":
+		diags.AddError(
+			"conflicting Cloudflare credentials",
+			fmt.Sprintf("Only one of the %s, %s and %s environment variables may be set, found %v.", consts.APITokenEnvVarKey, consts.APIKeyEnvVarKey, consts.APIUserServiceKeyEnvVarKey, set),
+		)
+	case creds.apiKey != "" && creds.email == "":
+		diags.AddError(
+			"missing Cloudflare email",
+			fmt.Sprintf("An API key from the %s requires an email, set `%s` or the %s environment variable.", source, consts.EmailSchemaKey, consts.EmailEnvVarKey),
+		)
+	}
+
+	return
+}
+
+// options returns the request options authenticating with the credentials.
+// Headers for any other kind of credential are removed, as the client picks up
+// credentials from the environment on its own.
+func (c credentials) options() []option.RequestOption {
+	opts := []option.RequestOption{
+		option.WithHeaderDel("authorization"),
+		option.WithHeaderDel("x-auth-key"),
+		option.WithHeaderDel("x-auth-email"),
+		option.WithHeaderDel("x-auth-user-service-key"),
+	}
+
+	switch {
+	case c.apiToken != "":
+		opts = append(opts, option.WithAPIToken(c.apiToken))
+	case c.apiKey != "":
+		opts = append(opts, option.WithAPIKey(c.apiKey), option.WithAPIEmail(c.email))
+	case c.apiUserServiceKey != "":
+		opts = append(opts, option.WithUserServiceKey(c.apiUserServiceKey))
+	}
+
+	return opts
+}
@@ -0,0 +1,89 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/consts"
+)
+
+func TestResolveCredentials(t *testing.T) {
+	token := strings.Repeat("t", 40)
+	key := strings.Repeat("a", 37)
+
+	for name, tc := range map[string]struct {
+		data    CloudflareExtendedProviderModel
+		env     map[string]string
+		want    credentials
+		wantErr string
+	}{
+		"config token": {
+			data: CloudflareExtendedProviderModel{APIToken: types.StringValue(token)},
+			want: credentials{apiToken: token},
+		},
+		"config wins over environment": {
+			data: CloudflareExtendedProviderModel{APIToken: types.StringValue(token)},
+			env:  map[string]string{consts.APIKeyEnvVarKey: key, consts.EmailEnvVarKey: "user@example.com"},
+			want: credentials{apiToken: token, email: "user@example.com"},
+		},
+		"environment token": {
+			env:  map[string]string{consts.APITokenEnvVarKey: token},
+			want: credentials{apiToken: token},
+		},
+		"environment key with config email": {
+			data: CloudflareExtendedProviderModel{Email: types.StringValue("user@example.com")},
+			env:  map[string]string{consts.APIKeyEnvVarKey: key},
+			want: credentials{apiKey: key, email: "user@example.com"},
+		},
+		"config key with environment email": {
+			data: CloudflareExtendedProviderModel{APIKey: types.StringValue(key)},
+			env:  map[string]string{consts.EmailEnvVarKey: "user@example.com"},
+			want: credentials{apiKey: key, email: "user@example.com"},
+		},
+		"config key without email": {
+			data:    CloudflareExtendedProviderModel{APIKey: types.StringValue(key)},
+			wantErr: "missing Cloudflare email",
+		},
+		"environment user service key": {
+			env:  map[string]string{consts.APIUserServiceKeyEnvVarKey: "v1.0-service-key"},
+			want: credentials{apiUserServiceKey: "v1.0-service-key"},
+		},
+		"conflicting environment": {
+			env:     map[string]string{consts.APITokenEnvVarKey: token, consts.APIKeyEnvVarKey: key},
+			wantErr: "conflicting Cloudflare credentials",
+		},
+		"key without email": {
+			env:     map[string]string{consts.APIKeyEnvVarKey: key},
+			wantErr: "missing Cloudflare email",
+		},
+		"no credentials": {
+			wantErr: "missing Cloudflare credentials",
+		},
+		"unknown token": {
+			data:    CloudflareExtendedProviderModel{APIToken: types.StringUnknown()},
+			wantErr: "unknown provider credentials",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			for _, k := range []string{consts.APITokenEnvVarKey, consts.APIKeyEnvVarKey, consts.EmailEnvVarKey, consts.APIUserServiceKeyEnvVarKey} {
+				t.Setenv(k, tc.env[k])
+			}
+
+			got, diags := resolveCredentials(tc.data)
+
+			if tc.wantErr != "" {
+				if !diags.HasError() || diags.Errors()[0].Summary() != tc.wantErr {
+					t.Fatalf("expected %q error, got %v", tc.wantErr, diags)
+				}
+				return
+			}
+			if diags.HasError() {
+				t.Fatalf("unexpected error: %v", diags)
+			}
+			if got != tc.want {
+				t.Errorf("got %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
@@ -3,10 +3,14 @@ package provider
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"regexp"
 
 	"github.com/cloudflare/cloudflare-go/v3"
 	"github.com/cloudflare/cloudflare-go/v3/option"
+	"github.com/hashicorp/terraform-plugin-framework-validators/float64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/providervalidator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/function"
@@ -17,7 +21,13 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/consts"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/providerdata"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/ratelimit"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/retry"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/services/queue"
 	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/services/queue_consumer"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/services/queue_messages"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/services/r2_bucket_event_notifications"
 	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/services/r2_event_notification"
 	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/services/vectorize"
 	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/services/workers_script"
@@ -32,16 +42,26 @@ type CloudflareExtendedProvider struct {
 	// provider is built and ran locally, and "test" when running acceptance
 	// testing.
 	version string
+
+	// httpClient, when set, sends the requests of the API client, e.g. to
+	// record them in acceptance tests.
+	httpClient *http.Client
 }
 
 // CloudflareExtendedProviderModel describes the provider data model.
 type CloudflareExtendedProviderModel struct {
-	APIKey                  types.String `tfsdk:"api_key" json:"api_key"`
-	APIUserServiceKey       types.String `tfsdk:"api_user_service_key" json:"api_user_service_key"`
-	Email                   types.String `tfsdk:"email" json:"email"`
-	APIToken                types.String `tfsdk:"api_token" json:"api_token"`
-	UserAgentOperatorSuffix types.String `tfsdk:"user_agent_operator_suffix" json:"user_agent_operator_suffix"`
-	BaseURL                 types.String `tfsdk:"base_url" json:"base_url"`
+	APIKey                  types.String  `tfsdk:"api_key" json:"api_key"`
+	APIUserServiceKey       types.String  `tfsdk:"api_user_service_key" json:"api_user_service_key"`
+	Email                   types.String  `tfsdk:"email" json:"email"`
+	APIToken                types.String  `tfsdk:"api_token" json:"api_token"`
+	UserAgentOperatorSuffix types.String  `tfsdk:"user_agent_operator_suffix" json:"user_agent_operator_suffix"`
+	BaseURL                 types.String  `tfsdk:"base_url" json:"base_url"`
+	AccountID               types.String  `tfsdk:"account_id" json:"account_id"`
+	MaxRetries              types.Int64   `tfsdk:"max_retries" json:"max_retries"`
+	MinBackoff              types.Int64   `tfsdk:"min_backoff" json:"min_backoff"`
+	MaxBackoff              types.Int64   `tfsdk:"max_backoff" json:"max_backoff"`
+	MaxConcurrentRequests   types.Int64   `tfsdk:"max_concurrent_requests" json:"max_concurrent_requests"`
+	RequestsPerSecond       types.Float64 `tfsdk:"requests_per_second" json:"requests_per_second"`
 }
 
 func (p *CloudflareExtendedProvider) Metadata(ctx context.Context, req provider.MetadataRequest, resp *provider.MetadataResponse) {
@@ -51,6 +71,7 @@ func (p *CloudflareExtendedProvider) Metadata(ctx context.Context, req provider.
 
 func (p *CloudflareExtendedProvider) Schema(ctx context.Context, req provider.SchemaRequest, resp *provider.SchemaResponse) {
 	resp.Schema = schema.Schema{
+		MarkdownDescription: fmt.Sprintf("API requests and responses are logged at the `DEBUG` level, with credentials redacted. Bodies longer than the `%s` environment variable, in bytes, are truncated. It defaults to `16384`, and `0` disables truncation.", consts.LogMaxBodySizeEnvVarKey),
 		Attributes: map[string]schema.Attribute{
 			consts.EmailSchemaKey: schema.StringAttribute{
 				Optional:            true,
@@ -66,9 +87,6 @@ func (p *CloudflareExtendedProvider) Schema(ctx context.Context, req provider.Sc
 						regexp.MustCompile(`[0-9a-f]{37}`),
 						"API key must be 37 characters long and only contain characters 0-9 and a-f (all lowercased)",
 					),
-					stringvalidator.AlsoRequires(path.Expressions{
-						path.MatchRoot(consts.EmailSchemaKey),
-					}...),
 				},
 			},
 
@@ -93,9 +111,44 @@ func (p *CloudflareExtendedProvider) Schema(ctx context.Context, req provider.Sc
 				MarkdownDescription: fmt.Sprintf("A value to append to the HTTP User Agent for all API calls. This value is not something most users need to modify however, if you are using a non-standard provider or operator configuration, this is recommended to assist in uniquely identifying your traffic. **Setting this value will remove the Terraform version from the HTTP User Agent string and may have unintended consequences**. Alternatively, can be configured using the `%s` environment variable.", consts.UserAgentOperatorSuffixEnvVarKey),
 			},
 
+			consts.AccountIDSchemaKey: schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: fmt.Sprintf("The account identifier resources target when their own `account_id` is omitted. Changing it replaces those resources. Alternatively, can be configured using the `%s` environment variable.", consts.AccountIDEnvVarKey),
+			},
+
 			consts.BaseURLSchemaKey: schema.StringAttribute{
 				Optional:            true,
-				MarkdownDescription: fmt.Sprintf("Value to override the default HTTP client base URL. Alternatively, can be configured using the `%s` environment variable.", consts.BaseURLSchemaKey),
+				MarkdownDescription: fmt.Sprintf("Value to override the default HTTP client base URL. Alternatively, can be configured using the `%s` environment variable.", consts.BaseURLEnvVarKey),
+			},
+
+			consts.MaxRetriesSchemaKey: schema.Int64Attribute{
+				Optional:            true,
+				MarkdownDescription: fmt.Sprintf("Maximum number of times an API request is retried after it is rate limited, times out or fails with a server error. Defaults to `%d`. Alternatively, can be configured using the `%s` environment variable.", retry.DefaultMaxRetries, consts.MaxRetriesEnvVarKey),
+				Validators:          []validator.Int64{int64validator.AtLeast(0)},
+			},
+
+			consts.MinBackoffSchemaKey: schema.Int64Attribute{
+				Optional:            true,
+				MarkdownDescription: fmt.Sprintf("Minimum number of seconds to wait before retrying an API request, doubled on every retry. Defaults to `%d`. Alternatively, can be configured using the `%s` environment variable.", int(retry.DefaultMinBackoff.Seconds()), consts.MinBackoffEnvVarKey),
+				Validators:          []validator.Int64{int64validator.AtLeast(1)},
+			},
+
+			consts.MaxBackoffSchemaKey: schema.Int64Attribute{
+				Optional:            true,
+				MarkdownDescription: fmt.Sprintf("Maximum number of seconds to wait between retries of an API request. A `Retry-After` sent by the API is honored even when it is longer. Defaults to `%d`. Alternatively, can be configured using the `%s` environment variable.", int(retry.DefaultMaxBackoff.Seconds()), consts.MaxBackoffEnvVarKey),
+				Validators:          []validator.Int64{int64validator.AtLeast(1)},
+			},
+
+			consts.MaxConcurrentRequestsSchemaKey: schema.Int64Attribute{
+				Optional:            true,
+				MarkdownDescription: fmt.Sprintf("Maximum number of API requests in flight at once, shared by all resources. `0` disables the limit. Defaults to `%d`. Alternatively, can be configured using the `%s` environment variable.", ratelimit.DefaultMaxConcurrentRequests, consts.MaxConcurrentRequestsEnvVarKey),
+				Validators:          []validator.Int64{int64validator.AtLeast(0)},
+			},
+
+			consts.RequestsPerSecondSchemaKey: schema.Float64Attribute{
+				Optional:            true,
+				MarkdownDescription: fmt.Sprintf("Maximum number of API requests sent per second, shared by all resources. Unlimited when unset or `0`. Alternatively, can be configured using the `%s` environment variable.", consts.RequestsPerSecondEnvVarKey),
+				Validators:          []validator.Float64{float64validator.AtLeast(0)},
 			},
 		},
 	}
@@ -106,21 +159,39 @@ func (p *CloudflareExtendedProvider) Configure(ctx context.Context, req provider
 	opts := []option.RequestOption{}
 
 	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
-	if !data.BaseURL.IsNull() {
-		opts = append(opts, option.WithBaseURL(data.BaseURL.ValueString()))
+	creds, diags := resolveCredentials(data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
-	if !data.APIToken.IsNull() {
-		opts = append(opts, option.WithAPIToken(data.APIToken.ValueString()))
+	opts = append(opts, creds.options()...)
+
+	policy, diags := resolveRetryPolicy(data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
-	if !data.APIKey.IsNull() {
-		opts = append(opts, option.WithAPIKey(data.APIKey.ValueString()))
+	// the policy replaces the retries built into the client
+	opts = append(opts, option.WithMaxRetries(0), option.WithMiddleware(policy.Middleware()))
+
+	limiter, diags := resolveLimiter(data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
-	if !data.Email.IsNull() {
-		opts = append(opts, option.WithAPIEmail(data.Email.ValueString()))
+	// every attempt of a request waits for the limiter, so it goes after the retries
+	opts = append(opts, option.WithMiddleware(limiter.Middleware()))
+
+	baseURL := data.BaseURL.ValueString()
+	if data.BaseURL.IsNull() {
+		baseURL = utils.GetDefaultFromEnv(consts.BaseURLEnvVarKey, "")
 	}
-	if !data.APIUserServiceKey.IsNull() {
-		opts = append(opts, option.WithUserServiceKey(data.APIUserServiceKey.ValueString()))
+	if baseURL != "" {
+		opts = append(opts, option.WithBaseURL(baseURL))
 	}
 
 	pluginVersion := utils.FindGoModuleVersion("github.com/hashicorp/terraform-plugin-framework")
@@ -135,8 +206,11 @@ func (p *CloudflareExtendedProvider) Configure(ctx context.Context, req provider
 		PluginVersion:   pluginVersion,
 	}
 
-	if !data.UserAgentOperatorSuffix.IsNull() {
-		operatorSuffix := data.UserAgentOperatorSuffix.String()
+	operatorSuffix := data.UserAgentOperatorSuffix.ValueString()
+	if data.UserAgentOperatorSuffix.IsNull() {
+		operatorSuffix = utils.GetDefaultFromEnv(consts.UserAgentOperatorSuffixEnvVarKey, "")
+	}
+	if operatorSuffix != "" {
 		userAgentParams.OperatorSuffix = &operatorSuffix
 	} else {
 		userAgentParams.TerraformVersion = &req.TerraformVersion
@@ -144,29 +218,56 @@ func (p *CloudflareExtendedProvider) Configure(ctx context.Context, req provider
 
 	opts = append(opts, option.WithHeader("user-agent", userAgentParams.String()))
 
+	if p.httpClient != nil {
+		opts = append(opts, option.WithHTTPClient(p.httpClient))
+	}
+
 	client := cloudflare.NewClient(
 		opts...,
 	)
 
-	resp.DataSourceData = client
-	resp.ResourceData = client
+	accountID := data.AccountID.ValueString()
+	if data.AccountID.IsNull() {
+		accountID = utils.GetDefaultFromEnv(consts.AccountIDEnvVarKey, "")
+	}
+
+	providerData := &providerdata.Data{
+		Client:    client,
+		AccountID: accountID,
+	}
+	resp.DataSourceData = providerData
+	resp.ResourceData = providerData
 }
 
 func (p *CloudflareExtendedProvider) ConfigValidators(_ context.Context) []provider.ConfigValidator {
-	return []provider.ConfigValidator{}
+	return []provider.ConfigValidator{
+		providervalidator.Conflicting(
+			path.MatchRoot(consts.APIKeySchemaKey),
+			path.MatchRoot(consts.APITokenSchemaKey),
+			path.MatchRoot(consts.APIUserServiceKeySchemaKey),
+		),
+		providervalidator.Conflicting(
+			path.MatchRoot(consts.APITokenSchemaKey),
+			path.MatchRoot(consts.EmailSchemaKey),
+		),
+	}
 }
 
 func (p *CloudflareExtendedProvider) Resources(ctx context.Context) []func() resource.Resource {
 	return []func() resource.Resource{
 		vectorize.NewResource,
 		workers_script.NewResource,
+		queue.NewResource,
 		queue_consumer.NewResource,
 		r2_event_notification.NewResource,
+		r2_bucket_event_notifications.NewResource,
 	}
 }
 
 func (p *CloudflareExtendedProvider) DataSources(ctx context.Context) []func() datasource.DataSource {
-	return []func() datasource.DataSource{}
+	return []func() datasource.DataSource{
+		queue_messages.NewDataSource,
+	}
 }
 
 func (p *CloudflareExtendedProvider) Functions(ctx context.Context) []func() function.Function {
@@ -180,3 +281,14 @@ func New(version string) func() provider.Provider {
 		}
 	}
 }
+
+// NewWithHTTPClient returns a provider whose API client sends its requests
+// with httpClient.
+func NewWithHTTPClient(version string, httpClient *http.Client) func() provider.Provider {
+	return func() provider.Provider {
+		return &CloudflareExtendedProvider{
+			version:    version,
+			httpClient: httpClient,
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package provider
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/consts"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/ratelimit"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/utils"
+)
+
+// resolveLimiter returns the limiter shared by every request of the API
+// client, configured from the provider configuration, then the environment.
+func resolveLimiter(data CloudflareExtendedProviderModel) (*ratelimit.Limiter, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	maxConcurrent, d := resolveInt64(data.MaxConcurrentRequests, consts.MaxConcurrentRequestsSchemaKey, consts.MaxConcurrentRequestsEnvVarKey, ratelimit.DefaultMaxConcurrentRequests, 0)
+	diags.Append(d...)
+
+	if data.RequestsPerSecond.IsUnknown() {
+		diags.AddAttributeError(
+			path.Root(consts.RequestsPerSecondSchemaKey),
+			"unknown provider configuration",
+			fmt.Sprintf("The provider cannot be configured because `%s` is unknown until apply. Set it to a known value, or use the environment instead.", consts.RequestsPerSecondSchemaKey),
+		)
+	}
+	requestsPerSecond := data.RequestsPerSecond.ValueFloat64()
+	if env := utils.GetDefaultFromEnv(consts.RequestsPerSecondEnvVarKey, ""); data.RequestsPerSecond.IsNull() && env != "" {
+		var err error
+		requestsPerSecond, err = strconv.ParseFloat(env, 64)
+		if err != nil || requestsPerSecond < 0 {
+			diags.AddError(
+				"invalid provider configuration",
+				fmt.Sprintf("The %s environment variable must be a number of at least 0, got %q.", consts.RequestsPerSecondEnvVarKey, env),
+			)
+		}
+	}
+
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	return ratelimit.New(int(maxConcurrent), requestsPerSecond), diags
+}
@@ -0,0 +1,54 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/consts"
+)
+
+func TestResolveLimiter(t *testing.T) {
+	for name, tc := range map[string]struct {
+		data    CloudflareExtendedProviderModel
+		env     map[string]string
+		wantErr string
+	}{
+		"defaults": {},
+		"config": {
+			data: CloudflareExtendedProviderModel{MaxConcurrentRequests: types.Int64Value(0), RequestsPerSecond: types.Float64Value(4)},
+		},
+		"environment": {
+			env: map[string]string{consts.MaxConcurrentRequestsEnvVarKey: "2", consts.RequestsPerSecondEnvVarKey: "0.5"},
+		},
+		"invalid concurrency environment": {
+			env:     map[string]string{consts.MaxConcurrentRequestsEnvVarKey: "-1"},
+			wantErr: "invalid provider configuration",
+		},
+		"invalid rate environment": {
+			env:     map[string]string{consts.RequestsPerSecondEnvVarKey: "fast"},
+			wantErr: "invalid provider configuration",
+		},
+		"unknown rate": {
+			data:    CloudflareExtendedProviderModel{RequestsPerSecond: types.Float64Unknown()},
+			wantErr: "unknown provider configuration",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			for _, k := range []string{consts.MaxConcurrentRequestsEnvVarKey, consts.RequestsPerSecondEnvVarKey} {
+				t.Setenv(k, tc.env[k])
+			}
+
+			limiter, diags := resolveLimiter(tc.data)
+
+			if tc.wantErr != "" {
+				if !diags.HasError() || diags.Errors()[0].Summary() != tc.wantErr {
+					t.Fatalf("expected %q error, got %v", tc.wantErr, diags)
+				}
+				return
+			}
+			if diags.HasError() || limiter == nil {
+				t.Fatalf("unexpected error: %v", diags)
+			}
+		})
+	}
+}
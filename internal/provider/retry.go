@@ -0,0 +1,78 @@
+package provider
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/consts"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/retry"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/utils"
+)
+
+// resolveRetryPolicy returns the retry policy of the API client. Each setting
+// comes from the provider configuration, then the environment, then the
+// default of the retry package.
+func resolveRetryPolicy(data CloudflareExtendedProviderModel) (policy retry.Policy, diags diag.Diagnostics) {
+	policy = retry.DefaultPolicy()
+
+	maxRetries, d := resolveInt64(data.MaxRetries, consts.MaxRetriesSchemaKey, consts.MaxRetriesEnvVarKey, int64(policy.MaxRetries), 0)
+	diags.Append(d...)
+	minBackoff, d := resolveInt64(data.MinBackoff, consts.MinBackoffSchemaKey, consts.MinBackoffEnvVarKey, int64(policy.MinBackoff/time.Second), 1)
+	diags.Append(d...)
+	maxBackoff, d := resolveInt64(data.MaxBackoff, consts.MaxBackoffSchemaKey, consts.MaxBackoffEnvVarKey, int64(policy.MaxBackoff/time.Second), 1)
+	diags.Append(d...)
+	if diags.HasError() {
+		return
+	}
+
+	if minBackoff > maxBackoff {
+		diags.AddAttributeError(
+			path.Root(consts.MinBackoffSchemaKey),
+			"invalid retry backoff",
+			fmt.Sprintf("`%s` (%d) must not be greater than `%s` (%d).", consts.MinBackoffSchemaKey, minBackoff, consts.MaxBackoffSchemaKey, maxBackoff),
+		)
+		return
+	}
+
+	policy.MaxRetries = int(maxRetries)
+	policy.MinBackoff = time.Duration(minBackoff) * time.Second
+	policy.MaxBackoff = time.Duration(maxBackoff) * time.Second
+
+	return
+}
+
+func resolveInt64(value types.Int64, key, envKey string, fallback, atLeast int64) (int64, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	if value.IsUnknown() {
+		diags.AddAttributeError(
+			path.Root(key),
+			"unknown provider configuration",
+			fmt.Sprintf("The provider cannot be configured because `%s` is unknown until apply. Set it to a known value, or use the environment instead.", key),
+		)
+		return 0, diags
+	}
+	if !value.IsNull() {
+		return value.ValueInt64(), diags
+	}
+
+	env := utils.GetDefaultFromEnv(envKey, "")
+	if env == "" {
+		return fallback, diags
+	}
+
+	n, err := strconv.ParseInt(env, 10, 64)
+	if err != nil || n < atLeast {
+		diags.AddError(
+			"invalid provider configuration",
+			fmt.Sprintf("The %s environment variable must be an integer of at least %d, got %q.", envKey, atLeast, env),
+		)
+		return 0, diags
+	}
+
+	return n, diags
+}
@@ -0,0 +1,65 @@
+package provider
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/consts"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/retry"
+)
+
+func TestResolveRetryPolicy(t *testing.T) {
+	for name, tc := range map[string]struct {
+		data    CloudflareExtendedProviderModel
+		env     map[string]string
+		want    retry.Policy
+		wantErr string
+	}{
+		"defaults": {
+			want: retry.DefaultPolicy(),
+		},
+		"config": {
+			data: CloudflareExtendedProviderModel{MaxRetries: types.Int64Value(0), MinBackoff: types.Int64Value(2), MaxBackoff: types.Int64Value(10)},
+			want: retry.Policy{MaxRetries: 0, MinBackoff: 2 * time.Second, MaxBackoff: 10 * time.Second, MaxElapsed: retry.DefaultMaxElapsed},
+		},
+		"config wins over environment": {
+			data: CloudflareExtendedProviderModel{MaxRetries: types.Int64Value(1)},
+			env:  map[string]string{consts.MaxRetriesEnvVarKey: "7", consts.MaxBackoffEnvVarKey: "60"},
+			want: retry.Policy{MaxRetries: 1, MinBackoff: retry.DefaultMinBackoff, MaxBackoff: time.Minute, MaxElapsed: retry.DefaultMaxElapsed},
+		},
+		"invalid environment": {
+			env:     map[string]string{consts.MinBackoffEnvVarKey: "0"},
+			wantErr: "invalid provider configuration",
+		},
+		"min greater than max": {
+			data:    CloudflareExtendedProviderModel{MinBackoff: types.Int64Value(60), MaxBackoff: types.Int64Value(30)},
+			wantErr: "invalid retry backoff",
+		},
+		"unknown max retries": {
+			data:    CloudflareExtendedProviderModel{MaxRetries: types.Int64Unknown()},
+			wantErr: "unknown provider configuration",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			for _, k := range []string{consts.MaxRetriesEnvVarKey, consts.MinBackoffEnvVarKey, consts.MaxBackoffEnvVarKey} {
+				t.Setenv(k, tc.env[k])
+			}
+
+			got, diags := resolveRetryPolicy(tc.data)
+
+			if tc.wantErr != "" {
+				if !diags.HasError() || diags.Errors()[0].Summary() != tc.wantErr {
+					t.Fatalf("expected %q error, got %v", tc.wantErr, diags)
+				}
+				return
+			}
+			if diags.HasError() {
+				t.Fatalf("unexpected error: %v", diags)
+			}
+			if got != tc.want {
+				t.Errorf("got %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+// Package providerdata holds the data the provider hands to its resources and
+// data sources once it is configured.
+package providerdata
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cloudflare/cloudflare-go/v3"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/consts"
+)
+
+// Data is passed as the ProviderData of resource and data source Configure
+// requests.
+type Data struct {
+	// Client is the API client configured with the provider credentials.
+	Client *cloudflare.Client
+
+	// AccountID is the account resources fall back to when their own
+	// `account_id` is omitted. Empty when the provider has none.
+	AccountID string
+}
+
+// ModifyPlanAccountID plans the provider account ID for resources whose
+// `account_id` is omitted from the configuration. As the attribute is computed
+// when omitted, a change of the provider account ID only shows up here, so the
+// replacement it requires is added too.
+func ModifyPlanAccountID(ctx context.Context, defaultAccountID string, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	accountIDPath := path.Root(consts.AccountIDSchemaKey)
+
+	var accountID types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, accountIDPath, &accountID)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if accountID.IsNull() {
+		if defaultAccountID == "" {
+			resp.Diagnostics.AddAttributeError(
+				accountIDPath,
+				"missing account_id",
+				fmt.Sprintf("Set `%s` on the resource, or on the provider or with the %s environment variable.", consts.AccountIDSchemaKey, consts.AccountIDEnvVarKey),
+			)
+			return
+		}
+
+		accountID = types.StringValue(defaultAccountID)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, accountIDPath, accountID)...)
+	}
+
+	if req.State.Raw.IsNull() || accountID.IsUnknown() {
+		return
+	}
+
+	var prior types.String
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, accountIDPath, &prior)...)
+	if !prior.Equal(accountID) {
+		resp.RequiresReplace = append(resp.RequiresReplace, accountIDPath)
+	}
+}
@@ -0,0 +1,207 @@
+package r2notifications
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/cloudflare/cloudflare-go/v3"
+	"github.com/cloudflare/cloudflare-go/v3/event_notifications"
+	"github.com/cloudflare/cloudflare-go/v3/option"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/logging"
+)
+
+// ErrQueueNotConfigured is returned by Bucket.Queue when the bucket has no
+// rules for the queue.
+var ErrQueueNotConfigured = errors.New("could not find queue associated with event notification")
+
+type deleteRequestBody struct {
+	RuleIds []string `json:"ruleIds"`
+}
+
+// Bucket is the event notification configuration of an R2 bucket.
+type Bucket struct {
+	Client    *cloudflare.Client
+	AccountID string
+	Name      string
+}
+
+// Configuration reads the notification configuration of every queue of the
+// bucket.
+func (b Bucket) Configuration(ctx context.Context) (*event_notifications.R2ConfigurationGetResponse, error) {
+	return b.Client.EventNotifications.R2.Configuration.Get(
+		ctx,
+		b.Name,
+		event_notifications.R2ConfigurationGetParams{
+			AccountID: cloudflare.F(b.AccountID),
+		},
+		option.WithMiddleware(logging.Middleware(ctx)),
+	)
+}
+
+// Queue reads the notification configuration of one queue of the bucket, with
+// its queue ID normalized.
+func (b Bucket) Queue(ctx context.Context, queueID string) (*event_notifications.R2ConfigurationGetResponseQueue, error) {
+	config, err := b.Configuration(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, q := range config.Queues {
+		q.QueueID = NormalizeQueueID(q.QueueID)
+		if q.QueueID == NormalizeQueueID(queueID) {
+			return &q, nil
+		}
+	}
+
+	return nil, ErrQueueNotConfigured
+}
+
+// AddRules adds rules to a queue of the bucket.
+func (b Bucket) AddRules(ctx context.Context, queueID string, rules []RuleModel) error {
+	_, err := b.Client.EventNotifications.R2.Configuration.Queues.Update(
+		ctx,
+		b.Name,
+		queueID,
+		event_notifications.R2ConfigurationQueueUpdateParams{
+			AccountID: cloudflare.F(b.AccountID),
+			Rules:     cloudflare.F(toUpdateParams(rules)),
+		},
+		option.WithMiddleware(logging.Middleware(ctx)),
+	)
+	return err
+}
+
+// DeleteRules deletes rules of a queue of the bucket by ID, or the whole
+// configuration of the queue when no rule ID is given.
+func (b Bucket) DeleteRules(ctx context.Context, queueID string, ruleIDs []string) error {
+	opts := []option.RequestOption{option.WithMiddleware(logging.Middleware(ctx))}
+	if len(ruleIDs) > 0 {
+		jsonData, err := json.Marshal(deleteRequestBody{RuleIds: ruleIDs})
+		if err != nil {
+			return err
+		}
+		opts = append(opts, option.WithRequestBody("application/json", jsonData))
+	}
+
+	_, err := b.Client.EventNotifications.R2.Configuration.Queues.Delete(
+		ctx,
+		b.Name,
+		queueID,
+		event_notifications.R2ConfigurationQueueDeleteParams{
+			AccountID: cloudflare.F(b.AccountID),
+		},
+		opts...,
+	)
+	return err
+}
+
+// Apply adds and deletes the changed rules of every queue, adding rules before
+// the ones they supersede are deleted whenever they don't overlap. If a step
+// fails, the steps done so far are reverted, so the bucket keeps its current
+// rules.
+func (b Bucket) Apply(ctx context.Context, changes []QueueChanges) (diags diag.Diagnostics) {
+	for _, c := range changes {
+		if len(c.Added) == 0 {
+			continue
+		}
+		if err := b.AddRules(ctx, c.QueueID, c.Added); err != nil {
+			diags.AddError("failed to add r2 event notification rules", fmt.Sprintf("Queue %s: %s", c.QueueID, err))
+			diags.Append(b.rollback(ctx, changes, nil)...)
+			return
+		}
+	}
+
+	var deleted []QueueChanges
+	for _, c := range changes {
+		if len(c.Removed) == 0 {
+			continue
+		}
+		var ruleIDs []string
+		if !c.RemoveAll {
+			for _, rule := range c.Removed {
+				ruleIDs = append(ruleIDs, rule.RuleID.ValueString())
+			}
+		}
+		if err := b.DeleteRules(ctx, c.QueueID, ruleIDs); err != nil {
+			diags.AddError("error deleting rules", fmt.Sprintf("Queue %s: %s", c.QueueID, err))
+			diags.Append(b.rollback(ctx, changes, deleted)...)
+			return
+		}
+		deleted = append(deleted, c)
+	}
+
+	for _, c := range changes {
+		if len(c.Replacing) == 0 {
+			continue
+		}
+		if err := b.AddRules(ctx, c.QueueID, c.Replacing); err != nil {
+			diags.AddError("failed to add r2 event notification rules", fmt.Sprintf("Queue %s: %s", c.QueueID, err))
+			diags.Append(b.rollback(ctx, changes, deleted)...)
+			return
+		}
+	}
+
+	return
+}
+
+// rollback deletes the rules added by a failed Apply and restores the rules
+// it deleted from the deleted queues, reporting the outcome.
+func (b Bucket) rollback(ctx context.Context, changes []QueueChanges, deleted []QueueChanges) (diags diag.Diagnostics) {
+	config, err := b.Configuration(ctx)
+	if err != nil {
+		diags.AddError("failed to roll back r2 event notification rules", fmt.Sprintf("The added rules could not be read: %s", err))
+		return
+	}
+
+	for _, c := range changes {
+		added := append(slices.Clone(c.Added), c.Replacing...)
+		if len(added) == 0 {
+			continue
+		}
+
+		var ruleIDs []string
+		for _, queue := range config.Queues {
+			if NormalizeQueueID(queue.QueueID) != NormalizeQueueID(c.QueueID) {
+				continue
+			}
+			for _, rule := range queue.Rules {
+				existed := slices.ContainsFunc(c.Current, func(existing RuleModel) bool {
+					return existing.RuleID.ValueString() == rule.RuleID
+				})
+				wasAdded := slices.ContainsFunc(added, func(a RuleModel) bool {
+					return Equal(a, FromAPIRule(rule))
+				})
+				if !existed && wasAdded {
+					ruleIDs = append(ruleIDs, rule.RuleID)
+				}
+			}
+		}
+
+		if len(ruleIDs) > 0 {
+			if err := b.DeleteRules(ctx, c.QueueID, ruleIDs); err != nil {
+				diags.AddError("failed to roll back r2 event notification rules", fmt.Sprintf("The added rules of queue %s could not be deleted: %s", c.QueueID, err))
+				return
+			}
+		}
+	}
+
+	for _, c := range deleted {
+		if err := b.AddRules(ctx, c.QueueID, c.Removed); err != nil {
+			diags.AddError(
+				"failed to roll back r2 event notification rules",
+				fmt.Sprintf(
+					"The deleted rules could not be restored, the bucket no longer has these rules:\n%s",
+					DescribeChanges([]QueueChanges{{QueueID: c.QueueID, Removed: c.Removed}}),
+				),
+			)
+			return
+		}
+	}
+
+	diags.AddWarning("rolled back r2 event notification rules", "The update failed, so the rules of the bucket were restored to their previous state.")
+	return
+}
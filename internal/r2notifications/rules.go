@@ -0,0 +1,258 @@
+// Package r2notifications manages the event notification rules of R2
+// buckets, for the resources that configure them.
+package r2notifications
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/cloudflare/cloudflare-go/v3"
+	"github.com/cloudflare/cloudflare-go/v3/event_notifications"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/customfield"
+)
+
+// Actions are the R2 object actions a rule can be notified of.
+var Actions = []string{"PutObject", "CopyObject", "DeleteObject", "CompleteMultipartUpload", "LifecycleDeletion"}
+
+type RuleModel struct {
+	Actions   customfield.Set[types.String] `tfsdk:"actions" path:"actions,required"`
+	RuleID    types.String                  `tfsdk:"rule_id" path:"rule_id,computed"`
+	Prefix    types.String                  `tfsdk:"prefix" path:"prefix,computed_optional"`
+	Suffix    types.String                  `tfsdk:"suffix" path:"suffix,computed_optional"`
+	CreatedAt types.String                  `tfsdk:"created_at" path:"created_at,computed"`
+}
+
+// NormalizeQueueID removes the hyphens some APIs format queue IDs with.
+func NormalizeQueueID(id string) string {
+	return strings.ReplaceAll(id, "-", "")
+}
+
+// Known reports whether the content of a rule is known.
+func Known(rule RuleModel) bool {
+	if rule.Prefix.IsUnknown() || rule.Suffix.IsUnknown() || rule.Actions.IsUnknown() {
+		return false
+	}
+	return !slices.ContainsFunc(rule.Actions.Elements(), func(action attr.Value) bool {
+		return action.IsUnknown()
+	})
+}
+
+// Equal reports whether two rules have the same content, regardless of their
+// IDs.
+func Equal(a, b RuleModel) bool {
+	return a.Prefix.ValueString() == b.Prefix.ValueString() &&
+		a.Suffix.ValueString() == b.Suffix.ValueString() &&
+		a.Actions.Equal(b.Actions)
+}
+
+// Overlap reports whether an object event could match both rules: they share
+// an action, and their prefixes and suffixes match overlapping keys. The API
+// rejects overlapping rules on a bucket, across all of its queues.
+func Overlap(a, b RuleModel) bool {
+	sharesAction := slices.ContainsFunc(a.Actions.Elements(), func(action attr.Value) bool {
+		return slices.ContainsFunc(b.Actions.Elements(), action.Equal)
+	})
+
+	prefixA, prefixB := a.Prefix.ValueString(), b.Prefix.ValueString()
+	suffixA, suffixB := a.Suffix.ValueString(), b.Suffix.ValueString()
+
+	return sharesAction &&
+		(strings.HasPrefix(prefixA, prefixB) || strings.HasPrefix(prefixB, prefixA)) &&
+		(strings.HasSuffix(suffixA, suffixB) || strings.HasSuffix(suffixB, suffixA))
+}
+
+// Describe returns a description of the content of a rule for diagnostics.
+func Describe(rule RuleModel) string {
+	actions := make([]string, 0, len(rule.Actions.Elements()))
+	for _, action := range rule.Actions.Elements() {
+		if s, ok := action.(types.String); ok {
+			actions = append(actions, s.ValueString())
+		}
+	}
+	slices.Sort(actions)
+
+	return fmt.Sprintf("rule with prefix %q, suffix %q and actions %s", rule.Prefix.ValueString(), rule.Suffix.ValueString(), strings.Join(actions, ", "))
+}
+
+// CheckActions reports the actions of a rule that are not supported, at the
+// given path.
+func CheckActions(rule RuleModel, p path.Path) (diags diag.Diagnostics) {
+	for _, action := range rule.Actions.Elements() {
+		s, ok := action.(types.String)
+		if !ok || s.IsNull() || s.IsUnknown() || slices.Contains(Actions, s.ValueString()) {
+			continue
+		}
+		diags.AddAttributeError(
+			p,
+			"unsupported r2 event notification action",
+			fmt.Sprintf("Action %q of the %s is not supported. Supported actions: %s.", s.ValueString(), Describe(rule), strings.Join(Actions, ", ")),
+		)
+	}
+	return
+}
+
+// FromAPI converts the rules of a queue read from the API.
+func FromAPI(ctx context.Context, apiRules []event_notifications.R2ConfigurationGetResponseQueuesRule) ([]RuleModel, diag.Diagnostics) {
+	var models []RuleModel
+	var allDiags diag.Diagnostics
+	for _, rule := range apiRules {
+		actions := make([]types.String, len(rule.Actions))
+		for i, action := range rule.Actions {
+			actions[i] = types.StringValue(string(action))
+		}
+
+		actionSet, diags := customfield.NewSet[types.String](ctx, actions)
+		allDiags = append(allDiags, diags...)
+
+		models = append(
+			models,
+			RuleModel{
+				RuleID:    types.StringValue(rule.RuleID),
+				Suffix:    types.StringValue(rule.Suffix),
+				Prefix:    types.StringValue(rule.Prefix),
+				CreatedAt: types.StringValue(rule.CreatedAt),
+				Actions:   actionSet,
+			},
+		)
+	}
+
+	return models, allDiags
+}
+
+// FromAPIRule returns the content of a single rule read from the API, for
+// comparing it with planned rules.
+func FromAPIRule(rule event_notifications.R2ConfigurationGetResponseQueuesRule) RuleModel {
+	models, _ := FromAPI(context.Background(), []event_notifications.R2ConfigurationGetResponseQueuesRule{rule})
+	return models[0]
+}
+
+func toUpdateParams(rules []RuleModel) []event_notifications.R2ConfigurationQueueUpdateParamsRule {
+	params := make([]event_notifications.R2ConfigurationQueueUpdateParamsRule, len(rules))
+	for i, rule := range rules {
+		var actions []event_notifications.R2ConfigurationQueueUpdateParamsRulesAction
+		for _, action := range rule.Actions.Elements() {
+			if s, ok := action.(types.String); ok {
+				actions = append(actions, event_notifications.R2ConfigurationQueueUpdateParamsRulesAction(s.ValueString()))
+			}
+		}
+
+		params[i] = event_notifications.R2ConfigurationQueueUpdateParamsRule{
+			Actions: cloudflare.F(actions),
+			Prefix:  cloudflare.F(rule.Prefix.ValueString()),
+			Suffix:  cloudflare.F(rule.Suffix.ValueString()),
+		}
+	}
+	return params
+}
+
+// SameRules reports whether two sets of rules have the same content.
+func SameRules(a, b []RuleModel) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for _, ruleA := range a {
+		if !slices.ContainsFunc(b, func(ruleB RuleModel) bool { return Equal(ruleA, ruleB) }) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// QueueRules are the rules a queue of a bucket has, and the ones it should
+// have.
+type QueueRules struct {
+	QueueID  string
+	Current  []RuleModel
+	Declared []RuleModel
+}
+
+// QueueChanges is the minimal set of changes that turns the current rules of
+// a queue into the declared ones. Rules are compared by content, as a changed
+// rule is planned as a new rule without an ID.
+type QueueChanges struct {
+	QueueID string
+	// Current are the rules the queue had before the changes.
+	Current []RuleModel
+	// RemoveAll is set when the queue keeps no rule and gets no new one, so
+	// its whole configuration is deleted.
+	RemoveAll bool
+	// Added are new rules that overlap none of the removed rules of the
+	// bucket. They are added first, so no event goes without a notification
+	// in between.
+	Added []RuleModel
+	// Removed are current rules that are not declared.
+	Removed []RuleModel
+	// Replacing are new rules that overlap a removed rule. The API rejects
+	// overlapping rules, so they are only added once the removed rules are
+	// deleted.
+	Replacing []RuleModel
+}
+
+// Diff returns the changes of the queues whose rules differ, in the order of
+// queues.
+func Diff(queues []QueueRules) []QueueChanges {
+	var changes []QueueChanges
+	var newRules [][]RuleModel
+	var removed []RuleModel
+	for _, q := range queues {
+		unmatched := slices.Clone(q.Current)
+		var added []RuleModel
+		for _, rule := range q.Declared {
+			i := slices.IndexFunc(unmatched, func(existing RuleModel) bool {
+				return Equal(existing, rule)
+			})
+			if i < 0 {
+				added = append(added, rule)
+				continue
+			}
+			unmatched = slices.Delete(unmatched, i, i+1)
+		}
+		if len(added) == 0 && len(unmatched) == 0 {
+			continue
+		}
+
+		changes = append(changes, QueueChanges{
+			QueueID:   q.QueueID,
+			Current:   q.Current,
+			RemoveAll: len(q.Declared) == 0,
+			Removed:   unmatched,
+		})
+		newRules = append(newRules, added)
+		removed = append(removed, unmatched...)
+	}
+
+	// rules may not overlap across the queues of a bucket
+	for i := range changes {
+		for _, rule := range newRules[i] {
+			if slices.ContainsFunc(removed, func(r RuleModel) bool { return Overlap(rule, r) }) {
+				changes[i].Replacing = append(changes[i].Replacing, rule)
+			} else {
+				changes[i].Added = append(changes[i].Added, rule)
+			}
+		}
+	}
+
+	return changes
+}
+
+// DescribeChanges lists the changed rules, one per line.
+func DescribeChanges(changes []QueueChanges) string {
+	var lines []string
+	for _, c := range changes {
+		for _, rule := range append(slices.Clone(c.Added), c.Replacing...) {
+			lines = append(lines, fmt.Sprintf("queue %s: added %s", c.QueueID, Describe(rule)))
+		}
+		for _, rule := range c.Removed {
+			lines = append(lines, fmt.Sprintf("queue %s: removed %s (rule ID %s)", c.QueueID, Describe(rule), rule.RuleID.ValueString()))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,159 @@
+package r2notifications_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/cloudflare/cloudflare-go/v3"
+	"github.com/cloudflare/cloudflare-go/v3/option"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/acctest"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/acctest/mockserver"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/customfield"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/r2notifications"
+)
+
+func rule(prefix, suffix string, actions ...string) r2notifications.RuleModel {
+	values := make([]types.String, len(actions))
+	for i, action := range actions {
+		values[i] = types.StringValue(action)
+	}
+	set, _ := customfield.NewSet[types.String](context.Background(), values)
+
+	return r2notifications.RuleModel{
+		Actions: set,
+		Prefix:  types.StringValue(prefix),
+		Suffix:  types.StringValue(suffix),
+	}
+}
+
+func TestOverlap(t *testing.T) {
+	cases := map[string]struct {
+		a, b    r2notifications.RuleModel
+		overlap bool
+	}{
+		"same":              {rule("", ".png", "PutObject"), rule("", ".png", "PutObject"), true},
+		"nested prefix":     {rule("images/", "", "PutObject"), rule("", ".png", "PutObject", "DeleteObject"), true},
+		"distinct actions":  {rule("", ".png", "PutObject"), rule("", ".png", "DeleteObject"), false},
+		"distinct prefixes": {rule("images/", "", "PutObject"), rule("docs/", "", "PutObject"), false},
+		"distinct suffixes": {rule("", ".png", "PutObject"), rule("", ".pdf", "PutObject"), false},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := r2notifications.Overlap(c.a, c.b); got != c.overlap {
+				t.Fatalf("expected overlap %t, got %t", c.overlap, got)
+			}
+			if got := r2notifications.Overlap(c.b, c.a); got != c.overlap {
+				t.Fatalf("expected symmetric overlap %t, got %t", c.overlap, got)
+			}
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	png := rule("", ".png", "PutObject")
+	pngDelete := rule("", ".png", "PutObject", "DeleteObject")
+	pdf := rule("", ".pdf", "PutObject")
+
+	changes := r2notifications.Diff([]r2notifications.QueueRules{
+		{QueueID: "a", Current: []r2notifications.RuleModel{png}, Declared: []r2notifications.RuleModel{pdf}},
+		{QueueID: "b", Declared: []r2notifications.RuleModel{pngDelete}},
+		{QueueID: "c", Current: []r2notifications.RuleModel{pdf}, Declared: []r2notifications.RuleModel{pdf}},
+		{QueueID: "d", Current: []r2notifications.RuleModel{rule("x/", "", "LifecycleDeletion")}},
+	})
+
+	if len(changes) != 3 {
+		t.Fatalf("expected changes of 3 queues, got %d", len(changes))
+	}
+	if c := changes[0]; c.QueueID != "a" || len(c.Added) != 1 || len(c.Removed) != 1 || len(c.Replacing) != 0 || c.RemoveAll {
+		t.Fatalf("unexpected changes of queue a: %+v", c)
+	}
+	// the new rule of queue b overlaps the removed rule of queue a
+	if c := changes[1]; c.QueueID != "b" || len(c.Added) != 0 || len(c.Replacing) != 1 {
+		t.Fatalf("unexpected changes of queue b: %+v", c)
+	}
+	if c := changes[2]; c.QueueID != "d" || !c.RemoveAll || len(c.Removed) != 1 {
+		t.Fatalf("unexpected changes of queue d: %+v", c)
+	}
+}
+
+func TestApplyRollback(t *testing.T) {
+	cases := map[string]struct {
+		failDelete bool
+		kept       []r2notifications.RuleModel
+	}{
+		"failed delete": {failDelete: true},
+		// the new rule of queue b also overlaps the kept rule of queue c, so
+		// the API rejects it
+		"failed replacing": {kept: []r2notifications.RuleModel{rule("", ".png", "DeleteObject")}},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			srv := mockserver.New(t)
+			accountID := acctest.TestAccCloudflareAccountID
+			queueA := srv.CreateQueue(accountID, "a")
+			queueB := srv.CreateQueue(accountID, "b")
+			queueC := srv.CreateQueue(accountID, "c")
+			bucket := r2notifications.Bucket{
+				Client: cloudflare.NewClient(
+					option.WithBaseURL(srv.BaseURL()),
+					option.WithAPIToken(acctest.MockAPIToken),
+					option.WithMaxRetries(0),
+				),
+				AccountID: accountID,
+				Name:      "bucket",
+			}
+
+			if err := bucket.AddRules(ctx, queueA, []r2notifications.RuleModel{rule("", ".png", "PutObject")}); err != nil {
+				t.Fatal(err)
+			}
+			if len(c.kept) > 0 {
+				if err := bucket.AddRules(ctx, queueC, c.kept); err != nil {
+					t.Fatal(err)
+				}
+			}
+			queue, err := bucket.Queue(ctx, queueA)
+			if err != nil {
+				t.Fatal(err)
+			}
+			current, _ := r2notifications.FromAPI(ctx, queue.Rules)
+
+			// the .pdf rule is added first, the .png rule of queue b only
+			// once the .png rule of queue a is deleted
+			changes := r2notifications.Diff([]r2notifications.QueueRules{
+				{QueueID: queueA, Current: current, Declared: []r2notifications.RuleModel{rule("", ".pdf", "PutObject")}},
+				{QueueID: queueB, Declared: []r2notifications.RuleModel{rule("", ".png", "PutObject", "DeleteObject")}},
+			})
+
+			if c.failDelete {
+				srv.FailNextRequestsWithMethod(http.MethodDelete, 1, http.StatusInternalServerError)
+			}
+			diags := bucket.Apply(ctx, changes)
+
+			if !diags.HasError() {
+				t.Fatal("expected the failure to be reported")
+			}
+			if n := diags.WarningsCount(); n != 1 || diags.Warnings()[0].Summary() != "rolled back r2 event notification rules" {
+				t.Fatalf("expected a rollback warning, got %v", diags)
+			}
+			queue, err = bucket.Queue(ctx, queueA)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rules, _ := r2notifications.FromAPI(ctx, queue.Rules)
+			if !r2notifications.SameRules(rules, current) {
+				t.Fatalf("expected queue a to keep its .png rule, got %d rules", len(rules))
+			}
+			if n := srv.R2NotificationRuleCount(accountID, "bucket", queueB); n != 0 {
+				t.Fatalf("expected queue b to have no rules, got %d", n)
+			}
+			if n := srv.R2NotificationRuleCount(accountID, "bucket", queueC); n != len(c.kept) {
+				t.Fatalf("expected queue c to keep %d rules, got %d", len(c.kept), n)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+// Package ratelimit bounds the number of API requests in flight and the rate
+// at which they are sent, across every resource of a provider.
+package ratelimit
+
+import (
+	"net/http"
+
+	"github.com/cloudflare/cloudflare-go/v3/option"
+	"golang.org/x/time/rate"
+)
+
+// DefaultMaxConcurrentRequests is the number of requests in flight allowed by
+// default, the default parallelism of Terraform.
+const DefaultMaxConcurrentRequests = 10
+
+// Limiter gates requests through a semaphore and, optionally, a token bucket.
+// A single Limiter is shared by every request of a provider.
+type Limiter struct {
+	slots   chan struct{}
+	limiter *rate.Limiter
+}
+
+// New returns a limiter allowing at most maxConcurrent requests in flight and
+// sending at most requestsPerSecond requests per second. Zero disables either
+// limit.
+func New(maxConcurrent int, requestsPerSecond float64) *Limiter {
+	l := &Limiter{}
+	if maxConcurrent > 0 {
+		l.slots = make(chan struct{}, maxConcurrent)
+	}
+	if requestsPerSecond > 0 {
+		// allow a second worth of requests to go out at once
+		l.limiter = rate.NewLimiter(rate.Limit(requestsPerSecond), max(1, int(requestsPerSecond)))
+	}
+
+	return l
+}
+
+// Middleware returns a client middleware that waits for the limiter before
+// sending a request. Installed after the retry middleware, every attempt of a
+// request waits on its own and no slot is held while backing off.
+func (l *Limiter) Middleware() option.Middleware {
+	return func(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+		ctx := req.Context()
+
+		if l.slots != nil {
+			select {
+			case l.slots <- struct{}{}:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+			defer func() { <-l.slots }()
+		}
+
+		if l.limiter != nil {
+			if err := l.limiter.Wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		return next(req)
+	}
+}
@@ -0,0 +1,100 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newRequest(t *testing.T, ctx context.Context) *http.Request {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.cloudflare.com/client/v4/accounts", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return req
+}
+
+func TestMiddlewareConcurrency(t *testing.T) {
+	middleware := New(3, 0).Middleware()
+
+	var inFlight, peak atomic.Int64
+	next := func(*http.Request) (*http.Response, error) {
+		n := inFlight.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		inFlight.Add(-1)
+		return &http.Response{StatusCode: http.StatusOK}, nil
+	}
+
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := middleware(newRequest(t, context.Background()), next); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := peak.Load(); got != 3 {
+		t.Errorf("got %d requests in flight at most, want 3", got)
+	}
+}
+
+func TestMiddlewareRequestsPerSecond(t *testing.T) {
+	middleware := New(0, 20).Middleware()
+	next := func(*http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK}, nil
+	}
+
+	// the first 20 requests are the burst, the next 10 take half a second
+	start := time.Now()
+	for range 30 {
+		if _, err := middleware(newRequest(t, context.Background()), next); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 400*time.Millisecond {
+		t.Errorf("sent 30 requests in %s, faster than 20 per second", elapsed)
+	}
+}
+
+func TestMiddlewareCanceled(t *testing.T) {
+	middleware := New(1, 0).Middleware()
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	go func() {
+		_, _ = middleware(newRequest(t, context.Background()), func(*http.Request) (*http.Response, error) {
+			close(started)
+			<-release
+			return &http.Response{StatusCode: http.StatusOK}, nil
+		})
+	}()
+	<-started
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := middleware(newRequest(t, ctx), func(*http.Request) (*http.Response, error) {
+		t.Error("request sent while the only slot is taken")
+		return nil, nil
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got error %v, want %v", err, context.DeadlineExceeded)
+	}
+}
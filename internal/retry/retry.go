@@ -0,0 +1,183 @@
+// Package retry retries API requests that fail with a transient error, such as
+// a rate limit or a server error, with exponential backoff.
+package retry
+
+import (
+	"context"
+	"io"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cloudflare/cloudflare-go/v3/option"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/logging"
+)
+
+const (
+	// DefaultMaxRetries is the number of times a request is retried by default.
+	DefaultMaxRetries = 4
+
+	// DefaultMinBackoff is the default delay before the first retry.
+	DefaultMinBackoff = time.Second
+
+	// DefaultMaxBackoff is the default upper bound of the delay between retries.
+	DefaultMaxBackoff = 30 * time.Second
+
+	// DefaultMaxElapsed bounds the time spent retrying a request, the window
+	// of the Cloudflare API rate limit.
+	DefaultMaxElapsed = 5 * time.Minute
+)
+
+// Policy decides whether and when a failed request is retried.
+type Policy struct {
+	// MaxRetries is the number of retries after the first attempt.
+	MaxRetries int
+
+	// MinBackoff is the delay before the first retry, doubled on every
+	// following one.
+	MinBackoff time.Duration
+
+	// MaxBackoff caps the delay between retries. A `Retry-After` sent by the
+	// API is honored even when it exceeds it.
+	MaxBackoff time.Duration
+
+	// MaxElapsed caps the total time spent on a request, a retry that would
+	// start after it is not attempted.
+	MaxElapsed time.Duration
+}
+
+// DefaultPolicy returns the policy used when the provider configures none.
+func DefaultPolicy() Policy {
+	return Policy{
+		MaxRetries: DefaultMaxRetries,
+		MinBackoff: DefaultMinBackoff,
+		MaxBackoff: DefaultMaxBackoff,
+		MaxElapsed: DefaultMaxElapsed,
+	}
+}
+
+// Middleware returns a client middleware retrying requests according to the
+// policy. It must be installed on the client, so that it wraps the middlewares
+// of every request and each attempt is logged on its own.
+func (p Policy) Middleware() option.Middleware {
+	return func(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+		ctx := req.Context()
+		start := time.Now()
+
+		for attempt := 1; ; attempt++ {
+			resp, err := next(req)
+			if ctx.Err() != nil || !retryable(resp, err) {
+				return resp, err
+			}
+
+			// a body that can't be read again can't be retried
+			if req.Body != nil && req.GetBody == nil {
+				return resp, err
+			}
+
+			delay := p.backoff(attempt, resp)
+			if attempt > p.MaxRetries || time.Since(start)+delay > p.MaxElapsed {
+				logging.LogRetriesExhausted(ctx, req, resp, err, attempt)
+				return resp, err
+			}
+
+			retryReq := req.Clone(ctx)
+			if req.GetBody != nil {
+				body, bodyErr := req.GetBody()
+				if bodyErr != nil {
+					return resp, err
+				}
+				retryReq.Body = body
+			}
+
+			logging.LogRetry(ctx, req, resp, err, attempt, delay)
+			discard(resp)
+			if err := sleep(ctx, delay); err != nil {
+				return nil, err
+			}
+
+			req = retryReq
+		}
+	}
+}
+
+// retryable reports whether a request failed with an error that may go away
+// when it is sent again.
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		// a connection error
+		return true
+	}
+
+	return resp.StatusCode == http.StatusRequestTimeout ||
+		resp.StatusCode == http.StatusTooManyRequests ||
+		resp.StatusCode >= http.StatusInternalServerError
+}
+
+// backoff returns the delay before retrying a request after the given failed
+// attempt. The API's `Retry-After` wins, otherwise the delay grows
+// exponentially from MinBackoff up to MaxBackoff, with jitter so that
+// concurrent requests don't retry in lockstep.
+func (p Policy) backoff(attempt int, resp *http.Response) time.Duration {
+	if delay, ok := retryAfter(resp); ok {
+		return delay
+	}
+
+	base := float64(p.MinBackoff) * math.Pow(2, float64(attempt-1))
+	if base > float64(p.MaxBackoff) {
+		base = float64(p.MaxBackoff)
+	}
+
+	// equal jitter: half the delay is fixed, the other half random
+	delay := time.Duration(base/2 + rand.Float64()*base/2)
+	if delay < p.MinBackoff {
+		delay = p.MinBackoff
+	}
+	return delay
+}
+
+// retryAfter returns the delay requested by the `Retry-After` header of resp,
+// in seconds or as an HTTP date.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds >= 0 {
+		return time.Duration(seconds * float64(time.Second)), true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+
+	return 0, false
+}
+
+// discard releases the connection of a response that is not returned.
+func discard(resp *http.Response) {
+	if resp == nil || resp.Body == nil {
+		return
+	}
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+}
+
+func sleep(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
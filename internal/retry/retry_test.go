@@ -0,0 +1,158 @@
+package retry
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeNext answers the attempts of a request with the given statuses in order,
+// recording the body of every attempt.
+type fakeNext struct {
+	statuses []int
+	header   http.Header
+	bodies   []string
+}
+
+func (f *fakeNext) next(req *http.Request) (*http.Response, error) {
+	body := ""
+	if req.Body != nil {
+		b, _ := io.ReadAll(req.Body)
+		body = string(b)
+	}
+	f.bodies = append(f.bodies, body)
+
+	status := f.statuses[len(f.bodies)-1]
+	if status == 0 {
+		return nil, errors.New("connection reset")
+	}
+	return &http.Response{
+		StatusCode: status,
+		Header:     f.header.Clone(),
+		Body:       io.NopCloser(strings.NewReader("")),
+	}, nil
+}
+
+func newRequest(t *testing.T, body string) *http.Request {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPut, "https://api.cloudflare.com/client/v4/accounts/a/queues", bytes.NewReader([]byte(body)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return req
+}
+
+func fastPolicy() Policy {
+	return Policy{
+		MaxRetries: 3,
+		MinBackoff: time.Millisecond,
+		MaxBackoff: 4 * time.Millisecond,
+		MaxElapsed: time.Minute,
+	}
+}
+
+func TestMiddlewareRetries(t *testing.T) {
+	for name, tc := range map[string]struct {
+		statuses     []int
+		wantStatus   int
+		wantAttempts int
+		wantErr      bool
+	}{
+		"success":               {statuses: []int{200}, wantStatus: 200, wantAttempts: 1},
+		"rate limited":          {statuses: []int{429, 429, 200}, wantStatus: 200, wantAttempts: 3},
+		"server error":          {statuses: []int{502, 200}, wantStatus: 200, wantAttempts: 2},
+		"connection error":      {statuses: []int{0, 200}, wantStatus: 200, wantAttempts: 2},
+		"client error":          {statuses: []int{400}, wantStatus: 400, wantAttempts: 1},
+		"conflict":              {statuses: []int{409}, wantStatus: 409, wantAttempts: 1},
+		"exhausted":             {statuses: []int{503, 503, 503, 503}, wantStatus: 503, wantAttempts: 4},
+		"exhausted with errors": {statuses: []int{0, 0, 0, 0}, wantErr: true, wantAttempts: 4},
+	} {
+		t.Run(name, func(t *testing.T) {
+			f := &fakeNext{statuses: tc.statuses}
+			resp, err := fastPolicy().Middleware()(newRequest(t, "payload"), f.next)
+
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			} else if resp.StatusCode != tc.wantStatus {
+				t.Errorf("got status %d, want %d", resp.StatusCode, tc.wantStatus)
+			}
+
+			if len(f.bodies) != tc.wantAttempts {
+				t.Fatalf("got %d attempts, want %d", len(f.bodies), tc.wantAttempts)
+			}
+			for i, body := range f.bodies {
+				if body != "payload" {
+					t.Errorf("attempt %d sent body %q, want %q", i+1, body, "payload")
+				}
+			}
+		})
+	}
+}
+
+func TestMiddlewareHonorsRetryAfter(t *testing.T) {
+	f := &fakeNext{statuses: []int{429, 200}, header: http.Header{"Retry-After": []string{"0.05"}}}
+
+	start := time.Now()
+	resp, err := fastPolicy().Middleware()(newRequest(t, ""), f.next)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if resp.StatusCode != 200 {
+		t.Errorf("got status %d, want 200", resp.StatusCode)
+	}
+	// Retry-After wins over the much shorter backoff of the policy
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("retried after %s, before Retry-After", elapsed)
+	}
+}
+
+func TestMiddlewareMaxElapsed(t *testing.T) {
+	policy := fastPolicy()
+	policy.MaxElapsed = time.Second
+	f := &fakeNext{statuses: []int{429, 200}, header: http.Header{"Retry-After": []string{"60"}}}
+
+	resp, err := policy.Middleware()(newRequest(t, ""), f.next)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if resp.StatusCode != 429 || len(f.bodies) != 1 {
+		t.Errorf("got status %d after %d attempts, want 429 after 1", resp.StatusCode, len(f.bodies))
+	}
+}
+
+func TestMiddlewareUnreplayableBody(t *testing.T) {
+	req := newRequest(t, "payload")
+	req.GetBody = nil
+	f := &fakeNext{statuses: []int{503, 200}}
+
+	resp, err := fastPolicy().Middleware()(req, f.next)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if resp.StatusCode != 503 || len(f.bodies) != 1 {
+		t.Errorf("got status %d after %d attempts, want 503 after 1", resp.StatusCode, len(f.bodies))
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	policy := Policy{MinBackoff: time.Second, MaxBackoff: 10 * time.Second}
+
+	for attempt, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 5: 10 * time.Second} {
+		for range 100 {
+			got := policy.backoff(attempt, nil)
+			if got < max(want/2, policy.MinBackoff) || got > want {
+				t.Fatalf("attempt %d: backoff %s outside of [%s, %s]", attempt, got, max(want/2, policy.MinBackoff), want)
+			}
+		}
+	}
+}
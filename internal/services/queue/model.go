@@ -0,0 +1,43 @@
+package queue
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/apijson"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/customfield"
+)
+
+type QueueResultEnvelope struct {
+	Result QueueModel `json:"result"`
+}
+
+type QueueModel struct {
+	ID                  types.String                                      `tfsdk:"id" path:"id,computed"`
+	AccountID           types.String                                      `tfsdk:"account_id" path:"account_id,computed_optional"`
+	QueueID             types.String                                      `tfsdk:"queue_id" json:"queue_id,computed"`
+	QueueName           types.String                                      `tfsdk:"queue_name" json:"queue_name,required"`
+	Settings            customfield.NestedObject[QueueSettingsModel]      `tfsdk:"settings" json:"settings,computed_optional"`
+	ConsumersTotalCount types.Float64                                     `tfsdk:"consumers_total_count" json:"consumers_total_count,computed"`
+	CreatedOn           types.String                                      `tfsdk:"created_on" json:"created_on,computed"`
+	ModifiedOn          types.String                                      `tfsdk:"modified_on" json:"modified_on,computed"`
+	Producers           customfield.NestedObjectList[QueueProducersModel] `tfsdk:"producers" json:"producers,computed"`
+}
+
+func (m QueueModel) MarshalJSON() (data []byte, err error) {
+	return apijson.MarshalRoot(m)
+}
+
+func (m QueueModel) MarshalJSONForUpdate(state QueueModel) (data []byte, err error) {
+	return apijson.MarshalForUpdate(m, state)
+}
+
+type QueueSettingsModel struct {
+	DeliveryDelay          types.Float64 `tfsdk:"delivery_delay" json:"delivery_delay,computed_optional"`
+	DeliveryPaused         types.Bool    `tfsdk:"delivery_paused" json:"delivery_paused,computed_optional"`
+	MessageRetentionPeriod types.Float64 `tfsdk:"message_retention_period" json:"message_retention_period,computed_optional"`
+}
+
+type QueueProducersModel struct {
+	Type       types.String `tfsdk:"type" json:"type,computed"`
+	Script     types.String `tfsdk:"script" json:"script,computed"`
+	BucketName types.String `tfsdk:"bucket_name" json:"bucket_name,computed"`
+}
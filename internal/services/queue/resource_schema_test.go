@@ -0,0 +1,17 @@
+package queue_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/services/queue"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/test_helpers"
+)
+
+func TestQueueModelSchemaParity(t *testing.T) {
+	t.Parallel()
+	model := (*queue.QueueModel)(nil)
+	schema := queue.ResourceSchema(context.TODO())
+	errs := test_helpers.ValidateResourceModelSchemaIntegrity(model, schema)
+	errs.Report(t)
+}
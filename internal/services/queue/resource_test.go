@@ -0,0 +1,179 @@
+package queue_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/cloudflare/cloudflare-go/v3"
+	"github.com/cloudflare/cloudflare-go/v3/queues"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/acctest"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/utils"
+)
+
+func TestMain(m *testing.M) {
+	resource.TestMain(m)
+}
+
+func init() {
+	acctest.AddTestSweepers("cloudflare-extended_queue")
+}
+
+func TestAccCloudflareQueue_Create(t *testing.T) {
+	acctest.Parallel(t)
+
+	rnd := acctest.RandomResourceName(t)
+	name := "cloudflare-extended_queue." + rnd
+	accountID := os.Getenv("CLOUDFLARE_ACCOUNT_ID")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			acctest.TestAccPreCheck(t)
+			acctest.TestAccPreCheck_AccountID(t)
+		},
+		ProtoV6ProviderFactories: acctest.TestAccProtoV6ProviderFactories(t),
+		CheckDestroy:             testAccCheckCloudflareQueueDestroy(acctest.Client(t)),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckCloudflareQueueConfigInitial(rnd, accountID),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "queue_name", rnd),
+					resource.TestCheckResourceAttrSet(name, "queue_id"),
+				),
+			},
+			{
+				Config: testAccCheckCloudflareQueueConfigUpdate(rnd, accountID),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "queue_name", rnd+"-renamed"),
+					resource.TestCheckResourceAttr(name, "settings.delivery_delay", "30"),
+					resource.TestCheckResourceAttr(name, "settings.delivery_paused", "true"),
+					resource.TestCheckResourceAttr(name, "settings.message_retention_period", "86400"),
+				),
+			},
+		},
+	})
+}
+
+func TestCloudflareQueue_Offline(t *testing.T) {
+	srv := acctest.NewOfflineTest(t)
+	rnd := utils.GenerateRandomResourceName()
+	name := "cloudflare-extended_queue." + rnd
+	accountID := srv.AccountID
+	provider := srv.ProviderConfig()
+	queueID := ""
+
+	srv.Test(resource.TestCase{
+		Steps: []resource.TestStep{
+			{
+				Config: provider + testAccCheckCloudflareQueueConfigInitial(rnd, accountID),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "queue_name", rnd),
+					resource.TestCheckResourceAttr(name, "settings.delivery_delay", "0"),
+					resource.TestCheckResourceAttr(name, "settings.delivery_paused", "false"),
+					resource.TestCheckResourceAttr(name, "settings.message_retention_period", "345600"),
+					resource.TestCheckResourceAttr(name, "consumers_total_count", "0"),
+					resource.TestCheckResourceAttr(name, "producers.#", "0"),
+					resource.TestCheckResourceAttrPair(name, "id", name, "queue_id"),
+					resource.TestCheckResourceAttrWith(name, "queue_id", func(value string) error {
+						queueID = value
+						return nil
+					}),
+				),
+			},
+			{
+				Config: provider + testAccCheckCloudflareQueueConfigUpdate(rnd, accountID),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction(name, plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "queue_name", rnd+"-renamed"),
+					resource.TestCheckResourceAttr(name, "settings.delivery_delay", "30"),
+					resource.TestCheckResourceAttr(name, "settings.delivery_paused", "true"),
+					resource.TestCheckResourceAttr(name, "settings.message_retention_period", "86400"),
+					resource.TestCheckResourceAttrWith(name, "queue_id", func(value string) error {
+						if value != queueID {
+							return fmt.Errorf("expected queue %s to be updated in place, got %s", queueID, value)
+						}
+						return nil
+					}),
+				),
+			},
+			{
+				ResourceName:      name,
+				ImportState:       true,
+				ImportStateIdFunc: func(*terraform.State) (string, error) { return accountID + "/" + queueID, nil },
+				ImportStateVerify: true,
+			},
+			{
+				Config: provider + testAccCheckCloudflareQueueConfigWithConsumer(rnd, accountID, rnd),
+			},
+			{
+				Config: provider + testAccCheckCloudflareQueueConfigWithConsumer(rnd, accountID, rnd),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "consumers_total_count", "1"),
+					resource.TestCheckResourceAttr(name, "producers.#", "1"),
+					resource.TestCheckResourceAttr(name, "producers.0.type", "r2_bucket"),
+					resource.TestCheckResourceAttr(name, "producers.0.bucket_name", rnd),
+				),
+			},
+			{
+				PreConfig: func() {
+					_, err := srv.APIClient().Queues.Delete(
+						context.Background(),
+						queueID,
+						queues.QueueDeleteParams{AccountID: cloudflare.F(accountID)},
+					)
+					if err != nil {
+						t.Fatalf("failed to delete queue out-of-band: %s", err)
+					}
+				},
+				Config:             provider + testAccCheckCloudflareQueueConfigUpdate(rnd, accountID),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func testAccCheckCloudflareQueueConfigInitial(rnd, accountID string) string {
+	return acctest.LoadTestCase("queueinitial.tf", rnd, accountID)
+}
+
+func testAccCheckCloudflareQueueConfigUpdate(rnd, accountID string) string {
+	return acctest.LoadTestCase("queueupdate.tf", rnd, accountID)
+}
+
+func testAccCheckCloudflareQueueConfigWithConsumer(rnd, accountID, bucketName string) string {
+	return acctest.LoadTestCase("queuewithconsumer.tf", rnd, accountID, bucketName)
+}
+
+func testAccCheckCloudflareQueueDestroy(client *cloudflare.Client) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		for _, rs := range s.RootModule().Resources {
+			if rs.Type != "cloudflare-extended_queue" {
+				continue
+			}
+
+			_, err := client.Queues.Get(
+				context.Background(),
+				rs.Primary.Attributes["queue_id"],
+				queues.QueueGetParams{
+					AccountID: cloudflare.F(rs.Primary.Attributes["account_id"]),
+				})
+			if err == nil {
+				return fmt.Errorf("queue %s still exists", rs.Primary.Attributes["queue_name"])
+			}
+			if !utils.IsNotFound(err) {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
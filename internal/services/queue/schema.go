@@ -0,0 +1,105 @@
+package queue
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/float64validator"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/customfield"
+)
+
+var _ resource.ResourceWithConfigValidators = (*QueueResource)(nil)
+
+func ResourceSchema(ctx context.Context) schema.Schema {
+	return schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description:   "Identifier.",
+				Computed:      true,
+				PlanModifiers: []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+			},
+			"account_id": schema.StringAttribute{
+				Description:   "Identifier. Defaults to the `account_id` of the provider.",
+				Optional:      true,
+				Computed:      true,
+				PlanModifiers: []planmodifier.String{stringplanmodifier.UseStateForUnknown(), stringplanmodifier.RequiresReplace()},
+			},
+			"queue_id": schema.StringAttribute{
+				Description:   "Identifier.",
+				Computed:      true,
+				PlanModifiers: []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+			},
+			"queue_name": schema.StringAttribute{
+				Description: "Name of the queue.",
+				Required:    true,
+			},
+			"settings": schema.SingleNestedAttribute{
+				Computed:   true,
+				Optional:   true,
+				CustomType: customfield.NewNestedObjectType[QueueSettingsModel](ctx),
+				Attributes: map[string]schema.Attribute{
+					"delivery_delay": schema.Float64Attribute{
+						Description: "Number of seconds to delay delivery of all messages to consumers.",
+						Computed:    true,
+						Optional:    true,
+						Validators:  []validator.Float64{float64validator.Between(0, 43200)},
+					},
+					"delivery_paused": schema.BoolAttribute{
+						Description: "Whether delivery of messages to consumers is paused.",
+						Computed:    true,
+						Optional:    true,
+					},
+					"message_retention_period": schema.Float64Attribute{
+						Description: "Number of seconds an unconsumed message is retained before it is deleted.",
+						Computed:    true,
+						Optional:    true,
+						Validators:  []validator.Float64{float64validator.Between(60, 1209600)},
+					},
+				},
+			},
+			"consumers_total_count": schema.Float64Attribute{
+				Description: "Number of consumers of the queue.",
+				Computed:    true,
+			},
+			"created_on": schema.StringAttribute{
+				Computed:      true,
+				PlanModifiers: []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+			},
+			"modified_on": schema.StringAttribute{
+				Computed: true,
+			},
+			"producers": schema.ListNestedAttribute{
+				Computed:   true,
+				CustomType: customfield.NewNestedObjectListType[QueueProducersModel](ctx),
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"type": schema.StringAttribute{
+							Description: `Type of producer. One of "worker", or "r2_bucket"`,
+							Computed:    true,
+						},
+						"script": schema.StringAttribute{
+							Description: "Name of the Worker script, for worker producers.",
+							Computed:    true,
+						},
+						"bucket_name": schema.StringAttribute{
+							Description: "Name of the R2 bucket, for r2_bucket producers.",
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (r *QueueResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = ResourceSchema(ctx)
+}
+
+func (r *QueueResource) ConfigValidators(_ context.Context) []resource.ConfigValidator {
+	return []resource.ConfigValidator{}
+}
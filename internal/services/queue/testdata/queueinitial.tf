@@ -0,0 +1,4 @@
+resource "cloudflare-extended_queue" "%[1]s" {
+  account_id = "%[2]s"
+  queue_name = "%[1]s"
+}
@@ -0,0 +1,10 @@
+resource "cloudflare-extended_queue" "%[1]s" {
+  account_id = "%[2]s"
+  queue_name = "%[1]s-renamed"
+
+  settings = {
+    delivery_delay           = 30
+    delivery_paused          = true
+    message_retention_period = 86400
+  }
+}
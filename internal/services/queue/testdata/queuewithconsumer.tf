@@ -0,0 +1,28 @@
+resource "cloudflare-extended_queue" "%[1]s" {
+  account_id = "%[2]s"
+  queue_name = "%[1]s-renamed"
+
+  settings = {
+    delivery_delay           = 30
+    delivery_paused          = true
+    message_retention_period = 86400
+  }
+}
+
+resource "cloudflare-extended_queue_consumer" "%[1]s" {
+  account_id = "%[2]s"
+  queue_id   = cloudflare-extended_queue.%[1]s.queue_id
+  type       = "http_pull"
+}
+
+resource "cloudflare-extended_r2_event_notification" "%[1]s" {
+  account_id  = "%[2]s"
+  bucket_name = "%[3]s"
+  queue_id    = cloudflare-extended_queue.%[1]s.queue_id
+
+  rules = [
+    {
+      actions = ["PutObject"],
+    },
+  ]
+}
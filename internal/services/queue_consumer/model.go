@@ -10,8 +10,39 @@ type QueueConsumerResultEnvelope struct {
 	Result QueueConsumerModel `json:"result"`
 }
 
+// QueueConsumersResultEnvelope is the response of listing the consumers of a
+// queue. The typed SDK response omits the consumer ID and type, so the raw
+// response is decoded instead.
+type QueueConsumersResultEnvelope struct {
+	Result []QueueConsumerResponse `json:"result"`
+}
+
+type QueueConsumerResponse struct {
+	ConsumerID      string                        `json:"consumer_id"`
+	CreatedOn       string                        `json:"created_on"`
+	DeadLetterQueue string                        `json:"dead_letter_queue"`
+	Environment     string                        `json:"environment"`
+	QueueName       string                        `json:"queue_name"`
+	ScriptName      string                        `json:"script_name"`
+	Service         string                        `json:"service"`
+	Settings        QueueConsumerSettingsResponse `json:"settings"`
+	Type            string                        `json:"type"`
+}
+
+// QueueConsumerSettingsResponse holds the settings of a consumer. The API
+// only returns the settings that apply to the consumer type, and leaves
+// max_concurrency out when worker consumers scale automatically.
+type QueueConsumerSettingsResponse struct {
+	BatchSize           *float64 `json:"batch_size"`
+	MaxConcurrency      *float64 `json:"max_concurrency"`
+	MaxRetries          *float64 `json:"max_retries"`
+	MaxWaitTimeMs       *float64 `json:"max_wait_time_ms"`
+	RetryDelay          *float64 `json:"retry_delay"`
+	VisibilityTimeoutMs *float64 `json:"visibility_timeout_ms"`
+}
+
 type QueueConsumerModel struct {
-	AccountID       types.String                                         `tfsdk:"account_id" path:"account_id,required"`
+	AccountID       types.String                                         `tfsdk:"account_id" path:"account_id,computed_optional"`
 	QueueID         types.String                                         `tfsdk:"queue_id" path:"queue_id,required"`
 	ScriptName      types.String                                         `tfsdk:"script_name" json:"script_name,optional"`
 	ConsumerID      types.String                                         `tfsdk:"consumer_id" json:"consumer_id,computed"`
@@ -32,7 +63,10 @@ func (m QueueConsumerModel) MarshalJSONForUpdate(state QueueConsumerModel) (data
 }
 
 type QueueConsumerSettingsModel struct {
-	BatchSize     types.Float64 `tfsdk:"batch_size" json:"batch_size,computed_optional"`
-	MaxRetries    types.Float64 `tfsdk:"max_retries" json:"max_retries,computed_optional"`
-	MaxWaitTimeMs types.Float64 `tfsdk:"max_wait_time_ms" json:"max_wait_time_ms,computed_optional"`
+	BatchSize           types.Float64 `tfsdk:"batch_size" json:"batch_size,computed_optional"`
+	MaxConcurrency      types.Float64 `tfsdk:"max_concurrency" json:"max_concurrency,computed_optional"`
+	MaxRetries          types.Float64 `tfsdk:"max_retries" json:"max_retries,computed_optional"`
+	MaxWaitTimeMs       types.Float64 `tfsdk:"max_wait_time_ms" json:"max_wait_time_ms,computed_optional"`
+	RetryDelay          types.Float64 `tfsdk:"retry_delay" json:"retry_delay,computed_optional"`
+	VisibilityTimeoutMs types.Float64 `tfsdk:"visibility_timeout_ms" json:"visibility_timeout_ms,computed_optional"`
 }
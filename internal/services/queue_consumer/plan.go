@@ -0,0 +1,97 @@
+package queue_consumer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cloudflare/cloudflare-go/v3"
+	"github.com/cloudflare/cloudflare-go/v3/option"
+	"github.com/cloudflare/cloudflare-go/v3/queues"
+	"github.com/cloudflare/cloudflare-go/v3/workers"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/logging"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/utils"
+)
+
+// API error code returned when the Worker script does not exist.
+const scriptNotFoundErrorCode = 10007
+
+// checkDeadLetterQueue resolves the dead letter queue by name against the
+// queues of the account. A dead letter queue that does not exist, or that is
+// the consumed queue itself, would silently drop the messages that run out of
+// retries. The queue may be created later in the same apply, so a missing
+// queue is only an error when applying.
+func (r *QueueConsumerResource) checkDeadLetterQueue(ctx context.Context, data *QueueConsumerModel, apply bool) (diags diag.Diagnostics) {
+	list := r.client.Queues.ListAutoPaging(
+		ctx,
+		queues.QueueListParams{AccountID: cloudflare.F(data.AccountID.ValueString())},
+		option.WithMiddleware(logging.Middleware(ctx)),
+	)
+
+	var dlq *queues.Queue
+	for list.Next() {
+		if list.Current().QueueName == data.DeadLetterQueue.ValueString() {
+			queue := list.Current()
+			dlq = &queue
+			break
+		}
+	}
+	if err := list.Err(); err != nil {
+		diags.AddError("failed to make http request", err.Error())
+		return
+	}
+
+	if dlq == nil {
+		summary := "dead letter queue not found"
+		detail := fmt.Sprintf("No queue named %q exists in account %s.", data.DeadLetterQueue.ValueString(), data.AccountID.ValueString())
+		if apply {
+			diags.AddAttributeError(path.Root("dead_letter_queue"), summary, detail)
+		} else {
+			diags.AddAttributeWarning(path.Root("dead_letter_queue"), summary, detail+" Messages that run out of retries are dropped unless it is created before the consumer.")
+		}
+		return
+	}
+
+	// some APIs format queue IDs with hyphens
+	if !data.QueueID.IsUnknown() && strings.ReplaceAll(dlq.QueueID, "-", "") == strings.ReplaceAll(data.QueueID.ValueString(), "-", "") {
+		diags.AddAttributeError(
+			path.Root("dead_letter_queue"),
+			"invalid dead letter queue",
+			fmt.Sprintf("Queue %q cannot be the dead letter queue of its own consumer.", data.DeadLetterQueue.ValueString()),
+		)
+	}
+
+	return
+}
+
+// checkScriptName warns when the consuming Worker script does not exist. The
+// script may be created later in the same apply, so this is not an error.
+func (r *QueueConsumerResource) checkScriptName(ctx context.Context, data *QueueConsumerModel) (diags diag.Diagnostics) {
+	_, err := r.client.Workers.Scripts.Settings.Get(
+		ctx,
+		data.ScriptName.ValueString(),
+		workers.ScriptSettingGetParams{AccountID: cloudflare.F(data.AccountID.ValueString())},
+		option.WithMiddleware(logging.Middleware(ctx)),
+	)
+	if utils.IsNotFound(err, scriptNotFoundErrorCode) {
+		diags.AddAttributeWarning(
+			path.Root("script_name"),
+			"worker script not found",
+			fmt.Sprintf("No Worker script named %q exists in account %s. The consumer cannot be created until it does.", data.ScriptName.ValueString(), data.AccountID.ValueString()),
+		)
+		return
+	}
+	if err != nil {
+		diags.AddError("failed to make http request", err.Error())
+	}
+
+	return
+}
+
+// known reports whether a planned value is set and known.
+func known(v types.String) bool {
+	return !v.IsNull() && !v.IsUnknown()
+}
@@ -2,6 +2,7 @@ package queue_consumer
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -9,16 +10,24 @@ import (
 	"github.com/cloudflare/cloudflare-go/v3"
 	"github.com/cloudflare/cloudflare-go/v3/option"
 	"github.com/cloudflare/cloudflare-go/v3/queues"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
-	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/apijson"
 	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/customfield"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/importpath"
 	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/logging"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/providerdata"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/utils"
 )
 
 // Ensure provider defined types fully satisfy framework interfaces.
 var _ resource.ResourceWithConfigure = (*QueueConsumerResource)(nil)
 var _ resource.ResourceWithModifyPlan = (*QueueConsumerResource)(nil)
+var _ resource.ResourceWithImportState = (*QueueConsumerResource)(nil)
+
+// API error code returned when the consumed queue does not exist.
+const queueNotFoundErrorCode = 11000
 
 func NewResource() resource.Resource {
 	return &QueueConsumerResource{}
@@ -26,7 +35,8 @@ func NewResource() resource.Resource {
 
 // QueueConsumerResource defines the resource implementation.
 type QueueConsumerResource struct {
-	client *cloudflare.Client
+	client           *cloudflare.Client
+	defaultAccountID string
 }
 
 func (r *QueueConsumerResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -38,18 +48,19 @@ func (r *QueueConsumerResource) Configure(ctx context.Context, req resource.Conf
 		return
 	}
 
-	client, ok := req.ProviderData.(*cloudflare.Client)
+	data, ok := req.ProviderData.(*providerdata.Data)
 
 	if !ok {
 		resp.Diagnostics.AddError(
 			"unexpected resource configure type",
-			fmt.Sprintf("Expected *cloudflare.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *providerdata.Data, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 
 		return
 	}
 
-	r.client = client
+	r.client = data.Client
+	r.defaultAccountID = data.AccountID
 }
 
 func (r *QueueConsumerResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
@@ -61,6 +72,13 @@ func (r *QueueConsumerResource) Create(ctx context.Context, req resource.CreateR
 		return
 	}
 
+	if !data.DeadLetterQueue.IsNull() {
+		resp.Diagnostics.Append(r.checkDeadLetterQueue(ctx, data, true)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
 	dataBytes, err := data.MarshalJSON()
 	if err != nil {
 		resp.Diagnostics.AddError("failed to serialize http request", err.Error())
@@ -111,6 +129,13 @@ func (r *QueueConsumerResource) Update(ctx context.Context, req resource.UpdateR
 		return
 	}
 
+	if !data.DeadLetterQueue.IsNull() && !data.DeadLetterQueue.Equal(state.DeadLetterQueue) {
+		resp.Diagnostics.Append(r.checkDeadLetterQueue(ctx, data, true)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
 	dataBytes, err := data.MarshalJSONForUpdate(*state)
 	if err != nil {
 		resp.Diagnostics.AddError("failed to serialize http request", err.Error())
@@ -153,45 +178,58 @@ func (r *QueueConsumerResource) Read(ctx context.Context, req resource.ReadReque
 		return
 	}
 
-	consumers, err := r.client.Queues.Consumers.Get(
-		ctx,
-		data.QueueID.ValueString(),
-		queues.ConsumerGetParams{
-			AccountID: cloudflare.F(data.AccountID.ValueString()),
-		},
-		option.WithMiddleware(logging.Middleware(ctx)),
-	)
-	if err != nil {
-		resp.Diagnostics.AddError("failed to make http request", err.Error())
+	consumers, err := r.listConsumers(ctx, data.AccountID.ValueString(), data.QueueID.ValueString())
+	if utils.IsNotFound(err, queueNotFoundErrorCode) {
+		resp.State.RemoveResource(ctx)
 		return
 	}
-	if consumers == nil {
-		req.State.RemoveResource(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError("failed to make http request", err.Error())
 		return
 	}
 
-	found := false
-	for _, consumer := range *consumers {
-		if consumer.Service == data.ScriptName.ValueString() {
-			found = true
-			data.CreatedOn = basetypes.NewStringValue(consumer.CreatedOn)
-			data.Environment = basetypes.NewStringValue(consumer.Environment)
-			data.QueueName = basetypes.NewStringValue(consumer.QueueName)
-			data.Settings = customfield.NewObjectMust(
-				ctx,
-				&QueueConsumerSettingsModel{
-					BatchSize:     basetypes.NewFloat64Value(consumer.Settings.BatchSize),
-					MaxRetries:    basetypes.NewFloat64Value(consumer.Settings.MaxRetries),
-					MaxWaitTimeMs: basetypes.NewFloat64Value(consumer.Settings.MaxWaitTimeMs),
-				})
+	// http_pull consumers have no script, so consumers are matched by ID
+	var consumer *QueueConsumerResponse
+	for i, c := range consumers {
+		if c.ConsumerID == data.ConsumerID.ValueString() {
+			consumer = &consumers[i]
+			break
 		}
 	}
 
-	if !found {
-		req.State.RemoveResource(ctx)
+	if consumer == nil {
+		resp.State.RemoveResource(ctx)
 		return
 	}
 
+	if consumer.Type != "" {
+		data.Type = types.StringValue(consumer.Type)
+	}
+	data.DeadLetterQueue = types.StringNull()
+	if consumer.DeadLetterQueue != "" {
+		data.DeadLetterQueue = types.StringValue(consumer.DeadLetterQueue)
+	}
+	// older API versions report the script name as `service`
+	data.ScriptName = types.StringNull()
+	if consumer.ScriptName != "" {
+		data.ScriptName = types.StringValue(consumer.ScriptName)
+	} else if consumer.Service != "" {
+		data.ScriptName = types.StringValue(consumer.Service)
+	}
+	data.CreatedOn = types.StringValue(consumer.CreatedOn)
+	data.Environment = types.StringValue(consumer.Environment)
+	data.QueueName = types.StringValue(consumer.QueueName)
+	data.Settings = customfield.NewObjectMust(
+		ctx,
+		&QueueConsumerSettingsModel{
+			BatchSize:           types.Float64PointerValue(consumer.Settings.BatchSize),
+			MaxConcurrency:      types.Float64PointerValue(consumer.Settings.MaxConcurrency),
+			MaxRetries:          types.Float64PointerValue(consumer.Settings.MaxRetries),
+			MaxWaitTimeMs:       types.Float64PointerValue(consumer.Settings.MaxWaitTimeMs),
+			RetryDelay:          types.Float64PointerValue(consumer.Settings.RetryDelay),
+			VisibilityTimeoutMs: types.Float64PointerValue(consumer.Settings.VisibilityTimeoutMs),
+		})
+
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
@@ -213,7 +251,7 @@ func (r *QueueConsumerResource) Delete(ctx context.Context, req resource.DeleteR
 		},
 		option.WithMiddleware(logging.Middleware(ctx)),
 	)
-	if err != nil {
+	if err != nil && !utils.IsNotFound(err, queueNotFoundErrorCode) {
 		resp.Diagnostics.AddError("failed to make http request", err.Error())
 		return
 	}
@@ -221,6 +259,109 @@ func (r *QueueConsumerResource) Delete(ctx context.Context, req resource.DeleteR
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
-func (r *QueueConsumerResource) ModifyPlan(_ context.Context, _ resource.ModifyPlanRequest, _ *resource.ModifyPlanResponse) {
+func (r *QueueConsumerResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	path_account_id := ""
+	path_queue_id := ""
+	path_consumer := ""
+	diags := importpath.ParseImportID(
+		req.ID,
+		"<account_id>/<queue_id>/<consumer_id|script_name>",
+		&path_account_id,
+		&path_queue_id,
+		&path_consumer,
+	)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	consumers, err := r.listConsumers(ctx, path_account_id, path_queue_id)
+	if err != nil {
+		resp.Diagnostics.AddError("failed to make http request", err.Error())
+		return
+	}
 
+	// consumers used to be imported by the name of their Worker script, which
+	// is still accepted for worker consumers
+	consumerID := ""
+	for _, c := range consumers {
+		if c.ConsumerID == path_consumer {
+			consumerID = c.ConsumerID
+			break
+		}
+	}
+	if consumerID == "" {
+		for _, c := range consumers {
+			if c.ScriptName == path_consumer || c.Service == path_consumer {
+				consumerID = c.ConsumerID
+				break
+			}
+		}
+	}
+	if consumerID == "" {
+		resp.Diagnostics.AddError(
+			"queue consumer not found",
+			fmt.Sprintf("Queue %s has no consumer with ID or script name %q.", path_queue_id, path_consumer),
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("account_id"), path_account_id)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("queue_id"), path_queue_id)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("consumer_id"), consumerID)...)
+}
+
+func (r *QueueConsumerResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	providerdata.ModifyPlanAccountID(ctx, r.defaultAccountID, req, resp)
+	if resp.Diagnostics.HasError() || req.Plan.Raw.IsNull() || r.client == nil {
+		return
+	}
+
+	var data *QueueConsumerModel
+	resp.Diagnostics.Append(resp.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() || data.AccountID.IsUnknown() {
+		return
+	}
+
+	create := req.State.Raw.IsNull()
+	state := &QueueConsumerModel{}
+	if !create {
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	// the checks only run when their inputs change, and values only known
+	// after apply are checked when applying
+	if known(data.DeadLetterQueue) && (create || !data.DeadLetterQueue.Equal(state.DeadLetterQueue) || !data.QueueID.Equal(state.QueueID)) {
+		resp.Diagnostics.Append(r.checkDeadLetterQueue(ctx, data, false)...)
+	}
+	if data.Type.ValueString() == "worker" && known(data.ScriptName) && (create || !data.ScriptName.Equal(state.ScriptName)) {
+		resp.Diagnostics.Append(r.checkScriptName(ctx, data)...)
+	}
+}
+
+// listConsumers returns the consumers of a queue, decoded from the raw
+// response as the typed SDK response omits their ID and type.
+func (r *QueueConsumerResource) listConsumers(ctx context.Context, accountID string, queueID string) ([]QueueConsumerResponse, error) {
+	res := new(http.Response)
+	_, err := r.client.Queues.Consumers.Get(
+		ctx,
+		queueID,
+		queues.ConsumerGetParams{
+			AccountID: cloudflare.F(accountID),
+		},
+		option.WithResponseBodyInto(&res),
+		option.WithMiddleware(logging.Middleware(ctx)),
+	)
+	if err != nil {
+		return nil, err
+	}
+	bytes, _ := io.ReadAll(res.Body)
+	var env QueueConsumersResultEnvelope
+	if err := json.Unmarshal(bytes, &env); err != nil {
+		return nil, fmt.Errorf("failed to deserialize http request: %w", err)
+	}
+	return env.Result, nil
 }
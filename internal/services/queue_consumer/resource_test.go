@@ -4,38 +4,49 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"regexp"
 	"testing"
 
 	"github.com/cloudflare/cloudflare-go/v3"
+	"github.com/cloudflare/cloudflare-go/v3/option"
 	"github.com/cloudflare/cloudflare-go/v3/queues"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
 	"github.com/hashicorp/terraform-plugin-testing/terraform"
 	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/acctest"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/services/queue_consumer"
 	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/utils"
 )
 
+func TestMain(m *testing.M) {
+	resource.TestMain(m)
+}
+
+func init() {
+	acctest.AddTestSweepers("cloudflare-extended_queue_consumer")
+}
+
 func TestAccCloudflareQueueConsumer_ScriptEnt(t *testing.T) {
-	t.Parallel()
+	acctest.Parallel(t)
 
-	rnd := utils.GenerateRandomResourceName()
+	rnd := acctest.RandomResourceName(t)
 	name := "cloudflare-extended_queue_consumer." + rnd
 	accountID := os.Getenv("CLOUDFLARE_ACCOUNT_ID")
 	queueID := os.Getenv("CLOUDFLARE_QUEUE_ID")
 	workerName := os.Getenv("CLOUDFLARE_WORKER_NAME")
 
-	if queueID == "" {
-		t.Fatal("CLOUDFLARE_QUEUE_ID must be set for this acceptance test")
-	} else if workerName == "" {
-		t.Fatal("CLOUDFLARE_WORKER_NAME must be set for this acceptance test")
-	}
-
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			acctest.TestAccPreCheck(t)
 			acctest.TestAccPreCheck_AccountID(t)
+			if queueID == "" {
+				t.Fatal("CLOUDFLARE_QUEUE_ID must be set for this acceptance test")
+			} else if workerName == "" {
+				t.Fatal("CLOUDFLARE_WORKER_NAME must be set for this acceptance test")
+			}
 		},
-		ProtoV6ProviderFactories: acctest.TestAccProtoV6ProviderFactories,
-		CheckDestroy:             testAccCheckCloudflareQueueConsumerDestroy,
+		ProtoV6ProviderFactories: acctest.TestAccProtoV6ProviderFactories(t),
+		CheckDestroy:             testAccCheckCloudflareQueueConsumerDestroy(acctest.Client(t)),
 		Steps: []resource.TestStep{
 			{
 				Config: testAccCheckCloudflareQueueConsumerConfigInitial(rnd, accountID, queueID, workerName),
@@ -53,6 +64,152 @@ func TestAccCloudflareQueueConsumer_ScriptEnt(t *testing.T) {
 	})
 }
 
+func TestCloudflareQueueConsumer_Offline(t *testing.T) {
+	srv := acctest.NewOfflineTest(t)
+	rnd := utils.GenerateRandomResourceName()
+	name := "cloudflare-extended_queue_consumer." + rnd
+	accountID := srv.AccountID
+	queueID := srv.CreateQueue(accountID, rnd)
+	provider := srv.ProviderConfig()
+	consumerID := ""
+
+	srv.Test(resource.TestCase{
+		Steps: []resource.TestStep{
+			{
+				Config: provider + testAccCheckCloudflareQueueConsumerConfigInitial(rnd, accountID, queueID, rnd),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "script_name", rnd),
+					resource.TestCheckResourceAttr(name, "queue_name", rnd),
+					resource.TestCheckResourceAttr(name, "settings.max_wait_time_ms", "5000"),
+					resource.TestCheckNoResourceAttr(name, "settings.max_concurrency"),
+					resource.TestCheckNoResourceAttr(name, "settings.visibility_timeout_ms"),
+					resource.TestCheckResourceAttrWith(name, "consumer_id", func(value string) error {
+						consumerID = value
+						return nil
+					}),
+				),
+			},
+			{
+				Config: provider + testAccCheckCloudflareQueueConsumerConfigSettings(rnd, accountID, queueID, rnd),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction(name, plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "settings.batch_size", "50"),
+					resource.TestCheckResourceAttr(name, "settings.max_concurrency", "5"),
+					resource.TestCheckResourceAttr(name, "settings.retry_delay", "10"),
+					resource.TestCheckResourceAttrWith(name, "consumer_id", func(value string) error {
+						if value != consumerID {
+							return fmt.Errorf("expected consumer %s to be updated in place, got %s", consumerID, value)
+						}
+						return nil
+					}),
+				),
+			},
+			{
+				ResourceName:                         name,
+				ImportState:                          true,
+				ImportStateIdFunc:                    testAccCheckCloudflareQueueConsumerImportID(name),
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "consumer_id",
+			},
+			{
+				// worker consumers can still be imported by script name
+				ResourceName:                         name,
+				ImportState:                          true,
+				ImportStateId:                        fmt.Sprintf("%s/%s/%s", accountID, queueID, rnd),
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "consumer_id",
+			},
+			{
+				ResourceName:  name,
+				ImportState:   true,
+				ImportStateId: fmt.Sprintf("%s/%s/%s", accountID, queueID, rnd+"-missing"),
+				ExpectError:   regexp.MustCompile(`has no consumer with ID or script name`),
+			},
+			{
+				Config:      provider + testAccCheckCloudflareQueueConsumerConfigInvalid(rnd, accountID, queueID, rnd),
+				ExpectError: regexp.MustCompile(`(?s)Consumer of type "http_pull" does not support.*"script_name"`),
+			},
+			{
+				Config: provider + testAccCheckCloudflareQueueConsumerConfigUpdate(rnd, accountID, queueID, rnd),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction(name, plancheck.ResourceActionReplace),
+					},
+				},
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "type", "http_pull"),
+					resource.TestCheckNoResourceAttr(name, "script_name"),
+					resource.TestCheckResourceAttr(name, "settings.batch_size", "100"),
+					resource.TestCheckResourceAttr(name, "settings.visibility_timeout_ms", "60000"),
+					resource.TestCheckNoResourceAttr(name, "settings.max_wait_time_ms"),
+					resource.TestCheckResourceAttrWith(name, "consumer_id", func(value string) error {
+						consumerID = value
+						return nil
+					}),
+				),
+			},
+			{
+				ResourceName:                         name,
+				ImportState:                          true,
+				ImportStateIdFunc:                    testAccCheckCloudflareQueueConsumerImportID(name),
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "consumer_id",
+			},
+			{
+				PreConfig: func() {
+					_, err := srv.APIClient().Queues.Consumers.Delete(
+						context.Background(),
+						queueID,
+						consumerID,
+						queues.ConsumerDeleteParams{AccountID: cloudflare.F(accountID)},
+					)
+					if err != nil {
+						t.Fatalf("failed to delete queue consumer out-of-band: %s", err)
+					}
+				},
+				Config:             provider + testAccCheckCloudflareQueueConsumerConfigUpdate(rnd, accountID, queueID, rnd),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestCloudflareQueueConsumer_OfflineDeadLetterQueue(t *testing.T) {
+	srv := acctest.NewOfflineTest(t)
+	rnd := utils.GenerateRandomResourceName()
+	name := "cloudflare-extended_queue_consumer." + rnd
+	accountID := srv.AccountID
+	queueID := srv.CreateQueue(accountID, rnd)
+	srv.CreateQueue(accountID, rnd+"-dlq")
+	provider := srv.ProviderConfig()
+
+	srv.Test(resource.TestCase{
+		Steps: []resource.TestStep{
+			{
+				Config:      provider + testAccCheckCloudflareQueueConsumerConfigDeadLetterQueue(rnd, accountID, queueID, rnd, rnd),
+				ExpectError: regexp.MustCompile(`cannot be the dead letter queue of its own\s+consumer`),
+			},
+			{
+				// a missing queue only warns when planning, as it may be
+				// created in the same apply
+				Config:      provider + testAccCheckCloudflareQueueConsumerConfigDeadLetterQueue(rnd, accountID, queueID, rnd, rnd+"-missing"),
+				ExpectError: regexp.MustCompile(`No queue named "` + rnd + `-missing"\s+exists`),
+			},
+			{
+				Config: provider + testAccCheckCloudflareQueueConsumerConfigDeadLetterQueue(rnd, accountID, queueID, rnd, rnd+"-dlq"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "dead_letter_queue", rnd+"-dlq"),
+				),
+			},
+		},
+	})
+}
+
 func testAccCheckCloudflareQueueConsumerConfigInitial(rnd, accountID, queueID, scriptName string) string {
 	return acctest.LoadTestCase("queueconsumerinitial.tf", rnd, accountID, queueID, scriptName)
 }
@@ -61,31 +218,58 @@ func testAccCheckCloudflareQueueConsumerConfigUpdate(rnd, accountID, queueID, sc
 	return acctest.LoadTestCase("queueconsumerupdate.tf", rnd, accountID, queueID, scriptName)
 }
 
-func testAccCheckCloudflareQueueConsumerDestroy(s *terraform.State) error {
-	accountID := os.Getenv("CLOUDFLARE_ACCOUNT_ID")
+func testAccCheckCloudflareQueueConsumerConfigSettings(rnd, accountID, queueID, scriptName string) string {
+	return acctest.LoadTestCase("queueconsumersettings.tf", rnd, accountID, queueID, scriptName)
+}
 
-	client := cloudflare.NewClient()
-	for _, rs := range s.RootModule().Resources {
-		if rs.Type != "cloudflare-extended_queue_consumer" {
-			continue
-		}
+func testAccCheckCloudflareQueueConsumerConfigDeadLetterQueue(rnd, accountID, queueID, scriptName, deadLetterQueue string) string {
+	return acctest.LoadTestCase("queueconsumerdeadletterqueue.tf", rnd, accountID, queueID, scriptName, deadLetterQueue)
+}
+
+func testAccCheckCloudflareQueueConsumerConfigInvalid(rnd, accountID, queueID, scriptName string) string {
+	return acctest.LoadTestCase("queueconsumerinvalid.tf", rnd, accountID, queueID, scriptName)
+}
 
-		consumers, err := client.Queues.Consumers.Get(
-			context.Background(),
-			rs.Primary.Attributes["queue_id"],
-			queues.ConsumerGetParams{
-				AccountID: cloudflare.F(accountID),
-			})
-		if err != nil {
-			return err
+func testAccCheckCloudflareQueueConsumerImportID(name string) resource.ImportStateIdFunc {
+	return func(s *terraform.State) (string, error) {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return "", fmt.Errorf("resource %s not found in state", name)
 		}
 
-		for _, consumer := range *consumers {
-			if consumer.Service == rs.Primary.Attributes["script_name"] {
-				return fmt.Errorf("queue consumer for service %s still exists", rs.Primary.Attributes["script_name"])
+		return fmt.Sprintf("%s/%s/%s", rs.Primary.Attributes["account_id"], rs.Primary.Attributes["queue_id"], rs.Primary.Attributes["consumer_id"]), nil
+	}
+}
+
+func testAccCheckCloudflareQueueConsumerDestroy(client *cloudflare.Client) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		accountID := os.Getenv("CLOUDFLARE_ACCOUNT_ID")
+
+		for _, rs := range s.RootModule().Resources {
+			if rs.Type != "cloudflare-extended_queue_consumer" {
+				continue
+			}
+
+			var env queue_consumer.QueueConsumersResultEnvelope
+			_, err := client.Queues.Consumers.Get(
+				context.Background(),
+				rs.Primary.Attributes["queue_id"],
+				queues.ConsumerGetParams{
+					AccountID: cloudflare.F(accountID),
+				},
+				option.WithResponseBodyInto(&env),
+			)
+			if err != nil {
+				return err
+			}
+
+			for _, consumer := range env.Result {
+				if consumer.ConsumerID == rs.Primary.Attributes["consumer_id"] {
+					return fmt.Errorf("queue consumer %s still exists", consumer.ConsumerID)
+				}
 			}
 		}
-	}
 
-	return nil
+		return nil
+	}
 }
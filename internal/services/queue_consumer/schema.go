@@ -18,9 +18,10 @@ func ResourceSchema(ctx context.Context) schema.Schema {
 	return schema.Schema{
 		Attributes: map[string]schema.Attribute{
 			"account_id": schema.StringAttribute{
-				Description:   "Identifier.",
-				Required:      true,
-				PlanModifiers: []planmodifier.String{stringplanmodifier.RequiresReplace()},
+				Description:   "Identifier. Defaults to the `account_id` of the provider.",
+				Optional:      true,
+				Computed:      true,
+				PlanModifiers: []planmodifier.String{stringplanmodifier.UseStateForUnknown(), stringplanmodifier.RequiresReplace()},
 			},
 			"queue_id": schema.StringAttribute{
 				Description:   "Identifier.",
@@ -28,26 +29,32 @@ func ResourceSchema(ctx context.Context) schema.Schema {
 				PlanModifiers: []planmodifier.String{stringplanmodifier.RequiresReplace()},
 			},
 			"consumer_id": schema.StringAttribute{
-				Description: "Identifier.",
-				Computed:    true,
+				Description:   "Identifier.",
+				Computed:      true,
+				PlanModifiers: []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
 			},
 			"type": schema.StringAttribute{
-				Description: `Type of queue consumer. One of "worker", or "http_pull"`,
-				Required:    true,
-				Validators:  []validator.String{stringvalidator.OneOf("worker", "http_pull")},
+				Description:   `Type of queue consumer. One of "worker", or "http_pull"`,
+				Required:      true,
+				Validators:    []validator.String{stringvalidator.OneOf(consumerSpecs.Types()...)},
+				PlanModifiers: []planmodifier.String{stringplanmodifier.RequiresReplace()},
 			},
 			"script_name": schema.StringAttribute{
-				Optional: true,
+				Description: "Name of the Worker script consuming the queue. Required for worker consumers.",
+				Optional:    true,
 			},
 			"created_on": schema.StringAttribute{
-				Computed: true,
+				Computed:      true,
+				PlanModifiers: []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
 			},
 			"dead_letter_queue": schema.StringAttribute{
-				Optional: true,
+				Description: "Name of the queue that receives the messages that run out of retries. Must be another queue of the account.",
+				Optional:    true,
 			},
 			"environment": schema.StringAttribute{
-				Optional: true,
-				Computed: true,
+				Description: "Environment of the Worker script, for worker consumers.",
+				Optional:    true,
+				Computed:    true,
 			},
 			"queue_name": schema.StringAttribute{
 				Computed: true,
@@ -61,14 +68,30 @@ func ResourceSchema(ctx context.Context) schema.Schema {
 						Computed: true,
 						Optional: true,
 					},
+					"max_concurrency": schema.Float64Attribute{
+						Description: "Maximum number of concurrent consumer invocations, for worker consumers. Scales automatically when unset.",
+						Computed:    true,
+						Optional:    true,
+					},
 					"max_retries": schema.Float64Attribute{
 						Description: "The maximum number of retries",
 						Computed:    true,
 						Optional:    true,
 					},
 					"max_wait_time_ms": schema.Float64Attribute{
-						Computed: true,
-						Optional: true,
+						Description: "Maximum time to wait for a batch to fill, for worker consumers.",
+						Computed:    true,
+						Optional:    true,
+					},
+					"retry_delay": schema.Float64Attribute{
+						Description: "Number of seconds to delay the retry of a message.",
+						Computed:    true,
+						Optional:    true,
+					},
+					"visibility_timeout_ms": schema.Float64Attribute{
+						Description: "Time a pulled message stays invisible to other pulls before it is redelivered, for http_pull consumers.",
+						Computed:    true,
+						Optional:    true,
 					},
 				},
 			},
@@ -81,5 +104,7 @@ func (r *QueueConsumerResource) Schema(ctx context.Context, req resource.SchemaR
 }
 
 func (r *QueueConsumerResource) ConfigValidators(_ context.Context) []resource.ConfigValidator {
-	return []resource.ConfigValidator{}
+	return []resource.ConfigValidator{
+		consumerTypeValidator{},
+	}
 }
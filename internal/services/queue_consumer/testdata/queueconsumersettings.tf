@@ -0,0 +1,12 @@
+resource "cloudflare-extended_queue_consumer" "%[1]s" {
+  account_id  = "%[2]s"
+  queue_id    = "%[3]s"
+  script_name = "%[4]s"
+  type        = "worker"
+
+  settings = {
+    batch_size      = 50
+    max_concurrency = 5
+    retry_delay     = 10
+  }
+}
@@ -4,6 +4,7 @@ resource "cloudflare-extended_queue_consumer" "%[1]s" {
   type        = "http_pull"
 
   settings = {
-    batch_size = 100
+    batch_size            = 100
+    visibility_timeout_ms = 60000
   }
 }
@@ -0,0 +1,65 @@
+package queue_consumer
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/typespec"
+)
+
+var _ resource.ConfigValidator = consumerTypeValidator{}
+
+// consumerSpecs lists the attributes every consumer type must and may set.
+var consumerSpecs = typespec.Specs{
+	"worker": {
+		Required: []string{"script_name"},
+		Optional: []string{"environment", "settings.max_concurrency", "settings.max_wait_time_ms"},
+	},
+	"http_pull": {
+		Optional: []string{"settings.visibility_timeout_ms"},
+	},
+}
+
+// consumerTypeValidator checks that a consumer sets the attributes its type
+// requires and none that only apply to the other consumer type.
+type consumerTypeValidator struct{}
+
+func (v consumerTypeValidator) Description(ctx context.Context) string {
+	return v.MarkdownDescription(ctx)
+}
+
+func (v consumerTypeValidator) MarkdownDescription(_ context.Context) string {
+	return "The consumer must set the attributes required by its `type`, and only those allowed for it."
+}
+
+func (v consumerTypeValidator) ValidateResource(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data *QueueConsumerModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() || data.Type.IsNull() || data.Type.IsUnknown() {
+		return
+	}
+
+	attributes := map[string]attr.Value{
+		"script_name": data.ScriptName,
+		"environment": data.Environment,
+	}
+	if !data.Settings.IsNull() && !data.Settings.IsUnknown() {
+		settings, diags := data.Settings.Value(ctx)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		attributes["settings.max_concurrency"] = settings.MaxConcurrency
+		attributes["settings.max_wait_time_ms"] = settings.MaxWaitTimeMs
+		attributes["settings.visibility_timeout_ms"] = settings.VisibilityTimeoutMs
+	}
+
+	resp.Diagnostics.Append(consumerSpecs.Validate(typespec.Object{
+		Kind:       "consumer",
+		Subject:    "Consumer",
+		Type:       data.Type.ValueString(),
+		Attributes: attributes,
+		Path:       typespec.AttributePath,
+	})...)
+}
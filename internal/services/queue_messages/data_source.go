@@ -0,0 +1,145 @@
+package queue_messages
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/cloudflare/cloudflare-go/v3"
+	"github.com/cloudflare/cloudflare-go/v3/option"
+	"github.com/cloudflare/cloudflare-go/v3/queues"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/apijson"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/consts"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/logging"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/providerdata"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSourceWithConfigure = (*QueueMessagesDataSource)(nil)
+
+func NewDataSource() datasource.DataSource {
+	return &QueueMessagesDataSource{}
+}
+
+// QueueMessagesDataSource defines the data source implementation.
+type QueueMessagesDataSource struct {
+	client           *cloudflare.Client
+	defaultAccountID string
+}
+
+func (d *QueueMessagesDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_queue_messages"
+}
+
+func (d *QueueMessagesDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*providerdata.Data)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"unexpected data source configure type",
+			fmt.Sprintf("Expected *providerdata.Data, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = data.Client
+	d.defaultAccountID = data.AccountID
+}
+
+func (d *QueueMessagesDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data *QueueMessagesModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if data.AccountID.IsNull() {
+		if d.defaultAccountID == "" {
+			resp.Diagnostics.AddAttributeError(
+				path.Root(consts.AccountIDSchemaKey),
+				"missing account_id",
+				fmt.Sprintf("Set `%s` on the data source, or on the provider or with the %s environment variable.", consts.AccountIDSchemaKey, consts.AccountIDEnvVarKey),
+			)
+			return
+		}
+		data.AccountID = types.StringValue(d.defaultAccountID)
+	}
+
+	dataBytes, err := data.MarshalJSON()
+	if err != nil {
+		resp.Diagnostics.AddError("failed to serialize http request", err.Error())
+		return
+	}
+	res := new(http.Response)
+	env := QueueMessagesResultEnvelope{*data}
+	_, err = d.client.Queues.Messages.Pull(
+		ctx,
+		data.QueueID.ValueString(),
+		queues.MessagePullParams{
+			AccountID: cloudflare.F(data.AccountID.ValueString()),
+		},
+		option.WithRequestBody("application/json", dataBytes),
+		option.WithResponseBodyInto(&res),
+		option.WithMiddleware(logging.Middleware(ctx)),
+	)
+	if err != nil {
+		resp.Diagnostics.AddError("failed to make http request", err.Error())
+		return
+	}
+	bytes, _ := io.ReadAll(res.Body)
+	err = apijson.UnmarshalComputed(bytes, &env)
+	if err != nil {
+		resp.Diagnostics.AddError("failed to deserialize http request", err.Error())
+		return
+	}
+	data = &env.Result
+
+	if data.Acknowledge.ValueBool() {
+		resp.Diagnostics.Append(d.acknowledge(ctx, data)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// acknowledge deletes the pulled messages from the queue by their lease IDs.
+func (d *QueueMessagesDataSource) acknowledge(ctx context.Context, data *QueueMessagesModel) diag.Diagnostics {
+	messages, diags := data.Messages.AsStructSliceT(ctx)
+	if diags.HasError() || len(messages) == 0 {
+		return diags
+	}
+
+	acks := make([]queues.MessageAckParamsAck, len(messages))
+	for i, message := range messages {
+		acks[i] = queues.MessageAckParamsAck{LeaseID: cloudflare.F(message.LeaseID.ValueString())}
+	}
+
+	_, err := d.client.Queues.Messages.Ack(
+		ctx,
+		data.QueueID.ValueString(),
+		queues.MessageAckParams{
+			AccountID: cloudflare.F(data.AccountID.ValueString()),
+			Acks:      cloudflare.F(acks),
+		},
+		option.WithMiddleware(logging.Middleware(ctx)),
+	)
+	if err != nil {
+		diags.AddError("failed to acknowledge queue messages", err.Error())
+	}
+
+	return diags
+}
@@ -0,0 +1,17 @@
+package queue_messages_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/services/queue_messages"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/test_helpers"
+)
+
+func TestQueueMessagesModelSchemaParity(t *testing.T) {
+	t.Parallel()
+	model := (*queue_messages.QueueMessagesModel)(nil)
+	schema := queue_messages.DataSourceSchema(context.TODO())
+	errs := test_helpers.ValidateDataSourceModelSchemaIntegrity(model, schema)
+	errs.Report(t)
+}
@@ -0,0 +1,103 @@
+package queue_messages_test
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/acctest"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/utils"
+)
+
+func TestMain(m *testing.M) {
+	resource.TestMain(m)
+}
+
+func init() {
+	acctest.AddTestSweepers("cloudflare-extended_queue")
+}
+
+func TestAccCloudflareQueueMessages_Empty(t *testing.T) {
+	acctest.Parallel(t)
+
+	rnd := acctest.RandomResourceName(t)
+	name := "data.cloudflare-extended_queue_messages." + rnd
+	accountID := os.Getenv("CLOUDFLARE_ACCOUNT_ID")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			acctest.TestAccPreCheck(t)
+			acctest.TestAccPreCheck_AccountID(t)
+		},
+		ProtoV6ProviderFactories: acctest.TestAccProtoV6ProviderFactories(t),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCloudflareQueueMessagesConfigEmpty(rnd, accountID),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "messages.#", "0"),
+					resource.TestCheckResourceAttr(name, "message_backlog_count", "0"),
+				),
+			},
+		},
+	})
+}
+
+func TestCloudflareQueueMessages_Offline(t *testing.T) {
+	srv := acctest.NewOfflineTest(t)
+	rnd := utils.GenerateRandomResourceName()
+	name := "data.cloudflare-extended_queue_messages." + rnd
+	accountID := srv.AccountID
+	queueID := srv.CreateQueue(accountID, rnd)
+	provider := srv.ProviderConfig()
+	messageID := srv.SendQueueMessage(accountID, queueID, "hello")
+
+	srv.Test(resource.TestCase{
+		Steps: []resource.TestStep{
+			{
+				// the short visibility timeout makes every read deliver the
+				// message again
+				Config: provider + testAccCloudflareQueueMessagesConfigPull(rnd, accountID, queueID),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "messages.#", "1"),
+					resource.TestCheckResourceAttr(name, "messages.0.id", messageID),
+					resource.TestCheckResourceAttr(name, "messages.0.body", "hello"),
+					resource.TestCheckResourceAttrSet(name, "messages.0.lease_id"),
+					resource.TestCheckResourceAttr(name, "message_backlog_count", "0"),
+					func(s *terraform.State) error {
+						if n := srv.QueueMessageCount(accountID, queueID); n != 1 {
+							return fmt.Errorf("expected the message to stay in the queue, got %d messages", n)
+						}
+						return nil
+					},
+				),
+			},
+			{
+				Config: provider + testAccCloudflareQueueMessagesConfigAcknowledge(rnd, accountID, queueID),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "messages.#", "1"),
+					resource.TestCheckResourceAttr(name, "messages.0.body", "hello"),
+					func(s *terraform.State) error {
+						if n := srv.QueueMessageCount(accountID, queueID); n != 0 {
+							return fmt.Errorf("expected the message to be acknowledged, got %d messages", n)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func testAccCloudflareQueueMessagesConfigEmpty(rnd, accountID string) string {
+	return acctest.LoadTestCase("queuemessagesempty.tf", rnd, accountID)
+}
+
+func testAccCloudflareQueueMessagesConfigPull(rnd, accountID, queueID string) string {
+	return acctest.LoadTestCase("queuemessagespull.tf", rnd, accountID, queueID)
+}
+
+func testAccCloudflareQueueMessagesConfigAcknowledge(rnd, accountID, queueID string) string {
+	return acctest.LoadTestCase("queuemessagesacknowledge.tf", rnd, accountID, queueID)
+}
@@ -0,0 +1,33 @@
+package queue_messages
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/apijson"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/customfield"
+)
+
+type QueueMessagesResultEnvelope struct {
+	Result QueueMessagesModel `json:"result"`
+}
+
+type QueueMessagesModel struct {
+	AccountID           types.String                                             `tfsdk:"account_id" path:"account_id,computed_optional"`
+	QueueID             types.String                                             `tfsdk:"queue_id" path:"queue_id,required"`
+	BatchSize           types.Float64                                            `tfsdk:"batch_size" json:"batch_size,optional"`
+	VisibilityTimeoutMs types.Float64                                            `tfsdk:"visibility_timeout_ms" json:"visibility_timeout_ms,optional"`
+	Acknowledge         types.Bool                                               `tfsdk:"acknowledge"`
+	MessageBacklogCount types.Float64                                            `tfsdk:"message_backlog_count" json:"message_backlog_count,computed"`
+	Messages            customfield.NestedObjectList[QueueMessagesMessagesModel] `tfsdk:"messages" json:"messages,computed"`
+}
+
+func (m QueueMessagesModel) MarshalJSON() (data []byte, err error) {
+	return apijson.MarshalRoot(m)
+}
+
+type QueueMessagesMessagesModel struct {
+	ID          types.String  `tfsdk:"id" json:"id,computed"`
+	Attempts    types.Float64 `tfsdk:"attempts" json:"attempts,computed"`
+	Body        types.String  `tfsdk:"body" json:"body,computed"`
+	LeaseID     types.String  `tfsdk:"lease_id" json:"lease_id,computed"`
+	TimestampMs types.Float64 `tfsdk:"timestamp_ms" json:"timestamp_ms,computed"`
+}
@@ -0,0 +1,78 @@
+package queue_messages
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/float64validator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/customfield"
+)
+
+func DataSourceSchema(ctx context.Context) schema.Schema {
+	return schema.Schema{
+		Description: "Pulls a batch of messages from a queue with an http_pull consumer. " +
+			"Every read leases the pulled messages, so they are not delivered again " +
+			"until their visibility timeout expires, or ever if `acknowledge` is set.",
+		Attributes: map[string]schema.Attribute{
+			"account_id": schema.StringAttribute{
+				Description: "Identifier. Defaults to the `account_id` of the provider.",
+				Optional:    true,
+				Computed:    true,
+			},
+			"queue_id": schema.StringAttribute{
+				Description: "Identifier.",
+				Required:    true,
+			},
+			"batch_size": schema.Float64Attribute{
+				Description: "The maximum number of messages to pull.",
+				Optional:    true,
+				Validators:  []validator.Float64{float64validator.Between(1, 100)},
+			},
+			"visibility_timeout_ms": schema.Float64Attribute{
+				Description: "The number of milliseconds the pulled messages are leased for.",
+				Optional:    true,
+				Validators:  []validator.Float64{float64validator.Between(1, 43200000)},
+			},
+			"acknowledge": schema.BoolAttribute{
+				Description: "Whether to acknowledge the pulled messages, deleting them from the queue.",
+				Optional:    true,
+			},
+			"message_backlog_count": schema.Float64Attribute{
+				Description: "Number of messages left in the queue that were not pulled.",
+				Computed:    true,
+			},
+			"messages": schema.ListNestedAttribute{
+				Computed:   true,
+				CustomType: customfield.NewNestedObjectListType[QueueMessagesMessagesModel](ctx),
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							Computed: true,
+						},
+						"attempts": schema.Float64Attribute{
+							Description: "Number of times the message was delivered, this pull included.",
+							Computed:    true,
+						},
+						"body": schema.StringAttribute{
+							Computed: true,
+						},
+						"lease_id": schema.StringAttribute{
+							Description: "Lease ID to acknowledge or retry the message with.",
+							Computed:    true,
+						},
+						"timestamp_ms": schema.Float64Attribute{
+							Description: "When the message was sent, in milliseconds since the Unix epoch.",
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *QueueMessagesDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = DataSourceSchema(ctx)
+}
@@ -0,0 +1,13 @@
+data "cloudflare-extended_queue_messages" "%[1]s" {
+  account_id  = "%[2]s"
+  queue_id    = "%[3]s"
+  batch_size  = 10
+  acknowledge = true
+}
+
+check "%[1]s" {
+  assert {
+    condition     = length(data.cloudflare-extended_queue_messages.%[1]s.messages) > 0
+    error_message = "no messages were delivered to the queue"
+  }
+}
@@ -0,0 +1,16 @@
+resource "cloudflare-extended_queue" "%[1]s" {
+  account_id = "%[2]s"
+  queue_name = "%[1]s"
+}
+
+resource "cloudflare-extended_queue_consumer" "%[1]s" {
+  account_id = "%[2]s"
+  queue_id   = cloudflare-extended_queue.%[1]s.queue_id
+  type       = "http_pull"
+}
+
+data "cloudflare-extended_queue_messages" "%[1]s" {
+  account_id  = "%[2]s"
+  queue_id    = cloudflare-extended_queue_consumer.%[1]s.queue_id
+  acknowledge = true
+}
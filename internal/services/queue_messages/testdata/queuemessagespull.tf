@@ -0,0 +1,6 @@
+data "cloudflare-extended_queue_messages" "%[1]s" {
+  account_id            = "%[2]s"
+  queue_id              = "%[3]s"
+  batch_size            = 10
+  visibility_timeout_ms = 1
+}
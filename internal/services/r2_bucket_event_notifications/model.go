@@ -0,0 +1,20 @@
+package r2_bucket_event_notifications
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/customfield"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/r2notifications"
+)
+
+type R2BucketEventNotificationsModel struct {
+	AccountID  types.String                                                      `tfsdk:"account_id" path:"account_id,computed_optional"`
+	BucketName types.String                                                      `tfsdk:"bucket_name" path:"bucket_name,required"`
+	Queues     customfield.NestedObjectMap[R2BucketEventNotificationsQueueModel] `tfsdk:"queues" path:"queues,required"`
+	Timeouts   timeouts.Value                                                    `tfsdk:"timeouts"`
+}
+
+type R2BucketEventNotificationsQueueModel struct {
+	QueueName types.String                                           `tfsdk:"queue_name" path:"queue_name,computed"`
+	Rules     customfield.NestedObjectSet[r2notifications.RuleModel] `tfsdk:"rules" path:"rules,required"`
+}
@@ -0,0 +1,323 @@
+package r2_bucket_event_notifications
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cloudflare/cloudflare-go/v3"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/customfield"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/importpath"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/providerdata"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/r2notifications"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/utils"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/waiter"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.ResourceWithConfigure = (*R2BucketEventNotificationsResource)(nil)
+var _ resource.ResourceWithModifyPlan = (*R2BucketEventNotificationsResource)(nil)
+var _ resource.ResourceWithImportState = (*R2BucketEventNotificationsResource)(nil)
+
+// API error code returned when the R2 bucket does not exist.
+const bucketNotFoundErrorCode = 10006
+
+func NewResource() resource.Resource {
+	return &R2BucketEventNotificationsResource{}
+}
+
+// R2BucketEventNotificationsResource defines the resource implementation.
+type R2BucketEventNotificationsResource struct {
+	client           *cloudflare.Client
+	defaultAccountID string
+}
+
+func (r *R2BucketEventNotificationsResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_r2_bucket_event_notifications"
+}
+
+func (r *R2BucketEventNotificationsResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*providerdata.Data)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"unexpected resource configure type",
+			fmt.Sprintf("Expected *providerdata.Data, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = data.Client
+	r.defaultAccountID = data.AccountID
+}
+
+func (r *R2BucketEventNotificationsResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data *R2BucketEventNotificationsModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	createTimeout, diags := data.Timeouts.Create(ctx, 5*time.Minute)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
+	// the bucket may already have notifications, which are taken over
+	r.reconcile(ctx, data, &resp.Diagnostics, &resp.State)
+}
+
+func (r *R2BucketEventNotificationsResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data *R2BucketEventNotificationsModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	config, err := r.bucket(data).Configuration(ctx)
+	if utils.IsNotFound(err, bucketNotFoundErrorCode) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("failed to make http request", err.Error())
+		return
+	}
+
+	// queues are keyed by their ID as declared, which may lack hyphens
+	var known map[string]bucketQueue
+	if !data.Queues.IsNull() && !data.Queues.IsUnknown() {
+		var diags diag.Diagnostics
+		known, diags = declaredQueues(ctx, data)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	// undeclared queues are read as well, so they show up as changes
+	current, diags := currentQueues(ctx, config, known)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(setQueues(ctx, data, current)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *R2BucketEventNotificationsResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data *R2BucketEventNotificationsModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	updateTimeout, diags := data.Timeouts.Update(ctx, 5*time.Minute)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
+
+	r.reconcile(ctx, data, &resp.Diagnostics, &resp.State)
+}
+
+func (r *R2BucketEventNotificationsResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data *R2BucketEventNotificationsModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	config, err := r.bucket(data).Configuration(ctx)
+	if utils.IsNotFound(err, bucketNotFoundErrorCode) {
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("failed to make http request", err.Error())
+		return
+	}
+
+	for _, queue := range config.Queues {
+		err := r.bucket(data).DeleteRules(ctx, r2notifications.NormalizeQueueID(queue.QueueID), nil)
+		if err != nil && !utils.IsNotFound(err, bucketNotFoundErrorCode) {
+			resp.Diagnostics.AddError("error deleting r2 event notifications", fmt.Sprintf("Queue %s: %s", queue.QueueID, err))
+			return
+		}
+	}
+}
+
+func (r *R2BucketEventNotificationsResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	path_account_id := ""
+	path_bucket_name := ""
+	diags := importpath.ParseImportID(
+		req.ID,
+		"<account_id>/<bucket_name>",
+		&path_account_id,
+		&path_bucket_name,
+	)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("account_id"), path_account_id)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("bucket_name"), path_bucket_name)...)
+}
+
+func (r *R2BucketEventNotificationsResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	providerdata.ModifyPlanAccountID(ctx, r.defaultAccountID, req, resp)
+}
+
+// reconcile makes the notification configuration of the bucket match the
+// declared queues, removing any queue or rule that is not declared, and waits
+// for the configuration to propagate.
+func (r *R2BucketEventNotificationsResource) reconcile(
+	ctx context.Context,
+	data *R2BucketEventNotificationsModel,
+	diagnostics *diag.Diagnostics,
+	state *tfsdk.State,
+) {
+	declared, diags := declaredQueues(ctx, data)
+	diagnostics.Append(diags...)
+	if diagnostics.HasError() {
+		return
+	}
+
+	config, err := r.bucket(data).Configuration(ctx)
+	if err != nil {
+		diagnostics.AddError("failed to make http request", err.Error())
+		return
+	}
+	current, diags := currentQueues(ctx, config, declared)
+	diagnostics.Append(diags...)
+	if diagnostics.HasError() {
+		return
+	}
+
+	changes := diffQueues(current, declared)
+	if len(changes) > 0 {
+		diags := r.bucket(data).Apply(ctx, changes)
+		diagnostics.Append(diags...)
+		if diags.HasError() {
+			// the rollback may have failed, so the state records what the
+			// bucket has now
+			r.setCurrentState(ctx, data, declared, diagnostics, state)
+			return
+		}
+		diagnostics.AddWarning("changed r2 bucket event notifications", r2notifications.DescribeChanges(changes))
+	}
+
+	conf := waiter.StateChangeConf{
+		Description: fmt.Sprintf("event notifications of r2 bucket %q", data.BucketName.ValueString()),
+		Pending:     []string{"pending"},
+		Target:      []string{"updated"},
+		Refresh: func(ctx context.Context) (any, string, error) {
+			config, err := r.bucket(data).Configuration(ctx)
+			if err != nil {
+				return nil, "", err
+			}
+			current, diags := currentQueues(ctx, config, declared)
+			if diags.HasError() {
+				return nil, "", fmt.Errorf("failed to convert rules: %v", diags.Errors())
+			}
+			if len(diffQueues(current, declared)) > 0 {
+				return nil, "pending", nil
+			}
+			return current, "updated", nil
+		},
+	}
+	result, err := conf.WaitForState(ctx)
+	if err != nil {
+		diagnostics.AddError("failed waiting for r2 bucket event notifications", err.Error())
+		return
+	}
+
+	// to set rule IDs from API
+	diagnostics.Append(setQueues(ctx, data, result.(map[string]bucketQueue))...)
+	if diagnostics.HasError() {
+		return
+	}
+
+	diagnostics.Append(state.Set(ctx, &data)...)
+}
+
+// setCurrentState sets the state to the configuration the bucket has, after
+// a failed update. Errors are reported as warnings, as the update already
+// failed.
+func (r *R2BucketEventNotificationsResource) setCurrentState(
+	ctx context.Context,
+	data *R2BucketEventNotificationsModel,
+	declared map[string]bucketQueue,
+	diagnostics *diag.Diagnostics,
+	state *tfsdk.State,
+) {
+	config, err := r.bucket(data).Configuration(ctx)
+	if err != nil {
+		diagnostics.AddWarning("failed to read r2 bucket event notifications", err.Error())
+		return
+	}
+	current, diags := currentQueues(ctx, config, declared)
+	if !diags.HasError() {
+		diags.Append(setQueues(ctx, data, current)...)
+	}
+	if !diags.HasError() {
+		diags.Append(state.Set(ctx, &data)...)
+	}
+	for _, d := range diags {
+		diagnostics.AddWarning(d.Summary(), d.Detail())
+	}
+}
+
+func (r *R2BucketEventNotificationsResource) bucket(data *R2BucketEventNotificationsModel) r2notifications.Bucket {
+	return r2notifications.Bucket{
+		Client:    r.client,
+		AccountID: data.AccountID.ValueString(),
+		Name:      data.BucketName.ValueString(),
+	}
+}
+
+// setQueues sets the queues of the model to the current configuration.
+func setQueues(ctx context.Context, data *R2BucketEventNotificationsModel, current map[string]bucketQueue) (diags diag.Diagnostics) {
+	queues := make(map[string]R2BucketEventNotificationsQueueModel, len(current))
+	for _, queue := range current {
+		rules, d := customfield.NewObjectSet(ctx, queue.rules)
+		diags.Append(d...)
+		if diags.HasError() {
+			return
+		}
+
+		queues[queue.queueID] = R2BucketEventNotificationsQueueModel{
+			QueueName: types.StringValue(queue.queueName),
+			Rules:     rules,
+		}
+	}
+
+	set, d := customfield.NewObjectMap(ctx, queues)
+	diags.Append(d...)
+	data.Queues = set
+	return
+}
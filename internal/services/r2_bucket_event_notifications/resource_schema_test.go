@@ -0,0 +1,17 @@
+package r2_bucket_event_notifications_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/services/r2_bucket_event_notifications"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/test_helpers"
+)
+
+func TestR2BucketEventNotificationsModelSchemaParity(t *testing.T) {
+	t.Parallel()
+	model := (*r2_bucket_event_notifications.R2BucketEventNotificationsModel)(nil)
+	schema := r2_bucket_event_notifications.ResourceSchema(context.TODO())
+	errs := test_helpers.ValidateResourceModelSchemaIntegrity(model, schema)
+	errs.Report(t)
+}
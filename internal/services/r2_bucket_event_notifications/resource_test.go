@@ -0,0 +1,259 @@
+package r2_bucket_event_notifications_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/cloudflare/cloudflare-go/v3"
+	"github.com/cloudflare/cloudflare-go/v3/event_notifications"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/acctest"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/utils"
+)
+
+func TestMain(m *testing.M) {
+	resource.TestMain(m)
+}
+
+func init() {
+	acctest.AddTestSweepers("cloudflare-extended_r2_bucket_event_notifications")
+}
+
+// Not parallel, as the resource takes over the whole configuration of the
+// R2_BUCKET_NAME fixture.
+func TestAccCloudflareR2BucketEventNotifications_Create(t *testing.T) {
+	rnd := acctest.RandomResourceName(t)
+	name := "cloudflare-extended_r2_bucket_event_notifications." + rnd
+	accountID := os.Getenv("CLOUDFLARE_ACCOUNT_ID")
+	bucketName := os.Getenv("R2_BUCKET_NAME")
+	queueID := os.Getenv("CLOUDFLARE_QUEUE_ID")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			acctest.TestAccPreCheck(t)
+			acctest.TestAccPreCheck_AccountID(t)
+		},
+		ProtoV6ProviderFactories: acctest.TestAccProtoV6ProviderFactories(t),
+		CheckDestroy:             testAccCheckCloudflareR2BucketEventNotificationsDestroy(acctest.Client(t)),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckCloudflareR2BucketEventNotificationsInitial(rnd, accountID, bucketName, queueID),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "bucket_name", bucketName),
+					resource.TestCheckResourceAttr(name, "queues.%", "1"),
+					resource.TestCheckResourceAttr(name, fmt.Sprintf("queues.%s.rules.#", queueID), "1"),
+				),
+			},
+		},
+	})
+}
+
+func TestCloudflareR2BucketEventNotifications_Offline(t *testing.T) {
+	srv := acctest.NewOfflineTest(t)
+	rnd := utils.GenerateRandomResourceName()
+	name := "cloudflare-extended_r2_bucket_event_notifications." + rnd
+	accountID := srv.AccountID
+	queueID := srv.CreateQueue(accountID, rnd)
+	otherQueueID := srv.CreateQueue(accountID, rnd+"-other")
+	strayQueueID := srv.CreateQueue(accountID, rnd+"-stray")
+	provider := srv.ProviderConfig()
+
+	addStrayRule := func() {
+		_, err := srv.APIClient().EventNotifications.R2.Configuration.Queues.Update(
+			context.Background(),
+			rnd,
+			strayQueueID,
+			event_notifications.R2ConfigurationQueueUpdateParams{
+				AccountID: cloudflare.F(accountID),
+				Rules: cloudflare.F([]event_notifications.R2ConfigurationQueueUpdateParamsRule{{
+					Actions: cloudflare.F([]event_notifications.R2ConfigurationQueueUpdateParamsRulesAction{"LifecycleDeletion"}),
+				}}),
+			},
+		)
+		if err != nil {
+			t.Fatalf("failed to add r2 event notification out-of-band: %s", err)
+		}
+	}
+	checkRuleCount := func(queueID string, want int) resource.TestCheckFunc {
+		return func(s *terraform.State) error {
+			if n := srv.R2NotificationRuleCount(accountID, rnd, queueID); n != want {
+				return fmt.Errorf("expected %d notification rules for queue %s, got %d", want, queueID, n)
+			}
+			return nil
+		}
+	}
+
+	factories, applied := acctest.TestAccProtoV6ProviderFactoriesWithDiagnostics(t)
+
+	srv.Test(resource.TestCase{
+		ProtoV6ProviderFactories: factories,
+		Steps: []resource.TestStep{
+			{
+				// notifications the bucket already has are removed
+				PreConfig: addStrayRule,
+				Config:    provider + testAccCheckCloudflareR2BucketEventNotificationsInitial(rnd, accountID, rnd, queueID),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "queues.%", "1"),
+					resource.TestCheckResourceAttr(name, fmt.Sprintf("queues.%s.queue_name", queueID), rnd),
+					resource.TestCheckResourceAttr(name, fmt.Sprintf("queues.%s.rules.#", queueID), "1"),
+					resource.TestCheckResourceAttrSet(name, fmt.Sprintf("queues.%s.rules.0.rule_id", queueID)),
+					checkRuleCount(strayQueueID, 0),
+					applied.TestCheckWarning(
+						"changed r2 bucket event notifications",
+						regexp.MustCompile(fmt.Sprintf(`queue %s: removed rule with prefix "", suffix "" and actions LifecycleDeletion`, strayQueueID)),
+					),
+				),
+			},
+			{
+				// the changed .png rule overlaps the one it replaces
+				Config: provider + testAccCheckCloudflareR2BucketEventNotificationsUpdate(rnd, accountID, rnd, queueID, otherQueueID),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "queues.%", "2"),
+					resource.TestCheckResourceAttr(name, fmt.Sprintf("queues.%s.rules.0.actions.#", queueID), "2"),
+					resource.TestCheckResourceAttr(name, fmt.Sprintf("queues.%s.rules.#", otherQueueID), "1"),
+					checkRuleCount(queueID, 1),
+					applied.TestCheckWarning(
+						"changed r2 bucket event notifications",
+						regexp.MustCompile(fmt.Sprintf(`queue %s: added rule with prefix "", suffix ".png" and actions DeleteObject`, otherQueueID)),
+					),
+				),
+			},
+			{
+				// the images/ rule overlaps the rule of the dropped queue
+				Config: provider + testAccCheckCloudflareR2BucketEventNotificationsUpdate2(rnd, accountID, rnd, queueID, otherQueueID),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "queues.%", "1"),
+					resource.TestCheckResourceAttr(name, fmt.Sprintf("queues.%s.rules.#", otherQueueID), "2"),
+					checkRuleCount(queueID, 0),
+					checkRuleCount(otherQueueID, 2),
+				),
+			},
+			{
+				ResourceName:                         name,
+				ImportState:                          true,
+				ImportStateId:                        fmt.Sprintf("%s/%s", accountID, rnd),
+				ImportStateVerify:                    true,
+				ImportStateVerifyIgnore:              []string{"timeouts"},
+				ImportStateVerifyIdentifierAttribute: "bucket_name",
+			},
+			{
+				PreConfig:          addStrayRule,
+				Config:             provider + testAccCheckCloudflareR2BucketEventNotificationsUpdate2(rnd, accountID, rnd, queueID, otherQueueID),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestCloudflareR2BucketEventNotifications_OfflineRollback(t *testing.T) {
+	srv := acctest.NewOfflineTest(t)
+	rnd := utils.GenerateRandomResourceName()
+	name := "cloudflare-extended_r2_bucket_event_notifications." + rnd
+	accountID := srv.AccountID
+	queueID := srv.CreateQueue(accountID, rnd)
+	otherQueueID := srv.CreateQueue(accountID, rnd+"-other")
+	provider := srv.ProviderConfig(`max_retries = 0`)
+
+	srv.Test(resource.TestCase{
+		Steps: []resource.TestStep{
+			{
+				Config: provider + testAccCheckCloudflareR2BucketEventNotificationsInitial(rnd, accountID, rnd, queueID),
+				Check:  resource.TestCheckResourceAttr(name, fmt.Sprintf("queues.%s.rules.#", queueID), "1"),
+			},
+			{
+				// the rule of the other queue is added before the superseded
+				// .png rule is deleted, so the failed delete has to remove it
+				// again
+				PreConfig: func() {
+					srv.FailNextRequestsWithMethod(http.MethodDelete, 1, http.StatusInternalServerError)
+				},
+				Config:      provider + testAccCheckCloudflareR2BucketEventNotificationsUpdate(rnd, accountID, rnd, queueID, otherQueueID),
+				ExpectError: regexp.MustCompile(`error deleting rules`),
+			},
+			{
+				PreConfig: func() {
+					if n := srv.R2NotificationRuleCount(accountID, rnd, queueID); n != 1 {
+						t.Fatalf("expected the rollback to leave 1 notification rule, got %d", n)
+					}
+					if n := srv.R2NotificationRuleCount(accountID, rnd, otherQueueID); n != 0 {
+						t.Fatalf("expected the rollback to remove the rule of the other queue, got %d", n)
+					}
+				},
+				Config:   provider + testAccCheckCloudflareR2BucketEventNotificationsInitial(rnd, accountID, rnd, queueID),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestCloudflareR2BucketEventNotifications_OfflineOverlappingRules(t *testing.T) {
+	srv := acctest.NewOfflineTest(t)
+	rnd := utils.GenerateRandomResourceName()
+	accountID := srv.AccountID
+	queueID := srv.CreateQueue(accountID, rnd)
+	otherQueueID := srv.CreateQueue(accountID, rnd+"-other")
+	provider := srv.ProviderConfig()
+
+	srv.Test(resource.TestCase{
+		Steps: []resource.TestStep{
+			{
+				Config: provider + testAccCheckCloudflareR2BucketEventNotificationsInvalid(rnd, accountID, rnd, queueID, otherQueueID),
+				// diagnostics follow the order of the random queue IDs
+				ExpectError: regexp.MustCompile(`(?s)Action "GetObject" of the rule.*is\s+not\s+supported.*overlapping r2 event notification rules|overlapping r2 event notification rules.*Action "GetObject" of the rule.*is\s+not\s+supported`),
+			},
+		},
+	})
+}
+
+func testAccCheckCloudflareR2BucketEventNotificationsInitial(rnd, accountID, bucketName, queueID string) string {
+	return acctest.LoadTestCase("r2bucketeventnotificationsinitial.tf", rnd, accountID, bucketName, queueID)
+}
+
+func testAccCheckCloudflareR2BucketEventNotificationsUpdate(rnd, accountID, bucketName, queueID, otherQueueID string) string {
+	return acctest.LoadTestCase("r2bucketeventnotificationsupdate.tf", rnd, accountID, bucketName, queueID, otherQueueID)
+}
+
+func testAccCheckCloudflareR2BucketEventNotificationsUpdate2(rnd, accountID, bucketName, queueID, otherQueueID string) string {
+	return acctest.LoadTestCase("r2bucketeventnotificationsupdate2.tf", rnd, accountID, bucketName, queueID, otherQueueID)
+}
+
+func testAccCheckCloudflareR2BucketEventNotificationsInvalid(rnd, accountID, bucketName, queueID, otherQueueID string) string {
+	return acctest.LoadTestCase("r2bucketeventnotificationsinvalid.tf", rnd, accountID, bucketName, queueID, otherQueueID)
+}
+
+func testAccCheckCloudflareR2BucketEventNotificationsDestroy(client *cloudflare.Client) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		accountID := os.Getenv("CLOUDFLARE_ACCOUNT_ID")
+
+		for _, rs := range s.RootModule().Resources {
+			if rs.Type != "cloudflare-extended_r2_bucket_event_notifications" {
+				continue
+			}
+
+			config, err := client.EventNotifications.R2.Configuration.Get(
+				context.Background(),
+				rs.Primary.Attributes["bucket_name"],
+				event_notifications.R2ConfigurationGetParams{
+					AccountID: cloudflare.F(accountID),
+				},
+			)
+			if err != nil {
+				continue
+			}
+
+			for _, queue := range config.Queues {
+				if len(queue.Rules) > 0 {
+					return fmt.Errorf("r2 bucket %s still has event notifications for queue %s", config.BucketName, queue.QueueID)
+				}
+			}
+		}
+
+		return nil
+	}
+}
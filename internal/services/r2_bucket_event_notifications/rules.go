@@ -0,0 +1,104 @@
+package r2_bucket_event_notifications
+
+import (
+	"context"
+	"sort"
+
+	"github.com/cloudflare/cloudflare-go/v3/event_notifications"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/r2notifications"
+)
+
+// bucketQueue is the notification configuration of one queue of a bucket.
+type bucketQueue struct {
+	// queueID is the ID the queue is declared with, or the one reported by
+	// the API for undeclared queues.
+	queueID   string
+	queueName string
+	rules     []r2notifications.RuleModel
+}
+
+// declaredQueues returns the queues of the model by their normalized ID.
+func declaredQueues(ctx context.Context, data *R2BucketEventNotificationsModel) (map[string]bucketQueue, diag.Diagnostics) {
+	queues, diags := data.Queues.AsStructMapT(ctx)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	declared := make(map[string]bucketQueue, len(queues))
+	for queueID, queue := range queues {
+		var rules []r2notifications.RuleModel
+		diags.Append(queue.Rules.ElementsAs(ctx, &rules, false)...)
+		if diags.HasError() {
+			return nil, diags
+		}
+		declared[r2notifications.NormalizeQueueID(queueID)] = bucketQueue{
+			queueID:   queueID,
+			queueName: queue.QueueName.ValueString(),
+			rules:     rules,
+		}
+	}
+
+	return declared, diags
+}
+
+// currentQueues returns the queues of the bucket configuration by their
+// normalized ID. Queues are keyed like in known, when they are in it.
+func currentQueues(ctx context.Context, config *event_notifications.R2ConfigurationGetResponse, known map[string]bucketQueue) (map[string]bucketQueue, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	current := make(map[string]bucketQueue, len(config.Queues))
+	for _, queue := range config.Queues {
+		id := r2notifications.NormalizeQueueID(queue.QueueID)
+		queueID := id
+		if k, ok := known[id]; ok {
+			queueID = k.queueID
+		}
+
+		rules, d := r2notifications.FromAPI(ctx, queue.Rules)
+		diags.Append(d...)
+		if diags.HasError() {
+			return nil, diags
+		}
+		if len(rules) == 0 {
+			continue
+		}
+
+		current[id] = bucketQueue{
+			queueID:   queueID,
+			queueName: queue.QueueName,
+			rules:     rules,
+		}
+	}
+
+	return current, diags
+}
+
+// diffQueues returns the changes that turn the current queues into the
+// declared ones, in the order of their normalized IDs.
+func diffQueues(current, declared map[string]bucketQueue) []r2notifications.QueueChanges {
+	ids := make([]string, 0, len(current)+len(declared))
+	for id := range current {
+		ids = append(ids, id)
+	}
+	for id := range declared {
+		if _, ok := current[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	queues := make([]r2notifications.QueueRules, len(ids))
+	for i, id := range ids {
+		queueID := current[id].queueID
+		if q, ok := declared[id]; ok {
+			queueID = q.queueID
+		}
+		queues[i] = r2notifications.QueueRules{
+			QueueID:  queueID,
+			Current:  current[id].rules,
+			Declared: declared[id].rules,
+		}
+	}
+
+	return r2notifications.Diff(queues)
+}
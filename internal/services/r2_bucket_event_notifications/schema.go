@@ -0,0 +1,98 @@
+package r2_bucket_event_notifications
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/customfield"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/r2notifications"
+)
+
+var _ resource.ResourceWithConfigValidators = (*R2BucketEventNotificationsResource)(nil)
+
+func ResourceSchema(ctx context.Context) schema.Schema {
+	return schema.Schema{
+		MarkdownDescription: "Manages the whole event notification configuration of an R2 bucket. Queues and rules of the bucket that are not declared are removed, so the bucket must not also be managed with `cloudflare-extended_r2_event_notification`.",
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Update: true,
+			}),
+		},
+		Attributes: map[string]schema.Attribute{
+			"account_id": schema.StringAttribute{
+				Description:   "Identifier. Defaults to the `account_id` of the provider.",
+				Optional:      true,
+				Computed:      true,
+				PlanModifiers: []planmodifier.String{stringplanmodifier.UseStateForUnknown(), stringplanmodifier.RequiresReplace()},
+			},
+			"bucket_name": schema.StringAttribute{
+				Description:   "Name of the R2 Bucket for the event notifications",
+				Required:      true,
+				PlanModifiers: []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"queues": schema.MapNestedAttribute{
+				Description: "Event notification rules of the bucket, by the ID of the queue they notify. Queues not listed get no notifications.",
+				CustomType:  customfield.NewNestedObjectMapType[R2BucketEventNotificationsQueueModel](ctx),
+				Required:    true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"queue_name": schema.StringAttribute{
+							Description: "Name of the queue.",
+							Computed:    true,
+						},
+						"rules": schema.SetNestedAttribute{
+							Description: "List of r2 event notification rules",
+							CustomType:  customfield.NewNestedObjectSetType[r2notifications.RuleModel](ctx),
+							Required:    true,
+							Validators:  []validator.Set{setvalidator.SizeAtLeast(1)},
+							NestedObject: schema.NestedAttributeObject{
+								Attributes: map[string]schema.Attribute{
+									"rule_id": schema.StringAttribute{
+										Description: "Identifier.",
+										Computed:    true,
+									},
+									"prefix": schema.StringAttribute{
+										Description: "Notifications will be sent only for objects with this prefix.",
+										Optional:    true,
+										Computed:    true,
+									},
+									"suffix": schema.StringAttribute{
+										Description: "Notifications will be sent only for objects with this suffix.",
+										Optional:    true,
+										Computed:    true,
+									},
+									"created_at": schema.StringAttribute{
+										Description: "Timestamp when the rule was created.",
+										Computed:    true,
+									},
+									"actions": schema.SetAttribute{
+										ElementType: types.StringType,
+										CustomType:  customfield.NewSetType[types.String](ctx),
+										Description: `Set of R2 object actions that will trigger notifications. Each one of "PutObject", "CopyObject", "DeleteObject", "CompleteMultipartUpload", or "LifecycleDeletion". Rules of a bucket may not match the same object for the same action.`,
+										Required:    true,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (r *R2BucketEventNotificationsResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = ResourceSchema(ctx)
+}
+
+func (r *R2BucketEventNotificationsResource) ConfigValidators(_ context.Context) []resource.ConfigValidator {
+	return []resource.ConfigValidator{rulesValidator{}}
+}
@@ -0,0 +1,15 @@
+resource "cloudflare-extended_r2_bucket_event_notifications" "%[1]s" {
+  account_id  = "%[2]s"
+  bucket_name = "%[3]s"
+
+  queues = {
+    "%[4]s" = {
+      rules = [
+        {
+          actions = ["PutObject"],
+          suffix  = ".png",
+        },
+      ]
+    }
+  }
+}
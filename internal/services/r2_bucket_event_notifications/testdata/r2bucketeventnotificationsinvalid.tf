@@ -0,0 +1,23 @@
+resource "cloudflare-extended_r2_bucket_event_notifications" "%[1]s" {
+  account_id  = "%[2]s"
+  bucket_name = "%[3]s"
+
+  queues = {
+    "%[4]s" = {
+      rules = [
+        {
+          actions = ["PutObject"],
+          suffix  = ".png",
+        },
+      ]
+    }
+    "%[5]s" = {
+      rules = [
+        {
+          actions = ["PutObject", "GetObject"],
+          prefix  = "images/",
+        },
+      ]
+    }
+  }
+}
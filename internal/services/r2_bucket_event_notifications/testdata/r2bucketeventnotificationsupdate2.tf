@@ -0,0 +1,19 @@
+resource "cloudflare-extended_r2_bucket_event_notifications" "%[1]s" {
+  account_id  = "%[2]s"
+  bucket_name = "%[3]s"
+
+  queues = {
+    "%[5]s" = {
+      rules = [
+        {
+          actions = ["DeleteObject"],
+          suffix  = ".png",
+        },
+        {
+          actions = ["PutObject"],
+          prefix  = "images/",
+        },
+      ]
+    }
+  }
+}
@@ -0,0 +1,90 @@
+package r2_bucket_event_notifications
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/r2notifications"
+)
+
+var _ resource.ConfigValidator = rulesValidator{}
+
+// rulesValidator checks that the rules only use supported actions and that no
+// two rules of the bucket overlap, which the API rejects when applying.
+type rulesValidator struct{}
+
+func (v rulesValidator) Description(ctx context.Context) string {
+	return v.MarkdownDescription(ctx)
+}
+
+func (v rulesValidator) MarkdownDescription(_ context.Context) string {
+	return "Rules may only use supported `actions`, and no two rules of the bucket may match the same object for the same action."
+}
+
+func (v rulesValidator) ValidateResource(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data *R2BucketEventNotificationsModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() || data.Queues.IsNull() || data.Queues.IsUnknown() {
+		return
+	}
+
+	queues, diags := data.Queues.AsStructMapT(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	queueIDs := make([]string, 0, len(queues))
+	for queueID := range queues {
+		queueIDs = append(queueIDs, queueID)
+	}
+	sort.Strings(queueIDs)
+
+	type queueRule struct {
+		queueID string
+		rule    r2notifications.RuleModel
+	}
+	var rules []queueRule
+	for _, queueID := range queueIDs {
+		queue := queues[queueID]
+		if queue.Rules.IsUnknown() {
+			continue
+		}
+
+		var queueRules []r2notifications.RuleModel
+		diags := queue.Rules.ElementsAs(ctx, &queueRules, false)
+		resp.Diagnostics.Append(diags...)
+		if diags.HasError() {
+			return
+		}
+
+		rulesPath := path.Root("queues").AtMapKey(queueID).AtName("rules")
+		for _, rule := range queueRules {
+			resp.Diagnostics.Append(r2notifications.CheckActions(rule, rulesPath)...)
+
+			if r2notifications.Known(rule) {
+				rules = append(rules, queueRule{queueID, rule})
+			}
+		}
+	}
+
+	// a set holds no duplicates, so any two rules of a queue are distinct
+	for i, a := range rules {
+		for _, b := range rules[i+1:] {
+			if !r2notifications.Overlap(a.rule, b.rule) {
+				continue
+			}
+			resp.Diagnostics.AddAttributeError(
+				path.Root("queues").AtMapKey(b.queueID).AtName("rules"),
+				"overlapping r2 event notification rules",
+				fmt.Sprintf(
+					"The %s of queue %s overlaps the %s of queue %s. Rules of a bucket may not match the same object for the same action.",
+					r2notifications.Describe(b.rule), b.queueID, r2notifications.Describe(a.rule), a.queueID,
+				),
+			)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package r2_event_notification
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/r2notifications"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/utils"
+)
+
+// checkBucketRules rejects new rules that overlap the rules other queues
+// already have on the bucket, which covers the other r2_event_notification
+// resources of the bucket that have been applied. Resources created in the
+// same apply are not visible to each other, so their overlaps are only
+// reported by the API.
+func (r *R2EventNotificationResource) checkBucketRules(ctx context.Context, data *R2EventNotificationModel, rules []r2notifications.RuleModel) (diags diag.Diagnostics) {
+	config, err := r.bucket(data).Configuration(ctx)
+	if utils.IsNotFound(err, bucketNotFoundErrorCode) {
+		// the bucket may be created in the same apply
+		return
+	}
+	if err != nil {
+		diags.AddError("failed to make http request", err.Error())
+		return
+	}
+
+	for _, queue := range config.Queues {
+		if r2notifications.NormalizeQueueID(queue.QueueID) == r2notifications.NormalizeQueueID(data.QueueID.ValueString()) {
+			continue
+		}
+
+		for _, existing := range queue.Rules {
+			existingModel := r2notifications.FromAPIRule(existing)
+			for _, rule := range rules {
+				if !r2notifications.Known(rule) || !r2notifications.Overlap(rule, existingModel) {
+					continue
+				}
+				diags.AddAttributeError(
+					path.Root("rules"),
+					"overlapping r2 event notification rules",
+					fmt.Sprintf(
+						"The %s overlaps the %s (rule %s of queue %q) on bucket %q. Rules of a bucket may not match the same object for the same action.",
+						r2notifications.Describe(rule),
+						r2notifications.Describe(existingModel),
+						existing.RuleID,
+						queue.QueueName,
+						data.BucketName.ValueString(),
+					),
+				)
+			}
+		}
+	}
+
+	return
+}
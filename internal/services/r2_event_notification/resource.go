@@ -2,25 +2,32 @@ package r2_event_notification
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"strings"
 	"time"
 
 	"github.com/cloudflare/cloudflare-go/v3"
 	"github.com/cloudflare/cloudflare-go/v3/event_notifications"
-	"github.com/cloudflare/cloudflare-go/v3/option"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/customfield"
-	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/logging"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/importpath"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/providerdata"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/r2notifications"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/utils"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/waiter"
 )
 
 // Ensure provider defined types fully satisfy framework interfaces.
 var _ resource.ResourceWithConfigure = (*R2EventNotificationResource)(nil)
 var _ resource.ResourceWithModifyPlan = (*R2EventNotificationResource)(nil)
+var _ resource.ResourceWithImportState = (*R2EventNotificationResource)(nil)
+
+// API error code returned when the R2 bucket does not exist.
+const bucketNotFoundErrorCode = 10006
 
 func NewResource() resource.Resource {
 	return &R2EventNotificationResource{}
@@ -28,7 +35,8 @@ func NewResource() resource.Resource {
 
 // R2EventNotificationResource defines the resource implementation.
 type R2EventNotificationResource struct {
-	client *cloudflare.Client
+	client           *cloudflare.Client
+	defaultAccountID string
 }
 
 func (r *R2EventNotificationResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -40,17 +48,18 @@ func (r *R2EventNotificationResource) Configure(ctx context.Context, req resourc
 		return
 	}
 
-	client, ok := req.ProviderData.(*cloudflare.Client)
+	data, ok := req.ProviderData.(*providerdata.Data)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"unexpected resource configure type",
-			fmt.Sprintf("Expected *cloudflare.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *providerdata.Data, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 
 		return
 	}
 
-	r.client = client
+	r.client = data.Client
+	r.defaultAccountID = data.AccountID
 }
 
 func (r *R2EventNotificationResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
@@ -69,14 +78,14 @@ func (r *R2EventNotificationResource) Create(ctx context.Context, req resource.C
 	ctx, cancel := context.WithTimeout(ctx, createTimeout)
 	defer cancel()
 
-	rules := make([]R2EventNotificationRuleModel, len(data.Rules.Elements()))
+	rules := make([]r2notifications.RuleModel, len(data.Rules.Elements()))
 	resp.Diagnostics.Append(data.Rules.ElementsAs(ctx, &rules, false)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	r.updateEventNotification(ctx, data, rules, &resp.Diagnostics)
-	if resp.Diagnostics.HasError() {
+	if err := r.bucket(data).AddRules(ctx, data.QueueID.ValueString(), rules); err != nil {
+		resp.Diagnostics.AddError("failed update r2 event notifications", err.Error())
 		return
 	}
 
@@ -91,13 +100,17 @@ func (r *R2EventNotificationResource) Read(ctx context.Context, req resource.Rea
 		return
 	}
 
-	queue, err := r.getQueue(ctx, data, &resp.Diagnostics)
+	queue, err := r.bucket(data).Queue(ctx, data.QueueID.ValueString())
+	if errors.Is(err, r2notifications.ErrQueueNotConfigured) || utils.IsNotFound(err, bucketNotFoundErrorCode) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
 	if err != nil {
 		resp.Diagnostics.AddError("could not read r2 event notification", err.Error())
 		return
 	}
 
-	apiRules, diags := convertToRuleModels(ctx, queue.Rules)
+	apiRules, diags := r2notifications.FromAPI(ctx, queue.Rules)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
@@ -137,50 +150,25 @@ func (r *R2EventNotificationResource) Update(ctx context.Context, req resource.U
 	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
 	defer cancel()
 
-	var stateRules []R2EventNotificationRuleModel
+	var stateRules []r2notifications.RuleModel
 	resp.Diagnostics.Append(state.Rules.ElementsAs(ctx, &stateRules, false)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	var newRules []R2EventNotificationRuleModel
+	var newRules []r2notifications.RuleModel
 	resp.Diagnostics.Append(data.Rules.ElementsAs(ctx, &newRules, false)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	ruleIDsToDelete, rulesToAdd := findRulesToDeleteAndAdd(stateRules, newRules)
-	if len(ruleIDsToDelete) > 0 {
-		reqBody := R2EventNotificationDeleteRequestBody{
-			RuleIds: ruleIDsToDelete,
-		}
-		jsonData, err := json.Marshal(reqBody)
-		if err != nil {
-			resp.Diagnostics.AddError("Error serializing delete request body", err.Error())
-			return
-		}
-
-		_, err = r.client.EventNotifications.R2.Configuration.Queues.Delete(
-			ctx,
-			data.BucketName.ValueString(),
-			data.QueueID.ValueString(),
-			event_notifications.R2ConfigurationQueueDeleteParams{
-				AccountID: cloudflare.F(data.AccountID.ValueString()),
-			},
-			option.WithMiddleware(logging.Middleware(ctx)),
-			option.WithRequestBody("application/json", jsonData),
-		)
-		if err != nil {
-			resp.Diagnostics.AddError("error deleting rules", err.Error())
-			return
-		}
-	}
-
-	if len(rulesToAdd) > 0 {
-		r.updateEventNotification(ctx, data, rulesToAdd, &resp.Diagnostics)
+	changes := diffRules(data, stateRules, newRules)
+	if len(changes) > 0 {
+		resp.Diagnostics.Append(r.bucket(data).Apply(ctx, changes)...)
 		if resp.Diagnostics.HasError() {
 			return
 		}
+		resp.Diagnostics.AddWarning("changed r2 event notification rules", r2notifications.DescribeChanges(changes))
 	}
 
 	r.verifyConfigurationUpdatedAndSetRuleIDs(ctx, data, &resp.Diagnostics, &resp.State)
@@ -194,16 +182,8 @@ func (r *R2EventNotificationResource) Delete(ctx context.Context, req resource.D
 		return
 	}
 
-	_, err := r.client.EventNotifications.R2.Configuration.Queues.Delete(
-		ctx,
-		data.BucketName.ValueString(),
-		data.QueueID.ValueString(),
-		event_notifications.R2ConfigurationQueueDeleteParams{
-			AccountID: cloudflare.F(data.AccountID.ValueString()),
-		},
-		option.WithMiddleware(logging.Middleware(ctx)),
-	)
-	if err != nil {
+	err := r.bucket(data).DeleteRules(ctx, data.QueueID.ValueString(), nil)
+	if err != nil && !utils.IsNotFound(err, bucketNotFoundErrorCode) {
 		resp.Diagnostics.AddError("error deleting r2 event notification", err.Error())
 		return
 	}
@@ -211,253 +191,159 @@ func (r *R2EventNotificationResource) Delete(ctx context.Context, req resource.D
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
-func (r *R2EventNotificationResource) ModifyPlan(_ context.Context, _ resource.ModifyPlanRequest, _ *resource.ModifyPlanResponse) {
+func (r *R2EventNotificationResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	path_account_id := ""
+	path_bucket_name := ""
+	path_queue_id := ""
+	diags := importpath.ParseImportID(
+		req.ID,
+		"<account_id>/<bucket_name>/<queue_id>",
+		&path_account_id,
+		&path_bucket_name,
+		&path_queue_id,
+	)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("account_id"), path_account_id)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("bucket_name"), path_bucket_name)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("queue_id"), path_queue_id)...)
 }
 
-func (r *R2EventNotificationResource) updateEventNotification(
-	ctx context.Context,
-	data *R2EventNotificationModel,
-	rules []R2EventNotificationRuleModel,
-	diagnostics *diag.Diagnostics,
-) {
-	updateParamsRules, diags := convertToUpdateParamsRules(ctx, rules)
-	diagnostics.Append(diags...)
-	if diagnostics.HasError() {
+func (r *R2EventNotificationResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	providerdata.ModifyPlanAccountID(ctx, r.defaultAccountID, req, resp)
+	if resp.Diagnostics.HasError() || req.Plan.Raw.IsNull() || r.client == nil {
 		return
 	}
 
-	_, err := r.client.EventNotifications.R2.Configuration.Queues.Update(
-		ctx,
-		data.BucketName.ValueString(),
-		data.QueueID.ValueString(),
-		event_notifications.R2ConfigurationQueueUpdateParams{
-			AccountID: cloudflare.F(data.AccountID.ValueString()),
-			Rules:     cloudflare.F(updateParamsRules),
-		},
-		option.WithMiddleware(logging.Middleware(ctx)),
-	)
-	if err != nil {
-		diagnostics.AddError("failed update r2 event notifications", err.Error())
+	var data *R2EventNotificationModel
+	resp.Diagnostics.Append(resp.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() || data.AccountID.IsUnknown() || data.BucketName.IsUnknown() || data.QueueID.IsUnknown() {
 		return
 	}
-}
 
-func (r *R2EventNotificationResource) verifyConfigurationUpdatedAndSetRuleIDs(
-	ctx context.Context,
-	data *R2EventNotificationModel,
-	diagnostics *diag.Diagnostics,
-	state *tfsdk.State,
-) {
-	interval := 10 * time.Second
-	for {
-		select {
-		case <-ctx.Done():
-			diagnostics.AddError(
-				"Timed out waiting for R2 event notification to become available",
-				"The resource did not become available within the allotted time",
-			)
-			return
-		default:
-			queue, err := r.getQueue(ctx, data, diagnostics)
-			if diagnostics.HasError() {
-				return
-			}
-
-			if err != nil {
-				time.Sleep(interval)
-				continue
-			}
+	// prefix and suffix are unknown in the plan when not configured, so the
+	// rules are checked as configured
+	var config *R2EventNotificationModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() || config.Rules.IsUnknown() {
+		return
+	}
+	var rules []r2notifications.RuleModel
+	resp.Diagnostics.Append(config.Rules.ElementsAs(ctx, &rules, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
-			rules, diags := convertToRuleModels(ctx, queue.Rules)
-			diagnostics.Append(diags...)
-			if diagnostics.HasError() {
-				return
-			}
+	// only new rules are checked, as applied rules would already have been
+	// rejected by the API
+	if !req.State.Raw.IsNull() {
+		var state *R2EventNotificationModel
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
 
-			var dataRules []R2EventNotificationRuleModel
-			diagnostics.Append(data.Rules.ElementsAs(ctx, &dataRules, false)...)
-			if diagnostics.HasError() {
+		if data.BucketName.Equal(state.BucketName) && data.QueueID.Equal(state.QueueID) {
+			var stateRules []r2notifications.RuleModel
+			resp.Diagnostics.Append(state.Rules.ElementsAs(ctx, &stateRules, false)...)
+			if resp.Diagnostics.HasError() {
 				return
 			}
-
-			if rulesSlicesEqual(rules, dataRules) {
-				// to set rule IDs from API
-				set, diags := customfield.NewObjectSet(ctx, rules)
-				diagnostics.Append(diags...)
-				if diagnostics.HasError() {
-					return
-				}
-				data.Rules = set
-				data.QueueName = types.StringValue(queue.QueueName)
-
-				diagnostics.Append(state.Set(ctx, &data)...)
-				return
+			changes := diffRules(data, stateRules, rules)
+			rules = nil
+			for _, c := range changes {
+				rules = append(rules, c.Added...)
+				rules = append(rules, c.Replacing...)
 			}
-
-			time.Sleep(interval)
 		}
 	}
-}
-
-func (r *R2EventNotificationResource) getQueue(
-	ctx context.Context,
-	data *R2EventNotificationModel,
-	diagnostics *diag.Diagnostics,
-) (*event_notifications.R2ConfigurationGetResponseQueue, error) {
-	eventNotifs, err := r.client.EventNotifications.R2.Configuration.Get(
-		ctx,
-		data.BucketName.ValueString(),
-		event_notifications.R2ConfigurationGetParams{
-			AccountID: cloudflare.F(data.AccountID.ValueString()),
-		},
-		option.WithMiddleware(logging.Middleware(ctx)),
-	)
-	if err != nil {
-		diagnostics.AddError("failed to get event notifications", err.Error())
-		return nil, err
-	}
 
-	for _, q := range eventNotifs.Queues {
-		// remove hyphens from QueueID to match the format used in data.QueueID
-		q.QueueID = strings.ReplaceAll(q.QueueID, "-", "")
-		if q.QueueID == strings.ReplaceAll(data.QueueID.ValueString(), "-", "") {
-			return &q, nil
-		}
+	if len(rules) > 0 {
+		resp.Diagnostics.Append(r.checkBucketRules(ctx, data, rules)...)
 	}
-
-	return nil, fmt.Errorf("could not find queue associated with event notification")
 }
 
-func convertToUpdateParamsRules(
+// verifyConfigurationUpdatedAndSetRuleIDs waits for the configuration of the
+// bucket to list the planned rules, which takes a while to propagate, and sets
+// the rule IDs the API assigned to them.
+func (r *R2EventNotificationResource) verifyConfigurationUpdatedAndSetRuleIDs(
 	ctx context.Context,
-	ruleModels []R2EventNotificationRuleModel,
-) ([]event_notifications.R2ConfigurationQueueUpdateParamsRule, diag.Diagnostics) {
-	var paramsRules []event_notifications.R2ConfigurationQueueUpdateParamsRule
-	var diags diag.Diagnostics
-	for _, rule := range ruleModels {
-		updateActions := make([]event_notifications.R2ConfigurationQueueUpdateParamsRulesAction, len(rule.Actions.Elements()))
-
-		actions := make([]string, len(rule.Actions.Elements()))
-		diags = append(diags, rule.Actions.ElementsAs(ctx, &actions, false)...)
-		if diags.HasError() {
-			return nil, diags
-		}
-
-		for i, action := range actions {
-			updateActions[i] = event_notifications.R2ConfigurationQueueUpdateParamsRulesAction(action)
-		}
-
-		paramsRules = append(
-			paramsRules,
-			event_notifications.R2ConfigurationQueueUpdateParamsRule{
-				Actions: cloudflare.F(updateActions),
-				Prefix:  cloudflare.F(rule.Prefix.ValueString()),
-				Suffix:  cloudflare.F(rule.Suffix.ValueString()),
-			},
-		)
+	data *R2EventNotificationModel,
+	diagnostics *diag.Diagnostics,
+	state *tfsdk.State,
+) {
+	var dataRules []r2notifications.RuleModel
+	diagnostics.Append(data.Rules.ElementsAs(ctx, &dataRules, false)...)
+	if diagnostics.HasError() {
+		return
 	}
 
-	return paramsRules, diags
-}
-
-func convertToRuleModels(
-	ctx context.Context,
-	apiRules []event_notifications.R2ConfigurationGetResponseQueuesRule,
-) ([]R2EventNotificationRuleModel, diag.Diagnostics) {
-	var models []R2EventNotificationRuleModel
-	var allDiags diag.Diagnostics
-	for _, rule := range apiRules {
-		actions := make([]types.String, len(rule.Actions))
-		for i, action := range rule.Actions {
-			actions[i] = types.StringValue(string(action))
-		}
-
-		actionSet, diags := customfield.NewSet[types.String](ctx, actions)
-		allDiags = append(allDiags, diags...)
-
-		models = append(
-			models,
-			R2EventNotificationRuleModel{
-				RuleID:    types.StringValue(rule.RuleID),
-				Suffix:    types.StringValue(rule.Suffix),
-				Prefix:    types.StringValue(rule.Prefix),
-				CreatedAt: types.StringValue(rule.CreatedAt),
-				Actions:   actionSet,
-			},
-		)
-	}
+	conf := waiter.StateChangeConf{
+		Description: "r2 event notification rules",
+		Pending:     []string{"pending"},
+		Target:      []string{"updated"},
+		MaxInterval: 10 * time.Second,
+		Refresh: func(ctx context.Context) (any, string, error) {
+			queue, err := r.bucket(data).Queue(ctx, data.QueueID.ValueString())
+			if errors.Is(err, r2notifications.ErrQueueNotConfigured) {
+				return nil, "pending", nil
+			}
+			if err != nil {
+				return nil, "", err
+			}
 
-	return models, allDiags
-}
+			rules, diags := r2notifications.FromAPI(ctx, queue.Rules)
+			if diags.HasError() {
+				return nil, "", fmt.Errorf("failed to convert rules: %v", diags.Errors())
+			}
+			if !r2notifications.SameRules(rules, dataRules) {
+				return nil, "pending", nil
+			}
 
-func findRulesToDeleteAndAdd(stateRules, newRules []R2EventNotificationRuleModel) ([]string, []R2EventNotificationRuleModel) {
-	stateRulesByID := make(map[string]R2EventNotificationRuleModel)
-	for _, rule := range stateRules {
-		stateRulesByID[rule.RuleID.ValueString()] = rule
+			return queue, "updated", nil
+		},
 	}
-
-	newRulesByID := make(map[string]R2EventNotificationRuleModel)
-	var dataRulesWithoutID []R2EventNotificationRuleModel
-	for _, rule := range newRules {
-		if !rule.RuleID.IsNull() && !rule.RuleID.IsUnknown() && rule.RuleID.ValueString() != "" {
-			newRulesByID[rule.RuleID.ValueString()] = rule
-		} else {
-			dataRulesWithoutID = append(dataRulesWithoutID, rule)
-		}
+	result, err := conf.WaitForState(ctx)
+	if err != nil {
+		diagnostics.AddError("failed waiting for r2 event notification rules", err.Error())
+		return
 	}
+	queue := result.(*event_notifications.R2ConfigurationGetResponseQueue)
 
-	var ruleIDsToDelete []string
-	for ruleID := range stateRulesByID {
-		if _, exists := newRulesByID[ruleID]; !exists {
-			// rule exists in state but not in data, mark for deletion
-			ruleIDsToDelete = append(ruleIDsToDelete, ruleID)
-		}
+	// to set rule IDs from API
+	rules, diags := r2notifications.FromAPI(ctx, queue.Rules)
+	diagnostics.Append(diags...)
+	if diagnostics.HasError() {
+		return
 	}
-
-	var rulesToUpdate []R2EventNotificationRuleModel
-	for ruleID, newRule := range newRulesByID {
-		if oldRule, exists := stateRulesByID[ruleID]; exists {
-			if !rulesEqual(oldRule, newRule) {
-				// content has changed, need to delete & re-add
-				ruleIDsToDelete = append(ruleIDsToDelete, ruleID)
-				rulesToUpdate = append(rulesToUpdate, newRule)
-			}
-		} else {
-			// RuleID exists in data but not in state, treat as new rule
-			rulesToUpdate = append(rulesToUpdate, newRule)
-		}
+	set, diags := customfield.NewObjectSet(ctx, rules)
+	diagnostics.Append(diags...)
+	if diagnostics.HasError() {
+		return
 	}
+	data.Rules = set
+	data.QueueName = types.StringValue(queue.QueueName)
 
-	rulesToAdd := append(dataRulesWithoutID, rulesToUpdate...)
-
-	return ruleIDsToDelete, rulesToAdd
+	diagnostics.Append(state.Set(ctx, &data)...)
 }
 
-func rulesSlicesEqual(a, b []R2EventNotificationRuleModel) bool {
-	if len(a) != len(b) {
-		return false
+func (r *R2EventNotificationResource) bucket(data *R2EventNotificationModel) r2notifications.Bucket {
+	return r2notifications.Bucket{
+		Client:    r.client,
+		AccountID: data.AccountID.ValueString(),
+		Name:      data.BucketName.ValueString(),
 	}
-
-	for _, ruleA := range a {
-		found := false
-		for _, ruleB := range b {
-			if rulesEqual(ruleA, ruleB) {
-				found = true
-				break
-			}
-		}
-
-		if !found {
-			return false
-		}
-	}
-
-	return true
 }
 
-func rulesEqual(a, b R2EventNotificationRuleModel) bool {
-	return a.Prefix.ValueString() == b.Prefix.ValueString() &&
-		a.Suffix.ValueString() == b.Suffix.ValueString() &&
-		a.Actions.Equal(b.Actions)
+// diffRules returns the changes that turn the rules of the state into the
+// planned ones, if any.
+func diffRules(data *R2EventNotificationModel, stateRules, planRules []r2notifications.RuleModel) []r2notifications.QueueChanges {
+	return r2notifications.Diff([]r2notifications.QueueRules{{
+		QueueID:  data.QueueID.ValueString(),
+		Current:  stateRules,
+		Declared: planRules,
+	}})
 }
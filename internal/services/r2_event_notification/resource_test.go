@@ -3,7 +3,9 @@ package r2_event_notification_test
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"os"
+	"regexp"
 	"testing"
 
 	"github.com/cloudflare/cloudflare-go/v3"
@@ -14,10 +16,18 @@ import (
 	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/utils"
 )
 
+func TestMain(m *testing.M) {
+	resource.TestMain(m)
+}
+
+func init() {
+	acctest.AddTestSweepers("cloudflare-extended_r2_event_notification")
+}
+
 func TestAccCloudflareR2EventNotificationInitial_Create(t *testing.T) {
-	t.Parallel()
+	acctest.Parallel(t)
 
-	rnd := utils.GenerateRandomResourceName()
+	rnd := acctest.RandomResourceName(t)
 	name := "cloudflare-extended_r2_event_notification." + rnd
 	accountID := os.Getenv("CLOUDFLARE_ACCOUNT_ID")
 	bucketName := os.Getenv("R2_BUCKET_NAME")
@@ -28,8 +38,8 @@ func TestAccCloudflareR2EventNotificationInitial_Create(t *testing.T) {
 			acctest.TestAccPreCheck(t)
 			acctest.TestAccPreCheck_AccountID(t)
 		},
-		ProtoV6ProviderFactories: acctest.TestAccProtoV6ProviderFactories,
-		CheckDestroy:             testAccCheckCloudflareR2EventNotificationDestroy,
+		ProtoV6ProviderFactories: acctest.TestAccProtoV6ProviderFactories(t),
+		CheckDestroy:             testAccCheckCloudflareR2EventNotificationDestroy(acctest.Client(t)),
 		Steps: []resource.TestStep{
 			{
 				Config: testAccCheckCloudflareR2EventNotificationInitial(rnd, accountID, bucketName, queueID),
@@ -56,6 +66,209 @@ func TestAccCloudflareR2EventNotificationInitial_Create(t *testing.T) {
 	})
 }
 
+func TestCloudflareR2EventNotification_Offline(t *testing.T) {
+	srv := acctest.NewOfflineTest(t)
+	rnd := utils.GenerateRandomResourceName()
+	name := "cloudflare-extended_r2_event_notification." + rnd
+	accountID := srv.AccountID
+	queueID := srv.CreateQueue(accountID, rnd)
+	provider := srv.ProviderConfig()
+
+	factories, applied := acctest.TestAccProtoV6ProviderFactoriesWithDiagnostics(t)
+
+	srv.Test(resource.TestCase{
+		ProtoV6ProviderFactories: factories,
+		Steps: []resource.TestStep{
+			{
+				Config: provider + testAccCheckCloudflareR2EventNotificationInitial(rnd, accountID, rnd, queueID),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "queue_name", rnd),
+					resource.TestCheckResourceAttr(name, "rules.#", "1"),
+				),
+			},
+			{
+				Config: provider + testAccCheckCloudflareR2EventNotificationUpdate(rnd, accountID, rnd, queueID),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "rules.#", "1"),
+					resource.TestCheckResourceAttr(name, "rules.0.suffix", ".png"),
+					applied.TestCheckWarning(
+						"changed r2 event notification rules",
+						regexp.MustCompile(`(?s)added rule with prefix "", suffix ".png".*removed rule with prefix ".jpeg", suffix ".png"`),
+					),
+				),
+			},
+			{
+				Config: provider + testAccCheckCloudflareR2EventNotificationUpdate2(rnd, accountID, rnd, queueID),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "rules.#", "2"),
+				),
+			},
+			{
+				ResourceName:                         name,
+				ImportState:                          true,
+				ImportStateId:                        fmt.Sprintf("%s/%s/%s", accountID, rnd, queueID),
+				ImportStateVerify:                    true,
+				ImportStateVerifyIgnore:              []string{"description", "timeouts"},
+				ImportStateVerifyIdentifierAttribute: "queue_id",
+			},
+			{
+				PreConfig: func() {
+					_, err := srv.APIClient().EventNotifications.R2.Configuration.Queues.Delete(
+						context.Background(),
+						rnd,
+						queueID,
+						event_notifications.R2ConfigurationQueueDeleteParams{AccountID: cloudflare.F(accountID)},
+					)
+					if err != nil {
+						t.Fatalf("failed to delete r2 event notification out-of-band: %s", err)
+					}
+				},
+				Config:             provider + testAccCheckCloudflareR2EventNotificationUpdate2(rnd, accountID, rnd, queueID),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestCloudflareR2EventNotification_OfflineRetry(t *testing.T) {
+	srv := acctest.NewOfflineTest(t)
+	rnd := utils.GenerateRandomResourceName()
+	name := "cloudflare-extended_r2_event_notification." + rnd
+	accountID := srv.AccountID
+	queueID := srv.CreateQueue(accountID, rnd)
+	provider := srv.ProviderConfig(`max_retries = 2`, `min_backoff = 1`, `max_backoff = 1`)
+
+	srv.Test(resource.TestCase{
+		Steps: []resource.TestStep{
+			{
+				PreConfig: func() {
+					srv.FailNextRequests(1, http.StatusTooManyRequests)
+					srv.FailNextRequests(1, http.StatusServiceUnavailable)
+				},
+				Config: provider + testAccCheckCloudflareR2EventNotificationInitial(rnd, accountID, rnd, queueID),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "rules.#", "1"),
+					func(s *terraform.State) error {
+						if n := srv.PendingFailures(); n != 0 {
+							return fmt.Errorf("%d injected failures were not retried", n)
+						}
+						return nil
+					},
+				),
+			},
+			{
+				// more failures than retries surface as an error
+				PreConfig: func() {
+					srv.FailNextRequests(3, http.StatusInternalServerError)
+				},
+				Config:      provider + testAccCheckCloudflareR2EventNotificationUpdate(rnd, accountID, rnd, queueID),
+				ExpectError: regexp.MustCompile(`500 Internal Server Error`),
+			},
+		},
+	})
+}
+
+func TestCloudflareR2EventNotification_OfflineRollback(t *testing.T) {
+	srv := acctest.NewOfflineTest(t)
+	rnd := utils.GenerateRandomResourceName()
+	name := "cloudflare-extended_r2_event_notification." + rnd
+	accountID := srv.AccountID
+	queueID := srv.CreateQueue(accountID, rnd)
+	provider := srv.ProviderConfig(`max_retries = 0`)
+
+	srv.Test(resource.TestCase{
+		Steps: []resource.TestStep{
+			{
+				Config: provider + testAccCheckCloudflareR2EventNotificationUpdate(rnd, accountID, rnd, queueID),
+				Check:  resource.TestCheckResourceAttr(name, "rules.#", "1"),
+			},
+			{
+				// the new .pdf rule is added before the superseded .png rule is
+				// deleted, so the failed delete has to remove it again
+				PreConfig: func() {
+					srv.FailNextRequestsWithMethod(http.MethodDelete, 1, http.StatusInternalServerError)
+				},
+				Config:      provider + testAccCheckCloudflareR2EventNotificationUpdate2(rnd, accountID, rnd, queueID),
+				ExpectError: regexp.MustCompile(`error deleting rules`),
+			},
+			{
+				PreConfig: func() {
+					if n := srv.R2NotificationRuleCount(accountID, rnd, queueID); n != 1 {
+						t.Fatalf("expected the rollback to leave 1 notification rule, got %d", n)
+					}
+				},
+				Config:   provider + testAccCheckCloudflareR2EventNotificationUpdate(rnd, accountID, rnd, queueID),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestCloudflareR2EventNotification_OfflineOverlappingRules(t *testing.T) {
+	srv := acctest.NewOfflineTest(t)
+	rnd := utils.GenerateRandomResourceName()
+	accountID := srv.AccountID
+	queueID := srv.CreateQueue(accountID, rnd)
+	otherQueueID := srv.CreateQueue(accountID, rnd+"-other")
+	provider := srv.ProviderConfig()
+
+	srv.Test(resource.TestCase{
+		Steps: []resource.TestStep{
+			{
+				Config:      provider + testAccCheckCloudflareR2EventNotificationInvalid(rnd, accountID, rnd, queueID),
+				ExpectError: regexp.MustCompile(`(?s)Action "GetObject" of the rule.*is\s+not\s+supported.*overlapping r2 event notification rules`),
+			},
+			{
+				Config: provider + testAccCheckCloudflareR2EventNotificationUpdate(rnd, accountID, rnd, queueID),
+			},
+			{
+				// rules of other queues on the bucket are checked when planning
+				Config:      provider + testAccCheckCloudflareR2EventNotificationOtherQueue(rnd, accountID, rnd, queueID, otherQueueID),
+				ExpectError: regexp.MustCompile(`(?s)prefix\s+"images/".*overlaps\s+the\s+rule\s+with\s+prefix\s+"",\s+suffix\s+".png".*of\s+queue\s+"` + rnd + `"`),
+				PreConfig: func() {
+					if n := srv.R2NotificationRuleCount(accountID, rnd, otherQueueID); n != 0 {
+						t.Fatalf("expected no rules for the other queue, got %d", n)
+					}
+				},
+			},
+		},
+	})
+}
+
+func TestCloudflareR2EventNotification_OfflinePropagation(t *testing.T) {
+	srv := acctest.NewOfflineTest(t)
+	rnd := utils.GenerateRandomResourceName()
+	name := "cloudflare-extended_r2_event_notification." + rnd
+	accountID := srv.AccountID
+	queueID := srv.CreateQueue(accountID, rnd)
+	provider := srv.ProviderConfig()
+
+	srv.Test(resource.TestCase{
+		Steps: []resource.TestStep{
+			{
+				// new rules only show up after a few reads of the configuration
+				PreConfig: func() {
+					srv.DelayR2NotificationRules(2)
+				},
+				Config: provider + testAccCheckCloudflareR2EventNotificationInitial(rnd, accountID, rnd, queueID),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "rules.#", "1"),
+					resource.TestCheckResourceAttrSet(name, "rules.0.rule_id"),
+				),
+			},
+			{
+				Config: provider + testAccCheckCloudflareR2EventNotificationUpdate2(rnd, accountID, rnd, queueID),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "rules.#", "2"),
+					resource.TestCheckResourceAttrSet(name, "rules.0.rule_id"),
+					resource.TestCheckResourceAttrSet(name, "rules.1.rule_id"),
+				),
+			},
+		},
+	})
+}
+
 func testAccCheckCloudflareR2EventNotificationInitial(rnd, accountID, bucketName, queueID string) string {
 	return acctest.LoadTestCase("r2eventnotificationinitial.tf", rnd, accountID, bucketName, queueID)
 }
@@ -68,27 +281,36 @@ func testAccCheckCloudflareR2EventNotificationUpdate2(rnd, accountID, bucketName
 	return acctest.LoadTestCase("r2eventnotificationupdate2.tf", rnd, accountID, bucketName, queueID)
 }
 
-func testAccCheckCloudflareR2EventNotificationDestroy(s *terraform.State) error {
-	accountID := os.Getenv("CLOUDFLARE_ACCOUNT_ID")
+func testAccCheckCloudflareR2EventNotificationInvalid(rnd, accountID, bucketName, queueID string) string {
+	return acctest.LoadTestCase("r2eventnotificationinvalid.tf", rnd, accountID, bucketName, queueID)
+}
 
-	for _, rs := range s.RootModule().Resources {
-		if rs.Type != "cloudflare-extended_r2_event_notification" {
-			continue
-		}
+func testAccCheckCloudflareR2EventNotificationOtherQueue(rnd, accountID, bucketName, queueID, otherQueueID string) string {
+	return acctest.LoadTestCase("r2eventnotificationotherqueue.tf", rnd, accountID, bucketName, queueID, otherQueueID)
+}
 
-		client := acctest.SharedClient()
-		event_notifications, _ := client.EventNotifications.R2.Configuration.Get(
-			context.Background(),
-			rs.Primary.ID,
-			event_notifications.R2ConfigurationGetParams{
-				AccountID: cloudflare.F(accountID),
-			},
-		)
+func testAccCheckCloudflareR2EventNotificationDestroy(client *cloudflare.Client) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		accountID := os.Getenv("CLOUDFLARE_ACCOUNT_ID")
+
+		for _, rs := range s.RootModule().Resources {
+			if rs.Type != "cloudflare-extended_r2_event_notification" {
+				continue
+			}
 
-		if event_notifications != nil {
-			return fmt.Errorf("r2 event notification with id %s still exists", rs.Primary.ID)
+			event_notifications, _ := client.EventNotifications.R2.Configuration.Get(
+				context.Background(),
+				rs.Primary.ID,
+				event_notifications.R2ConfigurationGetParams{
+					AccountID: cloudflare.F(accountID),
+				},
+			)
+
+			if event_notifications != nil {
+				return fmt.Errorf("r2 event notification with id %s still exists", rs.Primary.ID)
+			}
 		}
-	}
 
-	return nil
+		return nil
+	}
 }
@@ -4,15 +4,13 @@ import (
 	"context"
 
 	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
-	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
-	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
-	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/customfield"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/r2notifications"
 )
 
 var _ resource.ResourceWithConfigValidators = (*R2EventNotificationResource)(nil)
@@ -27,9 +25,10 @@ func ResourceSchema(ctx context.Context) schema.Schema {
 		},
 		Attributes: map[string]schema.Attribute{
 			"account_id": schema.StringAttribute{
-				Description:   "Identifier.",
-				Required:      true,
-				PlanModifiers: []planmodifier.String{stringplanmodifier.RequiresReplace()},
+				Description:   "Identifier. Defaults to the `account_id` of the provider.",
+				Optional:      true,
+				Computed:      true,
+				PlanModifiers: []planmodifier.String{stringplanmodifier.UseStateForUnknown(), stringplanmodifier.RequiresReplace()},
 			},
 			"bucket_name": schema.StringAttribute{
 				Description:   "Name of the R2 Bucket for the event notification",
@@ -51,7 +50,7 @@ func ResourceSchema(ctx context.Context) schema.Schema {
 			},
 			"rules": schema.SetNestedAttribute{
 				Description: "List of r2 event notification rules",
-				CustomType:  customfield.NewNestedObjectSetType[R2EventNotificationRuleModel](ctx),
+				CustomType:  customfield.NewNestedObjectSetType[r2notifications.RuleModel](ctx),
 				Required:    true,
 				NestedObject: schema.NestedAttributeObject{
 					Attributes: map[string]schema.Attribute{
@@ -76,13 +75,8 @@ func ResourceSchema(ctx context.Context) schema.Schema {
 						"actions": schema.SetAttribute{
 							ElementType: types.StringType,
 							CustomType:  customfield.NewSetType[types.String](ctx),
-							Description: "Set of R2 object actions that will trigger notifications",
+							Description: `Set of R2 object actions that will trigger notifications. Each one of "PutObject", "CopyObject", "DeleteObject", "CompleteMultipartUpload", or "LifecycleDeletion". Rules of a bucket may not match the same object for the same action.`,
 							Required:    true,
-							Validators: []validator.Set{
-								setvalidator.ValueStringsAre(
-									stringvalidator.OneOf([]string{"PutObject", "CopyObject", "DeleteObject", "CompleteMultipartUpload", "LifecycleDeletion"}...),
-								),
-							},
 						},
 					},
 				},
@@ -96,5 +90,5 @@ func (r *R2EventNotificationResource) Schema(ctx context.Context, req resource.S
 }
 
 func (r *R2EventNotificationResource) ConfigValidators(_ context.Context) []resource.ConfigValidator {
-	return []resource.ConfigValidator{}
+	return []resource.ConfigValidator{rulesValidator{}}
 }
@@ -0,0 +1,29 @@
+resource "cloudflare-extended_r2_event_notification" "%[1]s" {
+  account_id = "%[2]s"
+  bucket_name = "%[3]s"
+  queue_id     = "%[4]s"
+
+  rules = [
+    {
+      actions = ["PutObject"],
+      suffix = ".png",
+    },
+  ]
+}
+
+resource "cloudflare-extended_r2_event_notification" "%[1]s_other" {
+  account_id = "%[2]s"
+  bucket_name = "%[3]s"
+  queue_id     = "%[5]s"
+
+  rules = [
+    {
+      actions = ["DeleteObject"],
+      suffix = ".png",
+    },
+    {
+      actions = ["PutObject", "CopyObject"],
+      prefix = "images/",
+    },
+  ]
+}
@@ -0,0 +1,58 @@
+package r2_event_notification
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/r2notifications"
+)
+
+var _ resource.ConfigValidator = rulesValidator{}
+
+// rulesValidator checks that the rules only use supported actions and that no
+// two rules overlap, which the API rejects when applying.
+type rulesValidator struct{}
+
+func (v rulesValidator) Description(ctx context.Context) string {
+	return v.MarkdownDescription(ctx)
+}
+
+func (v rulesValidator) MarkdownDescription(_ context.Context) string {
+	return "Rules may only use supported `actions`, and no two rules may match the same object for the same action."
+}
+
+func (v rulesValidator) ValidateResource(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data *R2EventNotificationModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() || data.Rules.IsNull() || data.Rules.IsUnknown() {
+		return
+	}
+
+	var rules []r2notifications.RuleModel
+	resp.Diagnostics.Append(data.Rules.ElementsAs(ctx, &rules, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	for _, rule := range rules {
+		resp.Diagnostics.Append(r2notifications.CheckActions(rule, path.Root("rules"))...)
+	}
+
+	// a set holds no duplicates, so any two rules are distinct
+	for i, a := range rules {
+		if !r2notifications.Known(a) {
+			continue
+		}
+		for _, b := range rules[i+1:] {
+			if r2notifications.Known(b) && r2notifications.Overlap(a, b) {
+				resp.Diagnostics.AddAttributeError(
+					path.Root("rules"),
+					"overlapping r2 event notification rules",
+					fmt.Sprintf("The %s overlaps the %s. Rules of a bucket may not match the same object for the same action.", r2notifications.Describe(a), r2notifications.Describe(b)),
+				)
+			}
+		}
+	}
+}
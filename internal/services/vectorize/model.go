@@ -8,7 +8,7 @@ import (
 
 type VectorizeModel struct {
 	ID              types.String                           `tfsdk:"id"`
-	AccountID       types.String                           `tfsdk:"account_id" path:"account_id,required"`
+	AccountID       types.String                           `tfsdk:"account_id" path:"account_id,computed_optional"`
 	Name            types.String                           `tfsdk:"name" path:"name,required"`
 	Dimensions      types.Int64                            `tfsdk:"dimensions" path:"dimensions,required"`
 	Metric          types.String                           `tfsdk:"metric" path:"metric,required"`
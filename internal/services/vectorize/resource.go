@@ -3,18 +3,33 @@ package vectorize
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/cloudflare/cloudflare-go/v3"
 	"github.com/cloudflare/cloudflare-go/v3/option"
 	"github.com/cloudflare/cloudflare-go/v3/vectorize"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/customfield"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/importpath"
 	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/logging"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/providerdata"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/utils"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/waiter"
 )
 
 // Ensure provider defined types fully satisfy framework interfaces.
 var _ resource.ResourceWithConfigure = (*VectorizeResource)(nil)
 var _ resource.ResourceWithModifyPlan = (*VectorizeResource)(nil)
+var _ resource.ResourceWithImportState = (*VectorizeResource)(nil)
+
+// API error code returned when a Vectorize index does not exist.
+const indexNotFoundErrorCode = 3000
+
+// How long a new Vectorize index may take to become ready.
+const indexReadyTimeout = 2 * time.Minute
 
 func NewResource() resource.Resource {
 	return &VectorizeResource{}
@@ -22,7 +37,8 @@ func NewResource() resource.Resource {
 
 // VectorizeResource defines the resource implementation.
 type VectorizeResource struct {
-	client *cloudflare.Client
+	client           *cloudflare.Client
+	defaultAccountID string
 }
 
 func (r *VectorizeResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -34,17 +50,18 @@ func (r *VectorizeResource) Configure(ctx context.Context, req resource.Configur
 		return
 	}
 
-	client, ok := req.ProviderData.(*cloudflare.Client)
+	data, ok := req.ProviderData.(*providerdata.Data)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"unexpected resource configure type",
-			fmt.Sprintf("Expected *cloudflare.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *providerdata.Data, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 
 		return
 	}
 
-	r.client = client
+	r.client = data.Client
+	r.defaultAccountID = data.AccountID
 }
 
 func (r *VectorizeResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
@@ -90,6 +107,12 @@ func (r *VectorizeResource) Create(ctx context.Context, req resource.CreateReque
 		return
 	}
 
+	// the index, and its metadata indexes, can only be used once it is ready
+	if err := r.waitForIndex(ctx, data); err != nil {
+		resp.Diagnostics.AddError("failed waiting for vectorize index", err.Error())
+		return
+	}
+
 	for propertyName, indexType := range metadataIndexes {
 		_, err := r.client.Vectorize.Indexes.MetadataIndex.New(
 			ctx,
@@ -126,6 +149,10 @@ func (r *VectorizeResource) Read(ctx context.Context, req resource.ReadRequest,
 		},
 		option.WithMiddleware(logging.Middleware(ctx)),
 	)
+	if utils.IsNotFound(err, indexNotFoundErrorCode) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
 	if err != nil {
 		resp.Diagnostics.AddError("failed to read current state of vectorize index", err.Error())
 		return
@@ -139,6 +166,13 @@ func (r *VectorizeResource) Read(ctx context.Context, req resource.ReadRequest,
 	data.CreatedOn = basetypes.NewStringValue(index.CreatedOn.String())
 	data.ModifiedOn = basetypes.NewStringValue(index.ModifiedOn.String())
 
+	metadataIndexes, err := r.readMetadataIndexes(ctx, data.AccountID.ValueString(), data.Name.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("failed to list vectorize metadata indexes", err.Error())
+		return
+	}
+	data.MetadataIndexes = metadataIndexes
+
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
@@ -188,7 +222,7 @@ func (r *VectorizeResource) Update(ctx context.Context, req resource.UpdateReque
 			}
 		}
 
-		if oldType != newType {
+		if exists && oldType != newType {
 			_, err := r.client.Vectorize.Indexes.MetadataIndex.New(
 				ctx,
 				data.Name.ValueString(),
@@ -226,6 +260,12 @@ func (r *VectorizeResource) Update(ctx context.Context, req resource.UpdateReque
 		}
 	}
 
+	// only metadata indexes can change in place, the index itself is untouched
+	data.ID = state.ID
+	data.Description = state.Description
+	data.CreatedOn = state.CreatedOn
+	data.ModifiedOn = state.ModifiedOn
+
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
@@ -246,7 +286,7 @@ func (r *VectorizeResource) Delete(ctx context.Context, req resource.DeleteReque
 		},
 		option.WithMiddleware(logging.Middleware(ctx)),
 	)
-	if err != nil {
+	if err != nil && !utils.IsNotFound(err, indexNotFoundErrorCode) {
 		resp.Diagnostics.AddError("failed to make http request", err.Error())
 		return
 	}
@@ -254,6 +294,82 @@ func (r *VectorizeResource) Delete(ctx context.Context, req resource.DeleteReque
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
-func (r *VectorizeResource) ModifyPlan(_ context.Context, _ resource.ModifyPlanRequest, _ *resource.ModifyPlanResponse) {
+func (r *VectorizeResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	path_account_id := ""
+	path_index_name := ""
+	diags := importpath.ParseImportID(
+		req.ID,
+		"<account_id>/<index_name>",
+		&path_account_id,
+		&path_index_name,
+	)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("account_id"), path_account_id)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("name"), path_index_name)...)
+}
+
+func (r *VectorizeResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	providerdata.ModifyPlanAccountID(ctx, r.defaultAccountID, req, resp)
+}
+
+// waitForIndex waits until a new index can be read, which it only can once it
+// is ready.
+func (r *VectorizeResource) waitForIndex(ctx context.Context, data *VectorizeModel) error {
+	conf := waiter.StateChangeConf{
+		Description: fmt.Sprintf("vectorize index %q", data.Name.ValueString()),
+		Pending:     []string{"creating"},
+		Target:      []string{"ready"},
+		Timeout:     indexReadyTimeout,
+		Refresh: func(ctx context.Context) (any, string, error) {
+			_, err := r.client.Vectorize.Indexes.Get(
+				ctx,
+				data.Name.ValueString(),
+				vectorize.IndexGetParams{
+					AccountID: cloudflare.F(data.AccountID.ValueString()),
+				},
+				option.WithMiddleware(logging.Middleware(ctx)),
+			)
+			if utils.IsNotFound(err, indexNotFoundErrorCode) {
+				return nil, "creating", nil
+			}
+			if err != nil {
+				return nil, "", err
+			}
+			return nil, "ready", nil
+		},
+	}
+
+	_, err := conf.WaitForState(ctx)
+	return err
+}
+
+// readMetadataIndexes lists the metadata indexes of a Vectorize index, returning
+// a null map when there are none so that an omitted `metadata_indexes` matches.
+func (r *VectorizeResource) readMetadataIndexes(ctx context.Context, accountID, indexName string) (customfield.Map[basetypes.StringValue], error) {
+	list, err := r.client.Vectorize.Indexes.MetadataIndex.List(
+		ctx,
+		indexName,
+		vectorize.IndexMetadataIndexListParams{
+			AccountID: cloudflare.F(accountID),
+		},
+		option.WithMiddleware(logging.Middleware(ctx)),
+	)
+	if err != nil {
+		return customfield.NullMap[basetypes.StringValue](ctx), err
+	}
+
+	if len(list.MetadataIndexes) == 0 {
+		return customfield.NullMap[basetypes.StringValue](ctx), nil
+	}
+
+	values := make(map[string]attr.Value, len(list.MetadataIndexes))
+	for _, index := range list.MetadataIndexes {
+		values[index.PropertyName] = basetypes.NewStringValue(string(index.IndexType))
+	}
 
+	return customfield.NewMapMust[basetypes.StringValue](ctx, values), nil
 }
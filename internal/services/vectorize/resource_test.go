@@ -3,14 +3,19 @@ package vectorize_test
 import (
 	"context"
 	"fmt"
+	"math/big"
+	"net/http"
 	"os"
 	"testing"
 
 	"github.com/cloudflare/cloudflare-go/v3"
 	"github.com/cloudflare/cloudflare-go/v3/vectorize"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
 	"github.com/hashicorp/terraform-plugin-testing/terraform"
 	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/acctest"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/acctest/vcr"
 	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/utils"
 )
 
@@ -19,27 +24,240 @@ const (
 	metric     = "cosine"
 )
 
+func TestMain(m *testing.M) {
+	resource.TestMain(m)
+}
+
+func init() {
+	acctest.AddTestSweepers("cloudflare-extended_vectorize_index")
+}
+
 func TestAccCloudflareVectorize_Create(t *testing.T) {
-	t.Parallel()
+	acctest.Parallel(t)
 
-	rnd := utils.GenerateRandomResourceName()
+	rnd := acctest.RandomResourceName(t)
 	name := "cloudflare-extended_vectorize_index." + rnd
 	accountID := os.Getenv("CLOUDFLARE_ACCOUNT_ID")
+	client := acctest.Client(t)
 
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			acctest.TestAccPreCheck(t)
 			acctest.TestAccPreCheck_AccountID(t)
 		},
-		ProtoV6ProviderFactories: acctest.TestAccProtoV6ProviderFactories,
-		CheckDestroy:             testAccCheckCloudflareVectorizeDatabaseDestroy,
+		ProtoV6ProviderFactories: acctest.TestAccProtoV6ProviderFactories(t),
+		CheckDestroy:             testAccCheckCloudflareVectorizeDatabaseDestroy(client),
 		Steps: []resource.TestStep{
 			{
 				Config: testAccCheckCloudflareVectorizeIndexInitial(rnd, accountID),
 				Check: resource.ComposeTestCheckFunc(
 					resource.TestCheckResourceAttr(name, "name", rnd),
 					resource.TestCheckResourceAttr(name, "id", rnd),
-					testAccCheckCloudflareVectorizeIndexExists(rnd),
+					testAccCheckCloudflareVectorizeIndexExists(client, rnd),
+				),
+			},
+		},
+	})
+}
+
+// TestCloudflareVectorize_ReplayRead reads an index from a checked-in cassette,
+// so that replaying runs on every `go test`, without an account or Terraform.
+func TestCloudflareVectorize_ReplayRead(t *testing.T) {
+	t.Setenv(vcr.ModeEnvVarKey, string(vcr.ModeReplay))
+
+	rnd := acctest.RandomResourceName(t)
+	state := acctest.ReadResource(t, "cloudflare-extended_vectorize_index", map[string]tftypes.Value{
+		"id":         tftypes.NewValue(tftypes.String, rnd),
+		"account_id": tftypes.NewValue(tftypes.String, os.Getenv("CLOUDFLARE_ACCOUNT_ID")),
+		"name":       tftypes.NewValue(tftypes.String, rnd),
+	})
+
+	want := map[string]tftypes.Value{
+		"name":       tftypes.NewValue(tftypes.String, rnd),
+		"dimensions": tftypes.NewValue(tftypes.Number, big.NewFloat(dimensions)),
+		"metric":     tftypes.NewValue(tftypes.String, metric),
+		"metadata_indexes": tftypes.NewValue(tftypes.Map{ElementType: tftypes.String}, map[string]tftypes.Value{
+			"category": tftypes.NewValue(tftypes.String, "string"),
+		}),
+	}
+	for key, value := range want {
+		if !state[key].Equal(value) {
+			t.Errorf("got %s %s, want %s", key, state[key], value)
+		}
+	}
+}
+
+func TestCloudflareVectorize_Offline(t *testing.T) {
+	srv := acctest.NewOfflineTest(t)
+	rnd := utils.GenerateRandomResourceName()
+	name := "cloudflare-extended_vectorize_index." + rnd
+	accountID := srv.AccountID
+	provider := srv.ProviderConfig()
+	client := srv.APIClient()
+
+	srv.Test(resource.TestCase{
+		Steps: []resource.TestStep{
+			{
+				Config: provider + testAccCheckCloudflareVectorizeIndexInitial(rnd, accountID),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "name", rnd),
+					resource.TestCheckResourceAttr(name, "id", rnd),
+					resource.TestCheckResourceAttr(name, "metadata_indexes.%", "3"),
+				),
+			},
+			{
+				Config: provider + testAccCheckCloudflareVectorizeIndexUpdate(rnd, accountID, dimensions),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction(name, plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "id", rnd),
+					resource.TestCheckResourceAttr(name, "metadata_indexes.%", "3"),
+					resource.TestCheckResourceAttr(name, "metadata_indexes.test", "number"),
+					resource.TestCheckResourceAttr(name, "metadata_indexes.test4", "boolean"),
+				),
+			},
+			{
+				PreConfig: func() {
+					_, err := client.Vectorize.Indexes.MetadataIndex.New(
+						context.Background(),
+						rnd,
+						vectorize.IndexMetadataIndexNewParams{
+							AccountID:    cloudflare.F(accountID),
+							PropertyName: cloudflare.F("drift"),
+							IndexType:    cloudflare.F(vectorize.IndexMetadataIndexNewParamsIndexTypeString),
+						},
+					)
+					if err != nil {
+						t.Fatalf("failed to create metadata index out-of-band: %s", err)
+					}
+				},
+				Config:             provider + testAccCheckCloudflareVectorizeIndexUpdate(rnd, accountID, dimensions),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: provider + testAccCheckCloudflareVectorizeIndexUpdate(rnd, accountID, dimensions),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "metadata_indexes.%", "3"),
+					resource.TestCheckNoResourceAttr(name, "metadata_indexes.drift"),
+				),
+			},
+			{
+				Config: provider + testAccCheckCloudflareVectorizeIndexUpdate(rnd, accountID, 768),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction(name, plancheck.ResourceActionReplace),
+					},
+				},
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "dimensions", "768"),
+				),
+			},
+			{
+				ResourceName:      name,
+				ImportState:       true,
+				ImportStateId:     fmt.Sprintf("%s/%s", accountID, rnd),
+				ImportStateVerify: true,
+			},
+			{
+				PreConfig: func() {
+					_, err := client.Vectorize.Indexes.Delete(
+						context.Background(),
+						rnd,
+						vectorize.IndexDeleteParams{AccountID: cloudflare.F(accountID)},
+					)
+					if err != nil {
+						t.Fatalf("failed to delete vectorize index out-of-band: %s", err)
+					}
+				},
+				Config:             provider + testAccCheckCloudflareVectorizeIndexUpdate(rnd, accountID, 768),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestCloudflareVectorize_OfflineProviderAccountID(t *testing.T) {
+	srv := acctest.NewOfflineTest(t)
+	rnd := utils.GenerateRandomResourceName()
+	name := "cloudflare-extended_vectorize_index." + rnd
+	accountID := srv.AccountID
+	otherAccountID := "0123456789abcdef0123456789abcdef"
+
+	srv.Test(resource.TestCase{
+		Steps: []resource.TestStep{
+			{
+				Config: srv.ProviderConfig(fmt.Sprintf("account_id = %q", accountID)) + testAccCheckCloudflareVectorizeIndexProviderAccountID(rnd),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "account_id", accountID),
+					func(s *terraform.State) error {
+						if !srv.VectorizeIndexExists(accountID, rnd) {
+							return fmt.Errorf("vectorize index %s was not created in account %s", rnd, accountID)
+						}
+						return nil
+					},
+				),
+			},
+			{
+				// setting the same account on the resource itself doesn't replace it
+				Config: srv.ProviderConfig(fmt.Sprintf("account_id = %q", otherAccountID)) + testAccCheckCloudflareVectorizeIndexInitial(rnd, accountID),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction(name, plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "account_id", accountID),
+				),
+			},
+			{
+				Config: srv.ProviderConfig(fmt.Sprintf("account_id = %q", otherAccountID)) + testAccCheckCloudflareVectorizeIndexProviderAccountID(rnd),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction(name, plancheck.ResourceActionDestroyBeforeCreate),
+					},
+				},
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "account_id", otherAccountID),
+					func(s *terraform.State) error {
+						if srv.VectorizeIndexExists(accountID, rnd) {
+							return fmt.Errorf("vectorize index %s still exists in account %s", rnd, accountID)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestCloudflareVectorize_OfflineWaitForIndex(t *testing.T) {
+	srv := acctest.NewOfflineTest(t)
+	rnd := utils.GenerateRandomResourceName()
+	name := "cloudflare-extended_vectorize_index." + rnd
+	accountID := srv.AccountID
+	provider := srv.ProviderConfig()
+
+	srv.Test(resource.TestCase{
+		Steps: []resource.TestStep{
+			{
+				// the new index is not ready for metadata indexes right away
+				PreConfig: func() {
+					srv.FailNextRequestsWithMethod(http.MethodGet, 2, http.StatusNotFound)
+				},
+				Config: provider + testAccCheckCloudflareVectorizeIndexInitial(rnd, accountID),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "metadata_indexes.%", "3"),
+					func(s *terraform.State) error {
+						if n := srv.PendingFailures(); n != 0 {
+							return fmt.Errorf("%d injected failures were not waited out", n)
+						}
+						return nil
+					},
 				),
 			},
 		},
@@ -50,10 +268,16 @@ func testAccCheckCloudflareVectorizeIndexInitial(rnd, accountID string) string {
 	return acctest.LoadTestCase("vectorizeindexinitial.tf", rnd, accountID, dimensions, metric)
 }
 
-func testAccCheckCloudflareVectorizeIndexExists(name string) resource.TestCheckFunc {
-	return func(s *terraform.State) error {
-		client := acctest.SharedClient()
+func testAccCheckCloudflareVectorizeIndexUpdate(rnd, accountID string, dimensions int) string {
+	return acctest.LoadTestCase("vectorizeindexupdate.tf", rnd, accountID, dimensions, metric)
+}
 
+func testAccCheckCloudflareVectorizeIndexProviderAccountID(rnd string) string {
+	return acctest.LoadTestCase("vectorizeindexprovideraccountid.tf", rnd, dimensions, metric)
+}
+
+func testAccCheckCloudflareVectorizeIndexExists(client *cloudflare.Client, name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
 		rs, ok := s.RootModule().Resources["cloudflare-extended_vectorize_index."+name]
 		if !ok {
 			return fmt.Errorf("not found: %s", name)
@@ -75,27 +299,28 @@ func testAccCheckCloudflareVectorizeIndexExists(name string) resource.TestCheckF
 	}
 }
 
-func testAccCheckCloudflareVectorizeDatabaseDestroy(s *terraform.State) error {
-	accountID := os.Getenv("CLOUDFLARE_ACCOUNT_ID")
+func testAccCheckCloudflareVectorizeDatabaseDestroy(client *cloudflare.Client) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		accountID := os.Getenv("CLOUDFLARE_ACCOUNT_ID")
 
-	for _, rs := range s.RootModule().Resources {
-		if rs.Type != "cloudflare-extended_vectorize_index" {
-			continue
-		}
+		for _, rs := range s.RootModule().Resources {
+			if rs.Type != "cloudflare-extended_vectorize_index" {
+				continue
+			}
 
-		client := acctest.SharedClient()
-		index, _ := client.Vectorize.Indexes.Get(
-			context.Background(),
-			rs.Primary.ID,
-			vectorize.IndexGetParams{
-				AccountID: cloudflare.F(accountID),
-			},
-		)
+			index, _ := client.Vectorize.Indexes.Get(
+				context.Background(),
+				rs.Primary.ID,
+				vectorize.IndexGetParams{
+					AccountID: cloudflare.F(accountID),
+				},
+			)
 
-		if index != nil {
-			return fmt.Errorf("vectorize index with id %s still exists", rs.Primary.ID)
+			if index != nil {
+				return fmt.Errorf("vectorize index with id %s still exists", rs.Primary.ID)
+			}
 		}
-	}
 
-	return nil
+		return nil
+	}
 }
@@ -0,0 +1,5 @@
+resource "cloudflare-extended_vectorize_index" "%[1]s" {
+  name       = "%[1]s"
+  dimensions = "%[2]d"
+  metric     = "%[3]s"
+}
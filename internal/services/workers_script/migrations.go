@@ -0,0 +1,358 @@
+package workers_script
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/cloudflare/cloudflare-go/v3"
+	"github.com/cloudflare/cloudflare-go/v3/durable_objects"
+	"github.com/cloudflare/cloudflare-go/v3/option"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/customfield"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/logging"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/utils"
+)
+
+// migrationStep is a single step of a Durable Object migration, either the
+// migration itself or one of its `steps`.
+type migrationStep struct {
+	path             path.Path
+	newClasses       []string
+	newSqliteClasses []string
+	renamedClasses   []classRename
+	deletedClasses   []string
+	transferredTo    []string
+}
+
+type classRename struct {
+	from string
+	to   string
+}
+
+// deployedMigrations is the Durable Object state of a deployed script.
+type deployedMigrations struct {
+	tag     string
+	classes map[string]bool
+}
+
+// validateMigrations checks the configured Durable Object migrations against
+// the migration tag and classes of the deployed script in accountID, so that a
+// migration the API would reject, or one that deletes data, is reported at plan
+// time.
+func (r *WorkersScriptResource) validateMigrations(ctx context.Context, accountID types.String, config *WorkersScriptModel) (diags diag.Diagnostics) {
+	if config.Migrations.IsNull() || config.Migrations.IsUnknown() ||
+		accountID.IsUnknown() || config.ScriptName.IsUnknown() {
+		return
+	}
+
+	migrations, d := config.Migrations.Value(ctx)
+	diags.Append(d...)
+	if diags.HasError() || migrations.OldTag.IsUnknown() || migrations.NewTag.IsUnknown() {
+		return
+	}
+
+	// validation is skipped until every class name is known
+	steps, known := migrationSteps(ctx, migrations)
+	if !known {
+		return
+	}
+
+	deployed, err := r.readDeployedMigrations(ctx, accountID.ValueString(), config.ScriptName.ValueString())
+	if err != nil {
+		diags.AddWarning("failed to read deployed durable object migrations", err.Error())
+		return
+	}
+
+	newTag := migrations.NewTag.ValueString()
+	if newTag != "" && newTag == deployed.tag {
+		// already applied, the API skips it on upload
+		return
+	}
+
+	oldTag := migrations.OldTag.ValueString()
+	if oldTag != deployed.tag {
+		detail := fmt.Sprintf("The migration tag currently deployed for script %q is %q, but `old_tag` is %q.", config.ScriptName.ValueString(), deployed.tag, oldTag)
+		if deployed.tag == "" {
+			detail = fmt.Sprintf("Script %q has no migrations applied yet, so `old_tag` must be unset, but it is %q.", config.ScriptName.ValueString(), oldTag)
+		}
+		diags.AddAttributeError(path.Root("migrations").AtName("old_tag"), "migration old_tag does not match the deployed migration tag", detail)
+		return
+	}
+
+	classes := deployed.classes
+	var deleted []string
+	for _, step := range steps {
+		for _, name := range append(append([]string{}, step.newClasses...), step.newSqliteClasses...) {
+			if classes[name] {
+				diags.AddAttributeError(step.path, "invalid durable object migration", fmt.Sprintf("Class %q cannot be created, it already exists.", name))
+			}
+			classes[name] = true
+		}
+		for _, rename := range step.renamedClasses {
+			if !classes[rename.from] {
+				diags.AddAttributeError(step.path.AtName("renamed_classes"), "invalid durable object migration", fmt.Sprintf("Class %q cannot be renamed, it does not exist.", rename.from))
+			}
+			if classes[rename.to] {
+				diags.AddAttributeError(step.path.AtName("renamed_classes"), "invalid durable object migration", fmt.Sprintf("Class %q cannot be renamed to %q, it already exists.", rename.from, rename.to))
+			}
+			delete(classes, rename.from)
+			classes[rename.to] = true
+		}
+		for _, name := range step.deletedClasses {
+			if !classes[name] {
+				diags.AddAttributeError(step.path.AtName("deleted_classes"), "invalid durable object migration", fmt.Sprintf("Class %q cannot be deleted, it does not exist.", name))
+				continue
+			}
+			diags.AddAttributeWarning(
+				step.path.AtName("deleted_classes"),
+				"durable object class will be deleted",
+				fmt.Sprintf("Applying this migration permanently deletes every Durable Object of class %q and all of its stored data.", name),
+			)
+			delete(classes, name)
+			deleted = append(deleted, name)
+		}
+		for _, name := range step.transferredTo {
+			if classes[name] {
+				diags.AddAttributeError(step.path.AtName("transferred_classes"), "invalid durable object migration", fmt.Sprintf("Class %q cannot be transferred to, it already exists.", name))
+			}
+			classes[name] = true
+		}
+	}
+
+	bindings, d := config.Bindings.AsStructSliceT(ctx)
+	diags.Append(d...)
+	for _, binding := range bindings {
+		if binding.Type.ValueString() != "durable_object_namespace" || !(binding.ScriptName.IsNull() || binding.ScriptName.Equal(config.ScriptName)) {
+			continue
+		}
+		for _, name := range deleted {
+			if binding.ClassName.ValueString() == name {
+				diags.AddAttributeError(
+					path.Root("bindings"),
+					"binding to deleted durable object class",
+					fmt.Sprintf("Binding %q refers to class %q, which the migrations delete.", binding.Name.ValueString(), name),
+				)
+			}
+		}
+	}
+
+	return
+}
+
+// migrationSteps flattens the migration and its `steps` into the order the
+// API applies them, reporting whether every class name is known.
+func migrationSteps(ctx context.Context, migrations *WorkersScriptMigrationsModel) ([]migrationStep, bool) {
+	root := path.Root("migrations")
+	first := migrationStep{path: root}
+	known := true
+
+	first.newClasses, known = knownStrings(migrations.NewClasses, known)
+	first.newSqliteClasses, known = knownStrings(migrations.NewSqliteClasses, known)
+	first.deletedClasses, known = knownStrings(migrations.DeletedClasses, known)
+
+	renamed, _ := migrations.RenamedClasses.AsStructSliceT(ctx)
+	for _, rename := range renamed {
+		known = known && !rename.From.IsUnknown() && !rename.To.IsUnknown()
+		first.renamedClasses = append(first.renamedClasses, classRename{from: rename.From.ValueString(), to: rename.To.ValueString()})
+	}
+	transferred, _ := migrations.TransferredClasses.AsStructSliceT(ctx)
+	for _, transfer := range transferred {
+		known = known && !transfer.To.IsUnknown()
+		first.transferredTo = append(first.transferredTo, transfer.To.ValueString())
+	}
+
+	steps := []migrationStep{first}
+	models, _ := migrations.Steps.AsStructSliceT(ctx)
+	for i, model := range models {
+		step := migrationStep{path: root.AtName("steps").AtListIndex(i)}
+
+		step.newClasses, known = knownStrings(model.NewClasses, known)
+		step.newSqliteClasses, known = knownStrings(model.NewSqliteClasses, known)
+		step.deletedClasses, known = knownStrings(model.DeletedClasses, known)
+
+		renamed, _ := model.RenamedClasses.AsStructSliceT(ctx)
+		for _, rename := range renamed {
+			known = known && !rename.From.IsUnknown() && !rename.To.IsUnknown()
+			step.renamedClasses = append(step.renamedClasses, classRename{from: rename.From.ValueString(), to: rename.To.ValueString()})
+		}
+		transferred, _ := model.TransferredClasses.AsStructSliceT(ctx)
+		for _, transfer := range transferred {
+			known = known && !transfer.To.IsUnknown()
+			step.transferredTo = append(step.transferredTo, transfer.To.ValueString())
+		}
+
+		steps = append(steps, step)
+	}
+
+	known = known && !migrations.RenamedClasses.IsUnknown() && !migrations.TransferredClasses.IsUnknown() && !migrations.Steps.IsUnknown()
+	return steps, known
+}
+
+func knownStrings(list customfield.List[types.String], known bool) ([]string, bool) {
+	if list.IsUnknown() {
+		return nil, false
+	}
+
+	var values []string
+	for _, v := range list.Elements() {
+		s, ok := v.(types.String)
+		if !ok || s.IsUnknown() {
+			return nil, false
+		}
+		values = append(values, s.ValueString())
+	}
+
+	return values, known
+}
+
+// migrationsChanged reports whether the configured migrations differ from the
+// ones in state, or are planned for another script.
+func migrationsChanged(ctx context.Context, plan, config, state *WorkersScriptModel) bool {
+	if state == nil || !plan.AccountID.Equal(state.AccountID) || !plan.ScriptName.Equal(state.ScriptName) {
+		return true
+	}
+	if config.Migrations.IsNull() || state.Migrations.IsNull() {
+		return config.Migrations.IsNull() != state.Migrations.IsNull()
+	}
+
+	configured, d := config.Migrations.Value(ctx)
+	if d.HasError() || configured == nil {
+		return true
+	}
+	current, d := state.Migrations.Value(ctx)
+	if d.HasError() || current == nil {
+		return true
+	}
+
+	configuredSteps, known := migrationSteps(ctx, configured)
+	currentSteps, _ := migrationSteps(ctx, current)
+
+	// lists left out of the configuration may be empty rather than null in
+	// state, which formats the same
+	return !known ||
+		!configured.OldTag.Equal(current.OldTag) ||
+		!configured.NewTag.Equal(current.NewTag) ||
+		fmt.Sprint(configuredSteps) != fmt.Sprint(currentSteps)
+}
+
+// readDeployedMigrations returns the migration tag and Durable Object classes
+// of a script. A script that doesn't exist yet has neither.
+func (r *WorkersScriptResource) readDeployedMigrations(ctx context.Context, accountID, scriptName string) (deployed deployedMigrations, err error) {
+	deployed.classes = make(map[string]bool)
+
+	res := new(http.Response)
+	err = r.client.Execute(
+		ctx,
+		http.MethodGet,
+		fmt.Sprintf("accounts/%s/workers/scripts/%s/settings", accountID, scriptName),
+		nil,
+		&res,
+		option.WithMiddleware(logging.Middleware(ctx)),
+	)
+	if utils.IsNotFound(err, scriptNotFoundErrorCode) {
+		return deployed, nil
+	}
+	if err != nil {
+		return deployed, err
+	}
+
+	bytes, err := io.ReadAll(res.Body)
+	if err != nil {
+		return deployed, err
+	}
+
+	// the migration tag is not part of the settings model
+	var env struct {
+		Result struct {
+			MigrationTag string `json:"migration_tag"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(bytes, &env); err != nil {
+		return deployed, err
+	}
+	deployed.tag = env.Result.MigrationTag
+
+	// classes are only created by migrations
+	if deployed.tag == "" {
+		return deployed, nil
+	}
+
+	// Durable Object namespaces can only be listed for the whole account
+	namespaces := r.client.DurableObjects.Namespaces.ListAutoPaging(
+		ctx,
+		durable_objects.NamespaceListParams{AccountID: cloudflare.F(accountID)},
+		option.WithMiddleware(logging.Middleware(ctx)),
+	)
+	for namespaces.Next() {
+		if namespace := namespaces.Current(); namespace.Script == scriptName {
+			deployed.classes[namespace.Class] = true
+		}
+	}
+
+	return deployed, namespaces.Err()
+}
+
+// planMigrations plans the configured migrations. The nested class lists are
+// computed, and states written by earlier versions hold empty lists where the
+// configuration leaves them out, so those are kept rather than shown as
+// changes. Lists that are left out otherwise stay null instead of unknown, as
+// the API never returns them.
+func planMigrations(ctx context.Context, config, state *WorkersScriptModel) (customfield.NestedObject[WorkersScriptMigrationsModel], diag.Diagnostics) {
+	if config.Migrations.IsNull() || config.Migrations.IsUnknown() {
+		return config.Migrations, nil
+	}
+
+	planned, diags := config.Migrations.Value(ctx)
+	if diags.HasError() {
+		return config.Migrations, diags
+	}
+	current := &WorkersScriptMigrationsModel{}
+	if state != nil && !state.Migrations.IsNull() {
+		current, diags = state.Migrations.Value(ctx)
+		if diags.HasError() {
+			return config.Migrations, diags
+		}
+	}
+
+	planned.RenamedClasses = keepEmptyList(planned.RenamedClasses, current.RenamedClasses)
+	planned.TransferredClasses = keepEmptyList(planned.TransferredClasses, current.TransferredClasses)
+	planned.Steps = keepEmptyList(planned.Steps, current.Steps)
+
+	if !planned.Steps.IsNull() && !planned.Steps.IsUnknown() {
+		steps, d := planned.Steps.AsStructSliceT(ctx)
+		diags.Append(d...)
+		currentSteps, d := current.Steps.AsStructSliceT(ctx)
+		diags.Append(d...)
+		if diags.HasError() {
+			return config.Migrations, diags
+		}
+		for i := range steps {
+			currentStep := WorkersScriptMetadataMigrationsStepsModel{}
+			if i < len(currentSteps) {
+				currentStep = currentSteps[i]
+			}
+			steps[i].RenamedClasses = keepEmptyList(steps[i].RenamedClasses, currentStep.RenamedClasses)
+			steps[i].TransferredClasses = keepEmptyList(steps[i].TransferredClasses, currentStep.TransferredClasses)
+		}
+		planned.Steps, d = customfield.NewObjectList(ctx, steps)
+		diags.Append(d...)
+	}
+
+	migrations, d := customfield.NewObject(ctx, planned)
+	diags.Append(d...)
+	return migrations, diags
+}
+
+// keepEmptyList returns the state value of a list left out of the
+// configuration when it is an empty list, and null otherwise.
+func keepEmptyList[T any](configured, current customfield.NestedObjectList[T]) customfield.NestedObjectList[T] {
+	if configured.IsNull() && !current.IsNull() && !current.IsUnknown() && len(current.Elements()) == 0 {
+		return current
+	}
+	return configured
+}
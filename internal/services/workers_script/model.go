@@ -3,12 +3,21 @@ package workers_script
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"fmt"
+	"io"
+	"mime"
 	"mime/multipart"
 	"net/textproto"
+	"path"
+	"slices"
+	"sort"
 	"strings"
+	"unicode/utf8"
 
+	"github.com/hashicorp/terraform-plugin-framework-jsontypes/jsontypes"
 	"github.com/hashicorp/terraform-plugin-framework-timetypes/timetypes"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
 	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/apijson"
@@ -22,7 +31,7 @@ type WorkersScriptSettingResponseEnvelope struct {
 type WorkersScriptModel struct {
 	ID                 types.String                                                 `tfsdk:"id" path:"id,computed"`
 	ScriptName         types.String                                                 `tfsdk:"script_name" path:"script_name,required"`
-	AccountID          types.String                                                 `tfsdk:"account_id" path:"account_id,required"`
+	AccountID          types.String                                                 `tfsdk:"account_id" path:"account_id,computed_optional"`
 	Parts              customfield.NestedObjectMap[WorkersScriptPartModel]          `tfsdk:"parts" path:"parts,required"`
 	Bindings           customfield.NestedObjectSet[WorkersScriptBindingsModel]      `tfsdk:"bindings" json:"bindings,optional"`
 	CompatibilityDate  types.String                                                 `tfsdk:"compatibility_date" json:"compatibility_date,optional"`
@@ -45,14 +54,23 @@ type WorkersScriptModel struct {
 }
 
 type WorkersScriptPartModel struct {
-	Part   types.String `tfsdk:"part" path:"part,required"`
-	Module types.Bool   `tfsdk:"module" path:"module,optional"`
+	Part          types.String `tfsdk:"part" path:"part,optional"`
+	SourcePath    types.String `tfsdk:"source_path" path:"source_path,optional"`
+	Directory     types.String `tfsdk:"directory" path:"directory,optional"`
+	Glob          types.String `tfsdk:"glob" path:"glob,optional"`
+	ContentBase64 types.String `tfsdk:"content_base64" path:"content_base64,optional"`
+	ContentType   types.String `tfsdk:"content_type" path:"content_type,optional"`
+	Module        types.Bool   `tfsdk:"module" path:"module,optional"`
+	ContentHash   types.String `tfsdk:"content_hash" path:"content_hash,computed"`
 }
 
 func (r WorkersScriptModel) MarshalMultipart() (data []byte, contentType string, err error) {
 	buf := bytes.NewBuffer(nil)
 	writer := multipart.NewWriter(buf)
 	bindings, _ := r.Bindings.AsStructSliceT(context.Background())
+	for i := range bindings {
+		bindings[i] = bindings[i].toAPI()
+	}
 	tc, _ := r.TailConsumers.AsStructSliceT(context.Background())
 
 	metadata := WorkersScriptMetadataModel{
@@ -89,25 +107,43 @@ func (r WorkersScriptModel) MarshalMultipart() (data []byte, contentType string,
 		}
 	}
 
-	for k, v := range parts {
-		h := make(textproto.MIMEHeader)
-		h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, escapeQuotes(k), escapeQuotes(k)))
-		if v.Module.ValueBool() {
-			h.Set("Content-Type", "text/javascript+module")
-		} else {
-			h.Set("Content-Type", "text/javascript")
-		}
+	names := make([]string, 0, len(parts))
+	for k := range parts {
+		names = append(names, k)
+	}
+	sort.Strings(names)
 
-		scriptWriter, err := writer.CreatePart(h)
+	for _, k := range names {
+		v := parts[k]
+		files, err := v.files(k)
 		if err != nil {
 			writer.Close()
-			return nil, "", err
+			return nil, "", fmt.Errorf("failed to read part %q: %w", k, err)
 		}
 
-		_, err = scriptWriter.Write([]byte(v.Part.ValueString()))
-		if err != nil {
+		// the files are read again at apply time, make sure they are still
+		// the ones the plan was made with
+		if !v.ContentHash.IsNull() && !v.ContentHash.IsUnknown() && v.ContentHash.ValueString() != contentHash(files, v.fromDirectory()) {
 			writer.Close()
-			return nil, "", err
+			return nil, "", fmt.Errorf("content of part %q changed after the plan was made, plan again to upload it", k)
+		}
+
+		for _, f := range files {
+			h := make(textproto.MIMEHeader)
+			h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, escapeQuotes(f.name), escapeQuotes(f.name)))
+			h.Set("Content-Type", f.contentType)
+
+			scriptWriter, err := writer.CreatePart(h)
+			if err != nil {
+				writer.Close()
+				return nil, "", err
+			}
+
+			_, err = scriptWriter.Write(f.content)
+			if err != nil {
+				writer.Close()
+				return nil, "", err
+			}
 		}
 	}
 
@@ -119,6 +155,154 @@ func (r WorkersScriptModel) MarshalMultipart() (data []byte, contentType string,
 	return buf.Bytes(), writer.FormDataContentType(), nil
 }
 
+// UnmarshalMultipart replaces the parts of the model with the ones in a
+// multipart script content response. entrypoint is the part the API reports as
+// the main module or body part of the script.
+func (r *WorkersScriptModel) UnmarshalMultipart(data []byte, contentType string, entrypoint string) error {
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return err
+	}
+
+	prior := make(map[string]WorkersScriptPartModel)
+	if !r.Parts.IsNull() && !r.Parts.IsUnknown() {
+		diags := r.Parts.ElementsAs(context.TODO(), &prior, false)
+		if diags.HasError() {
+			for _, err := range diags.Errors() {
+				return fmt.Errorf(err.Detail())
+			}
+		}
+	}
+
+	var remote []partFile
+	// scripts whose entrypoint is a classic script use the service worker
+	// syntax, every other entrypoint (JavaScript or Python) is a main module
+	isModuleWorker := false
+	reader := multipart.NewReader(bytes.NewReader(data), params["boundary"])
+	for {
+		p, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		content, err := io.ReadAll(p)
+		if err != nil {
+			return err
+		}
+
+		file := partFile{name: p.FormName(), contentType: p.Header.Get("Content-Type"), content: content}
+		if file.name == entrypoint && !isServiceWorkerContentType(file.contentType) {
+			isModuleWorker = true
+		}
+		remote = append(remote, file)
+	}
+
+	parts := make(map[string]WorkersScriptPartModel)
+	var unclaimed []partFile
+	for _, file := range remote {
+		p, ok := prior[file.name]
+		if ok && p.fromDirectory() {
+			ok = false
+		}
+		if !ok {
+			unclaimed = append(unclaimed, file)
+			continue
+		}
+		parts[file.name] = remotePart(p, file)
+	}
+
+	// directory parts claim the remaining files that match their glob
+	for name, p := range prior {
+		if !p.fromDirectory() {
+			continue
+		}
+
+		var files []partFile
+		unclaimed = slices.DeleteFunc(unclaimed, func(file partFile) bool {
+			ok, _ := path.Match(p.Glob.ValueString(), file.name)
+			if ok {
+				files = append(files, file)
+			}
+			return ok
+		})
+
+		p.ContentHash = types.StringValue(contentHash(files, true))
+		parts[name] = p
+	}
+
+	for _, file := range unclaimed {
+		parts[file.name] = remotePart(WorkersScriptPartModel{}, file)
+	}
+
+	partsMap, diags := customfield.NewObjectMap(context.TODO(), parts)
+	if diags.HasError() {
+		for _, err := range diags.Errors() {
+			return fmt.Errorf(err.Detail())
+		}
+	}
+	r.Parts = partsMap
+
+	if entrypoint != "" {
+		if isModuleWorker {
+			r.MainModule = types.StringValue(entrypoint)
+			r.BodyPart = types.StringNull()
+		} else {
+			r.BodyPart = types.StringValue(entrypoint)
+			r.MainModule = types.StringNull()
+		}
+	}
+
+	return nil
+}
+
+// remotePart returns the part for a file reported by the API. Parts read from
+// `source_path` keep only the hash of the content, binary content is stored
+// base64 encoded.
+func remotePart(prior WorkersScriptPartModel, file partFile) WorkersScriptPartModel {
+	part := WorkersScriptPartModel{
+		Part:        types.StringValue(string(file.content)),
+		ContentHash: types.StringValue(contentHash([]partFile{file}, false)),
+	}
+	switch {
+	case !prior.SourcePath.IsNull():
+		part.Part = types.StringNull()
+		part.SourcePath = prior.SourcePath
+	// binary content can't be stored in a string attribute
+	case !prior.ContentBase64.IsNull() || (prior.Part.IsNull() && !utf8.Valid(file.content)):
+		part.Part = types.StringNull()
+		part.ContentBase64 = types.StringValue(base64.StdEncoding.EncodeToString(file.content))
+	}
+
+	if !prior.ContentType.IsNull() {
+		mediaType, _, _ := mime.ParseMediaType(file.contentType)
+		part.ContentType = types.StringValue(mediaType)
+	}
+
+	switch {
+	case isModuleContentType(file.contentType):
+		part.Module = types.BoolValue(true)
+	case isServiceWorkerContentType(file.contentType) && !prior.Module.IsNull():
+		part.Module = types.BoolValue(false)
+	default:
+		part.Module = prior.Module
+	}
+
+	return part
+}
+
+func isModuleContentType(contentType string) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	return mediaType == "text/javascript+module" || mediaType == "application/javascript+module"
+}
+
+func isServiceWorkerContentType(contentType string) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	return mediaType == "text/javascript" || mediaType == "application/javascript"
+}
+
 type WorkersScriptMetadataModel struct {
 	Bindings           customfield.NestedObjectList[WorkersScriptBindingsModel]      `tfsdk:"bindings" json:"bindings,optional"`
 	BodyPart           types.String                                                  `tfsdk:"body_part" json:"body_part,optional"`
@@ -140,16 +324,65 @@ func (m WorkersScriptMetadataModel) MarshalJSON() (data []byte, err error) {
 }
 
 type WorkersScriptBindingsModel struct {
-	Name          types.String `tfsdk:"name" json:"name,optional"`
-	Type          types.String `tfsdk:"type" json:"type,optional"`
-	BucketName    types.String `tfsdk:"bucket_name" json:"bucket_name,optional"`
-	Service       types.String `tfsdk:"service" json:"service,optional"`
-	Environment   types.String `tfsdk:"environment" json:"environment,optional"`
-	ClassName     types.String `tfsdk:"class_name" json:"class_name,optional"`
-	ScriptName    types.String `tfsdk:"script_name" json:"script_name,optional"`
-	QueueName     types.String `tfsdk:"queue_name" json:"queue_name,optional"`
-	ID            types.String `tfsdk:"id" json:"id,optional"`
-	CertificateID types.String `tfsdk:"certificate_id" json:"certificate_id,optional"`
+	Name          types.String         `tfsdk:"name" json:"name,optional"`
+	Type          types.String         `tfsdk:"type" json:"type,optional"`
+	BucketName    types.String         `tfsdk:"bucket_name" json:"bucket_name,optional"`
+	Service       types.String         `tfsdk:"service" json:"service,optional"`
+	Environment   types.String         `tfsdk:"environment" json:"environment,optional"`
+	ClassName     types.String         `tfsdk:"class_name" json:"class_name,optional"`
+	ScriptName    types.String         `tfsdk:"script_name" json:"script_name,optional"`
+	QueueName     types.String         `tfsdk:"queue_name" json:"queue_name,optional"`
+	ID            types.String         `tfsdk:"id" json:"id,optional"`
+	CertificateID types.String         `tfsdk:"certificate_id" json:"certificate_id,optional"`
+	Text          types.String         `tfsdk:"text" json:"text,optional"`
+	NamespaceID   types.String         `tfsdk:"namespace_id" json:"namespace_id,optional"`
+	DatabaseID    types.String         `tfsdk:"database_id" json:"database_id,optional"`
+	IndexName     types.String         `tfsdk:"index_name" json:"index_name,optional"`
+	Dataset       types.String         `tfsdk:"dataset" json:"dataset,optional"`
+	Namespace     types.String         `tfsdk:"namespace" json:"namespace,optional"`
+	Json          jsontypes.Normalized `tfsdk:"json" json:"json,optional"`
+}
+
+// attributes returns the type specific attributes of the binding keyed by
+// their schema name.
+func (m WorkersScriptBindingsModel) attributes() map[string]attr.Value {
+	return map[string]attr.Value{
+		"bucket_name":    m.BucketName,
+		"service":        m.Service,
+		"environment":    m.Environment,
+		"class_name":     m.ClassName,
+		"script_name":    m.ScriptName,
+		"queue_name":     m.QueueName,
+		"id":             m.ID,
+		"certificate_id": m.CertificateID,
+		"text":           m.Text,
+		"namespace_id":   m.NamespaceID,
+		"database_id":    m.DatabaseID,
+		"index_name":     m.IndexName,
+		"dataset":        m.Dataset,
+		"namespace":      m.Namespace,
+		"json":           m.Json,
+	}
+}
+
+// toAPI returns the binding as it is sent in the upload metadata. D1 bindings
+// take the database ID as `id`, which this resource reserves for Hyperdrive.
+// D1 bindings still setting the deprecated `id` are sent as they are.
+func (m WorkersScriptBindingsModel) toAPI() WorkersScriptBindingsModel {
+	if m.Type.ValueString() == "d1" && !m.DatabaseID.IsNull() {
+		m.ID = m.DatabaseID
+		m.DatabaseID = types.StringNull()
+	}
+	return m
+}
+
+// fromAPI reverses toAPI for a binding reported by the API.
+func (m WorkersScriptBindingsModel) fromAPI() WorkersScriptBindingsModel {
+	if m.Type.ValueString() == "d1" {
+		m.DatabaseID = m.ID
+		m.ID = types.StringNull()
+	}
+	return m
 }
 
 type WorkersScriptMigrationsModel struct {
@@ -0,0 +1,199 @@
+package workers_script
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// partFile is a single file uploaded as part of a Worker script.
+type partFile struct {
+	name        string
+	contentType string
+	content     []byte
+}
+
+// fromDirectory reports whether the part uploads the files in a directory.
+func (m WorkersScriptPartModel) fromDirectory() bool {
+	return !m.Directory.IsNull()
+}
+
+// hasUnknownSource reports whether the content of the part can't be known
+// until apply.
+func (m WorkersScriptPartModel) hasUnknownSource() bool {
+	return m.Part.IsUnknown() || m.ContentBase64.IsUnknown() || m.SourcePath.IsUnknown() ||
+		m.Directory.IsUnknown() || m.Glob.IsUnknown() || m.ContentType.IsUnknown()
+}
+
+// files returns the files uploaded for the part called name. Inline parts and
+// parts read from `source_path` are uploaded as a single file named after the
+// part. Directory parts upload every file matching `glob`, named by its path
+// relative to the directory. An explicit `content_type` applies to all of them.
+func (m WorkersScriptPartModel) files(name string) ([]partFile, error) {
+	files, err := m.readFiles(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if !m.ContentType.IsNull() {
+		for i := range files {
+			files[i].contentType = m.ContentType.ValueString()
+		}
+	}
+
+	return files, nil
+}
+
+func (m WorkersScriptPartModel) readFiles(name string) ([]partFile, error) {
+	switch {
+	case m.fromDirectory():
+		return globFiles(m.Directory.ValueString(), m.Glob.ValueString(), m.Module)
+	case !m.SourcePath.IsNull():
+		content, err := os.ReadFile(m.SourcePath.ValueString())
+		if err != nil {
+			return nil, err
+		}
+		// fall back to the extension of the file for parts named without one
+		ext := name
+		if path.Ext(name) == "" {
+			ext = m.SourcePath.ValueString()
+		}
+		return []partFile{{name: name, contentType: partContentType(ext, m.Module), content: content}}, nil
+	case !m.ContentBase64.IsNull():
+		content, err := base64.StdEncoding.DecodeString(m.ContentBase64.ValueString())
+		if err != nil {
+			return nil, fmt.Errorf("invalid content_base64: %w", err)
+		}
+		return []partFile{{name: name, contentType: partContentType(name, m.Module), content: content}}, nil
+	default:
+		return []partFile{{name: name, contentType: partContentType(name, m.Module), content: []byte(m.Part.ValueString())}}, nil
+	}
+}
+
+// checkGlob checks that pattern is valid for path.Match. As path.Match has no
+// `**`, which would otherwise silently match like `*`, it is rejected.
+func checkGlob(pattern string) error {
+	if strings.Contains(pattern, "**") {
+		return fmt.Errorf("glob %q uses `**`, which is not supported: `*` only matches within a directory, so match files in subdirectories with a pattern per level, e.g. `*/*.js`", pattern)
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("invalid glob %q: %w", pattern, err)
+	}
+	return nil
+}
+
+func globFiles(directory string, pattern string, module types.Bool) ([]partFile, error) {
+	if err := checkGlob(pattern); err != nil {
+		return nil, err
+	}
+
+	var files []partFile
+	err := filepath.WalkDir(directory, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		rel, err := filepath.Rel(directory, p)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		if ok, _ := path.Match(pattern, name); !ok {
+			return nil
+		}
+
+		content, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		files = append(files, partFile{name: name, contentType: partContentType(name, module), content: content})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("glob %q matched no files in %q", pattern, directory)
+	}
+
+	return files, nil
+}
+
+// contentHash returns the hex encoded SHA-256 of the content of a part. For
+// directory parts it is the SHA-256 of a `sha256sum` style listing of the
+// files, so renaming, adding or removing a file changes the hash too.
+func contentHash(files []partFile, fromDirectory bool) string {
+	if !fromDirectory {
+		var content []byte
+		if len(files) > 0 {
+			content = files[0].content
+		}
+		sum := sha256.Sum256(content)
+		return hex.EncodeToString(sum[:])
+	}
+
+	sorted := make([]partFile, len(files))
+	copy(sorted, files)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].name < sorted[j].name })
+
+	hash := sha256.New()
+	for _, f := range sorted {
+		sum := sha256.Sum256(f.content)
+		fmt.Fprintf(hash, "%s  %s\n", hex.EncodeToString(sum[:]), f.name)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// partContentTypes are the content types the Workers API accepts for the
+// parts of a script.
+var partContentTypes = []string{
+	"application/javascript",
+	"application/javascript+module",
+	"application/octet-stream",
+	"application/source-map",
+	"application/wasm",
+	"text/javascript",
+	"text/javascript+module",
+	"text/plain",
+	"text/x-python",
+	"text/x-python-requirement",
+}
+
+// partContentType infers the content type of a script part from the extension
+// of name. JavaScript, and names without a known extension, are uploaded as
+// ES modules or service worker scripts depending on module.
+func partContentType(name string, module types.Bool) string {
+	if path.Base(name) == "requirements.txt" {
+		return "text/x-python-requirement"
+	}
+
+	switch strings.ToLower(path.Ext(name)) {
+	case ".py":
+		return "text/x-python"
+	case ".wasm":
+		return "application/wasm"
+	case ".map":
+		return "application/source-map"
+	case ".txt", ".html":
+		return "text/plain"
+	case ".bin":
+		return "application/octet-stream"
+	case ".mjs":
+		return "text/javascript+module"
+	}
+
+	if module.ValueBool() {
+		return "text/javascript+module"
+	}
+	return "text/javascript"
+}
@@ -5,12 +5,15 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"time"
 
 	"github.com/cloudflare/cloudflare-go/v3"
 	"github.com/cloudflare/cloudflare-go/v3/option"
 	"github.com/cloudflare/cloudflare-go/v3/workers"
+	"github.com/hashicorp/terraform-plugin-framework-jsontypes/jsontypes"
 	"github.com/hashicorp/terraform-plugin-framework-timetypes/timetypes"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
@@ -18,6 +21,9 @@ import (
 	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/customfield"
 	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/importpath"
 	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/logging"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/providerdata"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/utils"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/waiter"
 )
 
 // Ensure provider defined types fully satisfy framework interfaces.
@@ -25,13 +31,20 @@ var _ resource.ResourceWithConfigure = (*WorkersScriptResource)(nil)
 var _ resource.ResourceWithModifyPlan = (*WorkersScriptResource)(nil)
 var _ resource.ResourceWithImportState = (*WorkersScriptResource)(nil)
 
+// API error code returned when a Worker script does not exist.
+const scriptNotFoundErrorCode = 10007
+
+// How long a new Worker script may take to be readable after its upload.
+const scriptPropagationTimeout = 2 * time.Minute
+
 func NewResource() resource.Resource {
 	return &WorkersScriptResource{}
 }
 
 // WorkersScriptResource defines the resource implementation.
 type WorkersScriptResource struct {
-	client *cloudflare.Client
+	client           *cloudflare.Client
+	defaultAccountID string
 }
 
 func (r *WorkersScriptResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -43,18 +56,19 @@ func (r *WorkersScriptResource) Configure(ctx context.Context, req resource.Conf
 		return
 	}
 
-	client, ok := req.ProviderData.(*cloudflare.Client)
+	data, ok := req.ProviderData.(*providerdata.Data)
 
 	if !ok {
 		resp.Diagnostics.AddError(
 			"unexpected resource configure type",
-			fmt.Sprintf("Expected *cloudflare.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *providerdata.Data, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 
 		return
 	}
 
-	r.client = client
+	r.client = data.Client
+	r.defaultAccountID = data.AccountID
 }
 
 func (r *WorkersScriptResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
@@ -86,7 +100,15 @@ func (r *WorkersScriptResource) Create(ctx context.Context, req resource.CreateR
 
 	updateModelFromResponse(ctx, data, created)
 
+	// the script is saved before waiting, so a timeout does not leak it
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.waitForScript(ctx, data); err != nil {
+		resp.Diagnostics.AddError("failed waiting for worker script", err.Error())
+	}
 }
 
 func (r *WorkersScriptResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
@@ -120,27 +142,32 @@ func (r *WorkersScriptResource) Read(ctx context.Context, req resource.ReadReque
 	}
 
 	res := new(http.Response)
-	path := fmt.Sprintf("accounts/%s/workers/scripts/%s/settings", data.AccountID.ValueString(), data.ID.ValueString())
+	endpoint := fmt.Sprintf("accounts/%s/workers/scripts/%s/settings", data.AccountID.ValueString(), data.ScriptName.ValueString())
 	err := r.client.Execute(
 		ctx,
 		http.MethodGet,
-		path,
+		endpoint,
 		nil,
 		&res,
 		option.WithMiddleware(logging.Middleware(ctx)),
 	)
+	if utils.IsNotFound(err, scriptNotFoundErrorCode) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
 	if err != nil {
 		resp.Diagnostics.AddError("failed to make http request", err.Error())
 		return
 	}
 
-	bytes := make([]byte, 0)
-	_, err = res.Body.Read(bytes)
+	bytes, err := io.ReadAll(res.Body)
 	if err != nil {
-		resp.Diagnostics.AddError("failed to deserialize http request", err.Error())
+		resp.Diagnostics.AddError("failed to read http response", err.Error())
 		return
 	}
 
+	// decode into an empty envelope so that optional attributes removed
+	// out-of-band come back as null
 	env := WorkersScriptSettingResponseEnvelope{}
 	err = apijson.Unmarshal(bytes, &env)
 	if err != nil {
@@ -148,23 +175,42 @@ func (r *WorkersScriptResource) Read(ctx context.Context, req resource.ReadReque
 		return
 	}
 
-	data.Logpush = env.Result.Logpush
-	data.UsageModel = env.Result.UsageModel
-
-	tcModel, diags := env.Result.TailConsumers.AsStructSliceT(ctx)
-	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(updateModelFromSettings(ctx, data, env.Result)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	data.TailConsumers = customfield.NewObjectSetMust(ctx, tcModel)
 
-	placement := WorkersScriptMetadataPlacementModel{}
-	diags = env.Result.Placement.As(ctx, &placement, basetypes.ObjectAsOptions{UnhandledNullAsEmpty: true, UnhandledUnknownAsEmpty: true})
-	resp.Diagnostics = append(resp.Diagnostics, diags...)
-	if resp.Diagnostics.HasError() {
+	res = new(http.Response)
+	endpoint = fmt.Sprintf("accounts/%s/workers/scripts/%s/content/v2", data.AccountID.ValueString(), data.ScriptName.ValueString())
+	err = r.client.Execute(
+		ctx,
+		http.MethodGet,
+		endpoint,
+		nil,
+		&res,
+		option.WithMiddleware(logging.Middleware(ctx)),
+	)
+	if utils.IsNotFound(err, scriptNotFoundErrorCode) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("failed to make http request", err.Error())
 		return
 	}
-	data.PlacementMode = placement.Mode
+
+	bytes, err = io.ReadAll(res.Body)
+	if err != nil {
+		resp.Diagnostics.AddError("failed to read http response", err.Error())
+		return
+	}
+
+	err = data.UnmarshalMultipart(bytes, res.Header.Get("Content-Type"), res.Header.Get("cf-entrypoint"))
+	if err != nil {
+		resp.Diagnostics.AddError("failed to deserialize multipart http response", err.Error())
+		return
+	}
+	data.ID = data.ScriptName
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
@@ -186,7 +232,7 @@ func (r *WorkersScriptResource) Delete(ctx context.Context, req resource.DeleteR
 		},
 		option.WithMiddleware(logging.Middleware(ctx)),
 	)
-	if err != nil {
+	if err != nil && !utils.IsNotFound(err, scriptNotFoundErrorCode) {
 		resp.Diagnostics.AddError("failed to make http request", err.Error())
 		return
 	}
@@ -196,8 +242,6 @@ func (r *WorkersScriptResource) Delete(ctx context.Context, req resource.DeleteR
 }
 
 func (r *WorkersScriptResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	var data *WorkersScriptModel
-
 	path_account_id := ""
 	path_script_name := ""
 	diags := importpath.ParseImportID(
@@ -211,33 +255,103 @@ func (r *WorkersScriptResource) ImportState(ctx context.Context, req resource.Im
 		return
 	}
 
-	res := new(http.Response)
-	_, err := r.client.Workers.Scripts.Get(
-		ctx,
-		path_script_name,
-		workers.ScriptGetParams{
-			AccountID: cloudflare.F(path_account_id),
-		},
-		option.WithResponseBodyInto(&res),
-		option.WithMiddleware(logging.Middleware(ctx)),
-	)
-	if err != nil {
-		resp.Diagnostics.AddError("failed to make http request", err.Error())
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("account_id"), path_account_id)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("script_name"), path_script_name)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), path_script_name)...)
+}
+
+func (r *WorkersScriptResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
 		return
 	}
-	bytes, _ := io.ReadAll(res.Body)
-	err = apijson.UnmarshalComputed(bytes, &data)
-	if err != nil {
-		resp.Diagnostics.AddError("failed to deserialize http request", err.Error())
+
+	providerdata.ModifyPlanAccountID(ctx, r.defaultAccountID, req, resp)
+	if resp.Diagnostics.HasError() {
 		return
 	}
-	data.ID = data.ScriptName
 
-	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
-}
+	var plan *WorkersScriptModel
+	resp.Diagnostics.Append(resp.Plan.Get(ctx, &plan)...)
+	var config *WorkersScriptModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	var state *WorkersScriptModel
+	if !req.State.Raw.IsNull() {
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
-func (r *WorkersScriptResource) ModifyPlan(_ context.Context, _ resource.ModifyPlanRequest, _ *resource.ModifyPlanResponse) {
+	// the deployed migrations are only read when the configured ones change
+	if r.client != nil && migrationsChanged(ctx, plan, config, state) {
+		resp.Diagnostics.Append(r.validateMigrations(ctx, plan.AccountID, config)...)
+	}
+	migrations, diags := planMigrations(ctx, config, state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("migrations"), migrations)...)
+	if resp.Diagnostics.HasError() || plan.Parts.IsNull() || plan.Parts.IsUnknown() {
+		return
+	}
 
+	parts := make(map[string]WorkersScriptPartModel)
+	resp.Diagnostics.Append(plan.Parts.ElementsAs(ctx, &parts, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// hash the content of every part at plan time, so that changes to files
+	// on disk show up in the plan
+	for name, part := range parts {
+		if part.hasUnknownSource() {
+			part.ContentHash = types.StringUnknown()
+			parts[name] = part
+			continue
+		}
+
+		files, err := part.files(name)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("parts").AtMapKey(name), "failed to read script part", err.Error())
+			continue
+		}
+		part.ContentHash = types.StringValue(contentHash(files, part.fromDirectory()))
+		parts[name] = part
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	planParts, diags := customfield.NewObjectMap(ctx, parts)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("parts"), planParts)...)
+
+	if resp.Diagnostics.HasError() || state == nil || planParts.Equal(state.Parts) {
+		return
+	}
+
+	// a change to the content of a part only shows up here, after the
+	// framework decided which computed attributes the update can change
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("etag"), types.StringUnknown())...)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("modified_on"), timetypes.NewRFC3339Unknown())...)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("startup_time_ms"), types.Int64Unknown())...)
+	if config.TailConsumers.IsNull() {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("tail_consumers"), customfield.UnknownObjectSet[WorkersScriptTailConsumersModel](ctx))...)
+	}
+	if config.Logpush.IsNull() {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("logpush"), types.BoolUnknown())...)
+	}
+	if config.PlacementMode.IsNull() {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("placement_mode"), types.StringUnknown())...)
+	}
+	if config.UsageModel.IsNull() {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("usage_model"), types.StringUnknown())...)
+	}
 }
 
 func (r *WorkersScriptResource) handleUpdate(ctx context.Context, data *WorkersScriptModel, diags *diag.Diagnostics) {
@@ -264,6 +378,36 @@ func (r *WorkersScriptResource) handleUpdate(ctx context.Context, data *WorkersS
 	updateModelFromResponse(ctx, data, created)
 }
 
+// waitForScript waits until a new script can be read. Uploads take a while to
+// propagate, and resources referencing the script, such as queue consumers,
+// are rejected until they have.
+func (r *WorkersScriptResource) waitForScript(ctx context.Context, data *WorkersScriptModel) error {
+	conf := waiter.StateChangeConf{
+		Description: fmt.Sprintf("worker script %q", data.ScriptName.ValueString()),
+		Pending:     []string{"propagating"},
+		Target:      []string{"available"},
+		Timeout:     scriptPropagationTimeout,
+		Refresh: func(ctx context.Context) (any, string, error) {
+			_, err := r.client.Workers.Scripts.Settings.Get(
+				ctx,
+				data.ScriptName.ValueString(),
+				workers.ScriptSettingGetParams{AccountID: cloudflare.F(data.AccountID.ValueString())},
+				option.WithMiddleware(logging.Middleware(ctx)),
+			)
+			if utils.IsNotFound(err, scriptNotFoundErrorCode) {
+				return nil, "propagating", nil
+			}
+			if err != nil {
+				return nil, "", err
+			}
+			return nil, "available", nil
+		},
+	}
+
+	_, err := conf.WaitForState(ctx)
+	return err
+}
+
 func updateModelFromResponse(ctx context.Context, model *WorkersScriptModel, res *workers.ScriptUpdateResponse) {
 	model.Etag = types.StringValue(res.Etag)
 	model.ID = types.StringValue(res.ID)
@@ -287,3 +431,107 @@ func updateModelFromResponse(ctx context.Context, model *WorkersScriptModel, res
 
 	model.TailConsumers = customfield.NewObjectSetMust(ctx, tailConsumerModels)
 }
+
+// updateModelFromSettings refreshes the attributes of model that are reported
+// by the script settings endpoint. Empty collections are stored as null, which
+// is how they are represented when omitted from the configuration.
+func updateModelFromSettings(ctx context.Context, model *WorkersScriptModel, settings WorkersScriptMetadataModel) (diags diag.Diagnostics) {
+	bindings, d := settings.Bindings.AsStructSliceT(ctx)
+	diags.Append(d...)
+	if diags.HasError() {
+		return
+	}
+	priorBindings, d := model.Bindings.AsStructSliceT(ctx)
+	diags.Append(d...)
+	if diags.HasError() {
+		return
+	}
+	model.Bindings = customfield.NullObjectSet[WorkersScriptBindingsModel](ctx)
+	if len(bindings) > 0 {
+		for i := range bindings {
+			bindings[i] = bindings[i].fromAPI()
+			keepUnsetBindingAttributes(&bindings[i], priorBindings)
+		}
+		model.Bindings = customfield.NewObjectSetMust(ctx, bindings)
+	}
+
+	model.CompatibilityDate = types.StringNull()
+	if settings.CompatibilityDate.ValueString() != "" {
+		model.CompatibilityDate = settings.CompatibilityDate
+	}
+
+	model.CompatibilityFlags = customfield.NullSet[types.String](ctx)
+	if len(settings.CompatibilityFlags.Elements()) > 0 {
+		model.CompatibilityFlags = customfield.NewSetMust[types.String](ctx, settings.CompatibilityFlags.Elements())
+	}
+
+	model.Tags = customfield.NullSet[types.String](ctx)
+	if len(settings.Tags.Elements()) > 0 {
+		model.Tags = customfield.NewSetMust[types.String](ctx, settings.Tags.Elements())
+	}
+
+	model.Logpush = types.BoolValue(settings.Logpush.ValueBool())
+	model.UsageModel = settings.UsageModel
+
+	tailConsumers, d := settings.TailConsumers.AsStructSliceT(ctx)
+	diags.Append(d...)
+	if diags.HasError() {
+		return
+	}
+	model.TailConsumers = customfield.NewObjectSetMust(ctx, tailConsumers)
+
+	placement := WorkersScriptMetadataPlacementModel{}
+	diags.Append(settings.Placement.As(ctx, &placement, basetypes.ObjectAsOptions{UnhandledNullAsEmpty: true, UnhandledUnknownAsEmpty: true})...)
+	if diags.HasError() {
+		return
+	}
+	model.PlacementMode = types.StringValue(placement.Mode.ValueString())
+
+	return
+}
+
+// keepUnsetBindingAttributes nulls out attributes the API fills in with
+// defaults (e.g. the environment of a service binding) when the matching
+// binding in prior state left them unset, so they don't show up as drift.
+// Secret text values, which the API never returns, are taken from prior state.
+func keepUnsetBindingAttributes(binding *WorkersScriptBindingsModel, prior []WorkersScriptBindingsModel) {
+	for _, p := range prior {
+		if !p.Name.Equal(binding.Name) || !p.Type.Equal(binding.Type) {
+			continue
+		}
+
+		// d1 bindings set with the deprecated `id` keep it
+		if binding.Type.ValueString() == "d1" && p.DatabaseID.IsNull() && !p.ID.IsNull() {
+			binding.ID, binding.DatabaseID = binding.DatabaseID, types.StringNull()
+		}
+
+		for _, attr := range []struct{ prior, current *types.String }{
+			{&p.BucketName, &binding.BucketName},
+			{&p.Service, &binding.Service},
+			{&p.Environment, &binding.Environment},
+			{&p.ClassName, &binding.ClassName},
+			{&p.ScriptName, &binding.ScriptName},
+			{&p.QueueName, &binding.QueueName},
+			{&p.ID, &binding.ID},
+			{&p.CertificateID, &binding.CertificateID},
+			{&p.Text, &binding.Text},
+			{&p.NamespaceID, &binding.NamespaceID},
+			{&p.DatabaseID, &binding.DatabaseID},
+			{&p.IndexName, &binding.IndexName},
+			{&p.Dataset, &binding.Dataset},
+			{&p.Namespace, &binding.Namespace},
+		} {
+			if attr.prior.IsNull() {
+				*attr.current = types.StringNull()
+			}
+		}
+		if p.Json.IsNull() {
+			binding.Json = jsontypes.NewNormalizedNull()
+		}
+		// the API never returns the value of a secret, keep the one in state
+		if binding.Type.ValueString() == "secret_text" {
+			binding.Text = p.Text
+		}
+		return
+	}
+}
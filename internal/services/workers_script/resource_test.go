@@ -1,13 +1,21 @@
 package workers_script_test
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
+	"net/http"
 	"os"
+	"path/filepath"
+	"regexp"
 	"testing"
 
 	cfv1 "github.com/cloudflare/cloudflare-go"
 	"github.com/cloudflare/cloudflare-go/v3"
+	"github.com/cloudflare/cloudflare-go/v3/option"
 	"github.com/cloudflare/cloudflare-go/v3/workers"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
@@ -21,45 +29,430 @@ const (
 	moduleContent2 = `export default { fetch() { return new Response('Hello world 2'); }, };`
 )
 
+func TestMain(m *testing.M) {
+	resource.TestMain(m)
+}
+
+func init() {
+	acctest.AddTestSweepers("cloudflare-extended_workers_script")
+}
+
 func TestAccCloudflareWorkerScript_ScriptEnt(t *testing.T) {
-	t.Parallel()
+	acctest.Parallel(t)
 
-	rnd := utils.GenerateRandomResourceName()
+	rnd := acctest.RandomResourceName(t)
 	name := "cloudflare-extended_workers_script." + rnd
 	accountID := os.Getenv("CLOUDFLARE_ACCOUNT_ID")
 	bucketName := os.Getenv("R2_BUCKET_NAME")
-
-	if bucketName == "" {
-		t.Fatal("R2_BUCKET_NAME must be set for this acceptance test")
-	}
+	httpClient := acctest.HTTPClient(t)
 
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			acctest.TestAccPreCheck(t)
 			acctest.TestAccPreCheck_AccountID(t)
+			if bucketName == "" {
+				t.Fatal("R2_BUCKET_NAME must be set for this acceptance test")
+			}
 		},
-		ProtoV6ProviderFactories: acctest.TestAccProtoV6ProviderFactories,
-		CheckDestroy:             testAccCheckCloudflareWorkerScriptDestroy,
+		ProtoV6ProviderFactories: acctest.TestAccProtoV6ProviderFactories(t),
+		CheckDestroy:             testAccCheckCloudflareWorkerScriptDestroy(httpClient),
 		Steps: []resource.TestStep{
 			{
 				Config: testAccCheckCloudflareWorkerScriptConfigScriptInitial(rnd, accountID),
 				Check: resource.ComposeTestCheckFunc(
-					testAccCheckCloudflareWorkerScriptExists(name, nil),
+					testAccCheckCloudflareWorkerScriptExists(httpClient, name, nil),
 					resource.TestCheckResourceAttr(name, "script_name", rnd),
 				),
 			},
 			{
 				Config: testAccCheckCloudflareWorkerScriptConfigScriptUpdate(rnd, accountID),
 				Check: resource.ComposeTestCheckFunc(
-					testAccCheckCloudflareWorkerScriptExists(name, nil),
+					testAccCheckCloudflareWorkerScriptExists(httpClient, name, nil),
 					resource.TestCheckResourceAttr(name, "script_name", rnd),
 				),
 			},
 			{
 				Config: testAccCheckCloudflareWorkerScriptConfigScriptUpdateBinding(rnd, accountID, bucketName),
 				Check: resource.ComposeTestCheckFunc(
-					testAccCheckCloudflareWorkerScriptExists(name, []string{"ai", "bucket"}),
+					testAccCheckCloudflareWorkerScriptExists(httpClient, name, []string{"ai", "bucket"}),
+					resource.TestCheckResourceAttr(name, "script_name", rnd),
+				),
+			},
+		},
+	})
+}
+
+func TestCloudflareWorkerScript_Offline(t *testing.T) {
+	srv := acctest.NewOfflineTest(t)
+	rnd := utils.GenerateRandomResourceName()
+	name := "cloudflare-extended_workers_script." + rnd
+	accountID := srv.AccountID
+	provider := srv.ProviderConfig()
+
+	srv.Test(resource.TestCase{
+		Steps: []resource.TestStep{
+			{
+				Config: provider + testAccCheckCloudflareWorkerScriptConfigScriptInitial(rnd, accountID),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "script_name", rnd),
+					resource.TestCheckResourceAttr(name, "id", rnd),
+				),
+			},
+			{
+				Config: provider + testAccCheckCloudflareWorkerScriptConfigScriptUpdate(rnd, accountID),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "compatibility_date", "2024-10-22"),
+					resource.TestCheckResourceAttr(name, "compatibility_flags.#", "1"),
+				),
+			},
+			{
+				Config: provider + testAccCheckCloudflareWorkerScriptConfigScriptUpdateBinding(rnd, accountID, "bucket"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "bindings.#", "2"),
+				),
+			},
+			{
+				ResourceName:                         name,
+				ImportState:                          true,
+				ImportStateId:                        fmt.Sprintf("%s/%s", accountID, rnd),
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "script_name",
+				ImportStateVerifyIgnore:              []string{"created_on", "etag", "modified_on", "startup_time_ms"},
+			},
+			{
+				PreConfig: func() {
+					// simulate a `wrangler deploy` replacing the script and its settings
+					body := "--boundary\r\n" +
+						"Content-Disposition: form-data; name=\"metadata\"\r\n\r\n" +
+						`{"main_module":"` + rnd + `","compatibility_date":"2024-11-01"}` + "\r\n" +
+						"--boundary\r\n" +
+						"Content-Disposition: form-data; name=\"" + rnd + "\"; filename=\"" + rnd + "\"\r\n" +
+						"Content-Type: text/javascript+module\r\n\r\n" +
+						moduleContent1 + "\r\n" +
+						"--boundary--\r\n"
+					_, err := srv.APIClient().Workers.Scripts.Update(
+						context.Background(),
+						rnd,
+						workers.ScriptUpdateParams{AccountID: cloudflare.F(accountID)},
+						option.WithRequestBody("multipart/form-data; boundary=boundary", []byte(body)),
+					)
+					if err != nil {
+						t.Fatalf("failed to deploy worker script out-of-band: %s", err)
+					}
+				},
+				Config:             provider + testAccCheckCloudflareWorkerScriptConfigScriptUpdateBinding(rnd, accountID, "bucket"),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: provider + testAccCheckCloudflareWorkerScriptConfigScriptUpdateBinding(rnd, accountID, "bucket"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "compatibility_date", "2024-10-22"),
+					resource.TestCheckResourceAttr(name, "bindings.#", "2"),
+					resource.TestCheckResourceAttr(name, fmt.Sprintf("parts.%s.part", rnd), moduleContent2),
+				),
+			},
+			{
+				PreConfig: func() {
+					err := srv.APIClient().Workers.Scripts.Delete(
+						context.Background(),
+						rnd,
+						workers.ScriptDeleteParams{AccountID: cloudflare.F(accountID)},
+					)
+					if err != nil {
+						t.Fatalf("failed to delete worker script out-of-band: %s", err)
+					}
+				},
+				Config:             provider + testAccCheckCloudflareWorkerScriptConfigScriptUpdateBinding(rnd, accountID, "bucket"),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestCloudflareWorkerScript_OfflineBindings(t *testing.T) {
+	srv := acctest.NewOfflineTest(t)
+	rnd := utils.GenerateRandomResourceName()
+	name := "cloudflare-extended_workers_script." + rnd
+	accountID := srv.AccountID
+	provider := srv.ProviderConfig()
+
+	srv.Test(resource.TestCase{
+		Steps: []resource.TestStep{
+			{
+				Config:      provider + testAccCheckCloudflareWorkerScriptConfigScriptInvalidBinding(rnd, accountID),
+				ExpectError: regexp.MustCompile(`(?s)Binding "bucket" of type "r2_bucket" does not support.*"namespace_id"`),
+			},
+			{
+				Config: provider + testAccCheckCloudflareWorkerScriptConfigScriptAllBindings(rnd, accountID),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "bindings.#", "9"),
+					resource.TestCheckTypeSetElemNestedAttrs(name, "bindings.*", map[string]string{
+						"name": "SECRET",
+						"type": "secret_text",
+						"text": "hunter2",
+					}),
+					resource.TestCheckTypeSetElemNestedAttrs(name, "bindings.*", map[string]string{
+						"name":        "DB",
+						"type":        "d1",
+						"database_id": accountID,
+					}),
+					resource.TestCheckTypeSetElemNestedAttrs(name, "bindings.*", map[string]string{
+						"name": "CONFIG",
+						"type": "json",
+						"json": `{"enabled":true}`,
+					}),
+				),
+			},
+			{
+				// the deprecated database ID attribute of d1 bindings
+				Config: provider + testAccCheckCloudflareWorkerScriptConfigScriptD1ID(rnd, accountID),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "bindings.#", "1"),
+					resource.TestCheckTypeSetElemNestedAttrs(name, "bindings.*", map[string]string{
+						"name": "DB",
+						"type": "d1",
+						"id":   accountID,
+					}),
+					resource.TestCheckNoResourceAttr(name, "bindings.0.database_id"),
+				),
+			},
+		},
+	})
+}
+
+func TestCloudflareWorkerScript_OfflineSourcePath(t *testing.T) {
+	srv := acctest.NewOfflineTest(t)
+	rnd := utils.GenerateRandomResourceName()
+	name := "cloudflare-extended_workers_script." + rnd
+	accountID := srv.AccountID
+	provider := srv.ProviderConfig()
+
+	dir := t.TempDir()
+	sourcePath := filepath.Join(dir, "index.js")
+	assets := filepath.Join(dir, "assets")
+	writeFile(t, sourcePath, moduleContent1)
+	writeFile(t, filepath.Join(assets, "greeting.txt"), "hello")
+	writeFile(t, filepath.Join(assets, "add.wasm"), "\x00asm")
+
+	expectPart := func(partName, contentType, content string) resource.TestCheckFunc {
+		return func(*terraform.State) error {
+			gotType, gotContent, ok := srv.WorkerScriptPart(accountID, rnd, partName)
+			if !ok {
+				return fmt.Errorf("part %s was not uploaded", partName)
+			}
+			if gotType != contentType || string(gotContent) != content {
+				return fmt.Errorf("part %s: got %s %q, want %s %q", partName, gotType, gotContent, contentType, content)
+			}
+			return nil
+		}
+	}
+
+	srv.Test(resource.TestCase{
+		Steps: []resource.TestStep{
+			{
+				Config:      provider + testAccCheckCloudflareWorkerScriptConfigScriptSourcePath(rnd, accountID, sourcePath, assets, "**/*"),
+				ExpectError: regexp.MustCompile("`\\*\\*`, which is not supported"),
+			},
+			{
+				Config: provider + testAccCheckCloudflareWorkerScriptConfigScriptSourcePath(rnd, accountID, sourcePath, assets, "*"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckNoResourceAttr(name, "parts.index.js.part"),
+					resource.TestCheckResourceAttr(name, "parts.index.js.content_hash", sha256Hex(moduleContent1)),
+					resource.TestCheckResourceAttrSet(name, "parts.assets.content_hash"),
+					expectPart("index.js", "text/javascript+module", moduleContent1),
+					expectPart("greeting.txt", "text/plain", "hello"),
+					expectPart("add.wasm", "application/wasm", "\x00asm"),
+				),
+			},
+			{
+				PreConfig:          func() { writeFile(t, sourcePath, moduleContent2) },
+				Config:             provider + testAccCheckCloudflareWorkerScriptConfigScriptSourcePath(rnd, accountID, sourcePath, assets, "*"),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: provider + testAccCheckCloudflareWorkerScriptConfigScriptSourcePath(rnd, accountID, sourcePath, assets, "*"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "parts.index.js.content_hash", sha256Hex(moduleContent2)),
+					expectPart("index.js", "text/javascript+module", moduleContent2),
+				),
+			},
+			{
+				PreConfig:          func() { writeFile(t, filepath.Join(assets, "farewell.txt"), "bye") },
+				Config:             provider + testAccCheckCloudflareWorkerScriptConfigScriptSourcePath(rnd, accountID, sourcePath, assets, "*"),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: provider + testAccCheckCloudflareWorkerScriptConfigScriptSourcePath(rnd, accountID, sourcePath, assets, "*"),
+				Check: resource.ComposeTestCheckFunc(
+					expectPart("farewell.txt", "text/plain", "bye"),
+				),
+			},
+		},
+	})
+}
+
+func TestCloudflareWorkerScript_OfflineModuleTypes(t *testing.T) {
+	srv := acctest.NewOfflineTest(t)
+	rnd := utils.GenerateRandomResourceName()
+	name := "cloudflare-extended_workers_script." + rnd
+	accountID := srv.AccountID
+	provider := srv.ProviderConfig()
+
+	// a WebAssembly header followed by a byte that isn't valid UTF-8
+	wasm := []byte("\x00asm\x01\x00\x00\x00\xff")
+	config := provider + testAccCheckCloudflareWorkerScriptConfigScriptModuleTypes(rnd, accountID, base64.StdEncoding.EncodeToString(wasm))
+
+	expectPart := func(partName, contentType string, content []byte) resource.TestCheckFunc {
+		return func(*terraform.State) error {
+			gotType, gotContent, ok := srv.WorkerScriptPart(accountID, rnd, partName)
+			if !ok {
+				return fmt.Errorf("part %s was not uploaded", partName)
+			}
+			if gotType != contentType || !bytes.Equal(gotContent, content) {
+				return fmt.Errorf("part %s: got %s %q, want %s %q", partName, gotType, gotContent, contentType, content)
+			}
+			return nil
+		}
+	}
+
+	srv.Test(resource.TestCase{
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(name, "main_module", "index.py"),
+					expectPart("add.wasm", "application/wasm", wasm),
+					expectPart("index.js.map", "application/source-map", []byte(`{"version":3,"sources":[],"mappings":""}`)),
+					expectPart("data", "application/octet-stream", []byte("raw data")),
+					func(*terraform.State) error {
+						if gotType, _, _ := srv.WorkerScriptPart(accountID, rnd, "index.py"); gotType != "text/x-python" {
+							return fmt.Errorf("index.py uploaded as %s", gotType)
+						}
+						return nil
+					},
+				),
+			},
+			{
+				ResourceName:                         name,
+				ImportState:                          true,
+				ImportStateId:                        fmt.Sprintf("%s/%s", accountID, rnd),
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "script_name",
+				// the content type of a part is only tracked once configured
+				ImportStateVerifyIgnore: []string{"created_on", "etag", "modified_on", "startup_time_ms", "parts.data.content_type"},
+			},
+		},
+	})
+}
+
+func TestCloudflareWorkerScript_OfflineMigrations(t *testing.T) {
+	srv := acctest.NewOfflineTest(t)
+	rnd := utils.GenerateRandomResourceName()
+	accountID := srv.AccountID
+	provider := srv.ProviderConfig()
+
+	expectTag := func(tag string) resource.TestCheckFunc {
+		return func(*terraform.State) error {
+			if got := srv.WorkerScriptMigrationTag(accountID, rnd); got != tag {
+				return fmt.Errorf("expected migration tag %q, got %q", tag, got)
+			}
+			return nil
+		}
+	}
+
+	srv.Test(resource.TestCase{
+		Steps: []resource.TestStep{
+			{
+				Config: provider + testAccCheckCloudflareWorkerScriptConfigScriptMigrations(rnd, accountID, `{
+					new_tag     = "v1"
+					new_classes = ["Counter"]
+				}`),
+				Check: expectTag("v1"),
+			},
+			{
+				Config: provider + testAccCheckCloudflareWorkerScriptConfigScriptMigrations(rnd, accountID, `{
+					old_tag     = "v0"
+					new_tag     = "v2"
+					new_classes = ["Other"]
+				}`),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`migration old_tag does not match the deployed migration tag`),
+			},
+			{
+				Config: provider + testAccCheckCloudflareWorkerScriptConfigScriptMigrations(rnd, accountID, `{
+					old_tag     = "v1"
+					new_tag     = "v2"
+					new_classes = ["Counter"]
+				}`),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`Class "Counter" cannot be created, it already exists`),
+			},
+			{
+				Config: provider + testAccCheckCloudflareWorkerScriptConfigScriptMigrations(rnd, accountID, `{
+					old_tag         = "v1"
+					new_tag         = "v2"
+					renamed_classes = [{ from = "Missing", to = "Renamed" }]
+				}`),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`Class "Missing" cannot be renamed, it does not exist`),
+			},
+			{
+				Config: provider + testAccCheckCloudflareWorkerScriptConfigScriptMigrations(rnd, accountID, `{
+					old_tag = "v1"
+					new_tag = "v2"
+					steps = [
+						{ renamed_classes = [{ from = "Counter", to = "Tally" }] },
+						{ deleted_classes = ["Tally"] },
+					]
+				}`),
+				Check: expectTag("v2"),
+			},
+		},
+	})
+}
+
+func writeFile(t *testing.T, name, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func sha256Hex(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestCloudflareWorkerScript_OfflineWaitForScript(t *testing.T) {
+	srv := acctest.NewOfflineTest(t)
+	rnd := utils.GenerateRandomResourceName()
+	name := "cloudflare-extended_workers_script." + rnd
+	accountID := srv.AccountID
+	provider := srv.ProviderConfig()
+
+	srv.Test(resource.TestCase{
+		Steps: []resource.TestStep{
+			{
+				// the uploaded script is not readable right away
+				PreConfig: func() {
+					srv.FailNextRequestsWithMethod(http.MethodGet, 2, http.StatusNotFound)
+				},
+				Config: provider + testAccCheckCloudflareWorkerScriptConfigScriptInitial(rnd, accountID),
+				Check: resource.ComposeTestCheckFunc(
 					resource.TestCheckResourceAttr(name, "script_name", rnd),
+					func(s *terraform.State) error {
+						if n := srv.PendingFailures(); n != 0 {
+							return fmt.Errorf("%d injected failures were not waited out", n)
+						}
+						return nil
+					},
 				),
 			},
 		},
@@ -78,7 +471,31 @@ func testAccCheckCloudflareWorkerScriptConfigScriptUpdateBinding(rnd, accountID,
 	return acctest.LoadTestCase("workerscriptconfigscriptupdatebinding.tf", rnd, accountID, moduleContent2, bucketName)
 }
 
-func testAccCheckCloudflareWorkerScriptExists(n string, bindings []string) resource.TestCheckFunc {
+func testAccCheckCloudflareWorkerScriptConfigScriptAllBindings(rnd, accountID string) string {
+	return acctest.LoadTestCase("workerscriptconfigscriptallbindings.tf", rnd, accountID, moduleContent1, accountID)
+}
+
+func testAccCheckCloudflareWorkerScriptConfigScriptD1ID(rnd, accountID string) string {
+	return acctest.LoadTestCase("workerscriptconfigscriptd1id.tf", rnd, accountID, moduleContent1)
+}
+
+func testAccCheckCloudflareWorkerScriptConfigScriptSourcePath(rnd, accountID, sourcePath, directory, glob string) string {
+	return acctest.LoadTestCase("workerscriptconfigscriptsourcepath.tf", rnd, accountID, sourcePath, directory, glob)
+}
+
+func testAccCheckCloudflareWorkerScriptConfigScriptModuleTypes(rnd, accountID, wasm string) string {
+	return acctest.LoadTestCase("workerscriptconfigscriptmoduletypes.tf", rnd, accountID, wasm)
+}
+
+func testAccCheckCloudflareWorkerScriptConfigScriptMigrations(rnd, accountID, migrations string) string {
+	return acctest.LoadTestCase("workerscriptconfigscriptmigrations.tf", rnd, accountID, moduleContent1, migrations)
+}
+
+func testAccCheckCloudflareWorkerScriptConfigScriptInvalidBinding(rnd, accountID string) string {
+	return acctest.LoadTestCase("workerscriptconfigscriptinvalidbinding.tf", rnd, accountID, moduleContent1)
+}
+
+func testAccCheckCloudflareWorkerScriptExists(httpClient *http.Client, n string, bindings []string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		accountID := os.Getenv("CLOUDFLARE_ACCOUNT_ID")
 
@@ -90,7 +507,7 @@ func testAccCheckCloudflareWorkerScriptExists(n string, bindings []string) resou
 		if rs.Primary.ID == "" {
 			return fmt.Errorf("No Worker Script ID is set")
 		}
-		client := cloudflare.NewClient()
+		client := cloudflare.NewClient(option.WithHTTPClient(httpClient))
 
 		r, err := client.Workers.Scripts.Settings.Get(context.Background(), rs.Primary.ID, workers.ScriptSettingGetParams{AccountID: cloudflare.F(accountID)})
 		if err != nil {
@@ -101,7 +518,7 @@ func testAccCheckCloudflareWorkerScriptExists(n string, bindings []string) resou
 			return fmt.Errorf("Worker Script not found")
 		}
 
-		foundBindings, err := getWorkerScriptBindings(context.Background(), accountID, rs.Primary.ID, nil)
+		foundBindings, err := getWorkerScriptBindings(context.Background(), httpClient, accountID, rs.Primary.ID, nil)
 		if err != nil {
 			return fmt.Errorf("cannot list script bindings: %w", err)
 		}
@@ -116,31 +533,33 @@ func testAccCheckCloudflareWorkerScriptExists(n string, bindings []string) resou
 	}
 }
 
-func testAccCheckCloudflareWorkerScriptDestroy(s *terraform.State) error {
-	accountID := os.Getenv("CLOUDFLARE_ACCOUNT_ID")
-	client, err := cfv1.NewWithAPIToken(os.Getenv("CLOUDFLARE_API_TOKEN"))
-	if err != nil {
-		tflog.Error(context.TODO(), fmt.Sprintf("failed to create Cloudflare client: %s", err))
-	}
-
-	for _, rs := range s.RootModule().Resources {
-		if rs.Type != "cloudflare-extended_workers_script" {
-			continue
+func testAccCheckCloudflareWorkerScriptDestroy(httpClient *http.Client) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		accountID := os.Getenv("CLOUDFLARE_ACCOUNT_ID")
+		client, err := cfv1.NewWithAPIToken(os.Getenv("CLOUDFLARE_API_TOKEN"), cfv1.HTTPClient(httpClient))
+		if err != nil {
+			tflog.Error(context.TODO(), fmt.Sprintf("failed to create Cloudflare client: %s", err))
 		}
 
-		r, _ := client.GetWorker(context.Background(), cfv1.AccountIdentifier(accountID), rs.Primary.ID)
-		if r.Script != "" {
-			return fmt.Errorf("worker script with id %s still exists", rs.Primary.ID)
+		for _, rs := range s.RootModule().Resources {
+			if rs.Type != "cloudflare-extended_workers_script" {
+				continue
+			}
+
+			r, _ := client.GetWorker(context.Background(), cfv1.AccountIdentifier(accountID), rs.Primary.ID)
+			if r.Script != "" {
+				return fmt.Errorf("worker script with id %s still exists", rs.Primary.ID)
+			}
 		}
-	}
 
-	return nil
+		return nil
+	}
 }
 
 type ScriptBindings map[string]cfv1.WorkerBinding
 
-func getWorkerScriptBindings(ctx context.Context, accountId, scriptName string, dispatchNamespace *string) (ScriptBindings, error) {
-	client, err := cfv1.NewWithAPIToken(os.Getenv("CLOUDFLARE_API_TOKEN"))
+func getWorkerScriptBindings(ctx context.Context, httpClient *http.Client, accountId, scriptName string, dispatchNamespace *string) (ScriptBindings, error) {
+	client, err := cfv1.NewWithAPIToken(os.Getenv("CLOUDFLARE_API_TOKEN"), cfv1.HTTPClient(httpClient))
 	if err != nil {
 		return nil, err
 	}
@@ -3,8 +3,10 @@ package workers_script
 import (
 	"context"
 
+	"github.com/hashicorp/terraform-plugin-framework-jsontypes/jsontypes"
 	"github.com/hashicorp/terraform-plugin-framework-timetypes/timetypes"
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
@@ -29,9 +31,10 @@ func ResourceSchema(ctx context.Context) schema.Schema {
 				PlanModifiers: []planmodifier.String{stringplanmodifier.UseStateForUnknown(), stringplanmodifier.RequiresReplace()},
 			},
 			"account_id": schema.StringAttribute{
-				Description:   "Identifier",
-				Required:      true,
-				PlanModifiers: []planmodifier.String{stringplanmodifier.RequiresReplace()},
+				Description:   "Identifier. Defaults to the `account_id` of the provider.",
+				Optional:      true,
+				Computed:      true,
+				PlanModifiers: []planmodifier.String{stringplanmodifier.UseStateForUnknown(), stringplanmodifier.RequiresReplace()},
 			},
 			"message": schema.StringAttribute{
 				Description: "Rollback message to be associated with this deployment. Only parsed when query param `\"rollback_to\"` is present.",
@@ -44,13 +47,52 @@ func ResourceSchema(ctx context.Context) schema.Schema {
 				NestedObject: schema.NestedAttributeObject{
 					Attributes: map[string]schema.Attribute{
 						"part": schema.StringAttribute{
-							Description: "Script content.",
-							Required:    true,
+							Description: "Script content. Exactly one of `part`, `content_base64`, `source_path` or `directory` must be set.",
+							Optional:    true,
+							Validators: []validator.String{
+								stringvalidator.ExactlyOneOf(
+									path.MatchRelative().AtParent().AtName("content_base64"),
+									path.MatchRelative().AtParent().AtName("source_path"),
+									path.MatchRelative().AtParent().AtName("directory"),
+								),
+							},
+						},
+						"content_base64": schema.StringAttribute{
+							Description: "Base64 encoded content, for binary parts such as WebAssembly modules.",
+							Optional:    true,
+						},
+						"source_path": schema.StringAttribute{
+							Description: "Path to a file to upload as the script content. Only the SHA-256 of the file is stored in state.",
+							Optional:    true,
+						},
+						"directory": schema.StringAttribute{
+							Description: "Path to a directory whose files matching `glob` are uploaded, each as a part named by its path relative to the directory. Only the SHA-256 of the files is stored in state.",
+							Optional:    true,
+							Validators: []validator.String{
+								stringvalidator.AlsoRequires(path.MatchRelative().AtParent().AtName("glob")),
+							},
+						},
+						"glob": schema.StringAttribute{
+							Description: "Pattern matched against the paths relative to `directory` of the files to upload, e.g. `*.js`. `*` does not match `/`, so files in subdirectories are matched with a pattern per level, e.g. `*/*.js`. `**` is not supported.",
+							Optional:    true,
+							Validators: []validator.String{
+								stringvalidator.AlsoRequires(path.MatchRelative().AtParent().AtName("directory")),
+								globValidator{},
+							},
+						},
+						"content_type": schema.StringAttribute{
+							Description: "Content type of the part. Inferred from the extension of the part name when unset: `.wasm` as `application/wasm`, `.map` as `application/source-map`, `.txt` and `.html` as `text/plain`, `.bin` as `application/octet-stream`, `.py` as `text/x-python` and JavaScript depending on `module`.",
+							Optional:    true,
+							Validators:  []validator.String{stringvalidator.OneOf(partContentTypes...)},
 						},
 						"module": schema.BoolAttribute{
 							Description: "True if the script part is a javascript module.",
 							Optional:    true,
 						},
+						"content_hash": schema.StringAttribute{
+							Description: "SHA-256 of the content of the part, used to detect changes.",
+							Computed:    true,
+						},
 					},
 				},
 			},
@@ -67,6 +109,7 @@ func ResourceSchema(ctx context.Context) schema.Schema {
 						"type": schema.StringAttribute{
 							Description: "Type of binding. You can find more about bindings on our docs: https://developers.cloudflare.com/workers/configuration/multipart-upload-metadata/#bindings.",
 							Optional:    true,
+							Validators:  []validator.String{stringvalidator.OneOf(bindingSpecs.Types()...)},
 						},
 						"bucket_name": schema.StringAttribute{
 							Description: "Name of the R2 Bucket for R2 Bindings.",
@@ -93,13 +136,43 @@ func ResourceSchema(ctx context.Context) schema.Schema {
 							Optional:    true,
 						},
 						"id": schema.StringAttribute{
-							Description: "ID of the D1 database to bind to.",
+							Description: "ID of the Hyperdrive configuration to bind to. Deprecated for D1 bindings, which set `database_id` instead.",
 							Optional:    true,
 						},
 						"certificate_id": schema.StringAttribute{
 							Description: "ID of the certificate to bind to.",
 							Optional:    true,
 						},
+						"text": schema.StringAttribute{
+							Description: "The text value to use for plain text and secret text bindings. Secret text values are never returned by the API, so changes made outside of Terraform are not detected.",
+							Optional:    true,
+							Sensitive:   true,
+						},
+						"namespace_id": schema.StringAttribute{
+							Description: "ID of the KV namespace to bind to.",
+							Optional:    true,
+						},
+						"database_id": schema.StringAttribute{
+							Description: "ID of the D1 database to bind to.",
+							Optional:    true,
+						},
+						"index_name": schema.StringAttribute{
+							Description: "Name of the Vectorize index to bind to.",
+							Optional:    true,
+						},
+						"dataset": schema.StringAttribute{
+							Description: "The dataset name to bind to for Analytics Engine bindings.",
+							Optional:    true,
+						},
+						"namespace": schema.StringAttribute{
+							Description: "Name of the dispatch namespace to bind to.",
+							Optional:    true,
+						},
+						"json": schema.StringAttribute{
+							Description: "JSON encoded value to use for JSON bindings.",
+							Optional:    true,
+							CustomType:  jsontypes.NormalizedType{},
+						},
 					},
 				},
 			},
@@ -335,5 +408,7 @@ func (r *WorkersScriptResource) Schema(ctx context.Context, req resource.SchemaR
 }
 
 func (r *WorkersScriptResource) ConfigValidators(_ context.Context) []resource.ConfigValidator {
-	return []resource.ConfigValidator{}
+	return []resource.ConfigValidator{
+		bindingsValidator{},
+	}
 }
@@ -0,0 +1,60 @@
+resource "cloudflare-extended_workers_script" "%[1]s" {
+  account_id  = "%[2]s"
+  script_name = "%[1]s"
+  main_module = "%[1]s"
+
+  parts = {
+    %[1]s = {
+      part   = "%[3]s"
+      module = true
+    }
+  }
+
+  bindings = [
+    {
+      name = "PLAIN"
+      type = "plain_text"
+      text = "hello"
+    },
+    {
+      name = "SECRET"
+      type = "secret_text"
+      text = "hunter2"
+    },
+    {
+      name         = "KV"
+      type         = "kv_namespace"
+      namespace_id = "%[4]s"
+    },
+    {
+      name        = "DB"
+      type        = "d1"
+      database_id = "%[4]s"
+    },
+    {
+      name       = "INDEX"
+      type       = "vectorize"
+      index_name = "%[1]s"
+    },
+    {
+      name    = "ANALYTICS"
+      type    = "analytics_engine"
+      dataset = "%[1]s"
+    },
+    {
+      name = "CONFIG"
+      type = "json"
+      json = jsonencode({ enabled = true })
+    },
+    {
+      name = "VERSION"
+      type = "version_metadata"
+    },
+    {
+      name        = "SERVICE"
+      type        = "service"
+      service     = "%[1]s"
+      environment = "production"
+    },
+  ]
+}
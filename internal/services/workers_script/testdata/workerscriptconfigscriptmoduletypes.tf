@@ -0,0 +1,22 @@
+resource "cloudflare-extended_workers_script" "%[1]s" {
+  account_id         = "%[2]s"
+  script_name        = "%[1]s"
+  main_module        = "index.py"
+  compatibility_date = "2024-10-22"
+
+  parts = {
+    "index.py" = {
+      part = "from js import Response\n\ndef on_fetch(request):\n    return Response.new('Hello world')\n"
+    }
+    "add.wasm" = {
+      content_base64 = "%[3]s"
+    }
+    "index.js.map" = {
+      part = "{\"version\":3,\"sources\":[],\"mappings\":\"\"}"
+    }
+    data = {
+      part         = "raw data"
+      content_type = "application/octet-stream"
+    }
+  }
+}
@@ -0,0 +1,16 @@
+resource "cloudflare-extended_workers_script" "%[1]s" {
+  account_id  = "%[2]s"
+  script_name = "%[1]s"
+  main_module = "index.js"
+
+  parts = {
+    "index.js" = {
+      source_path = "%[3]s"
+      module      = true
+    }
+    assets = {
+      directory = "%[4]s"
+      glob      = "%[5]s"
+    }
+  }
+}
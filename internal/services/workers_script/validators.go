@@ -0,0 +1,129 @@
+package workers_script
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/customfield"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/typespec"
+)
+
+var _ resource.ConfigValidator = bindingsValidator{}
+
+// bindingSpecs lists the attributes every binding type must and may set.
+var bindingSpecs = typespec.Specs{
+	"ai":                       {},
+	"analytics_engine":         {Required: []string{"dataset"}},
+	"browser":                  {},
+	"d1":                       {Required: []string{"database_id"}},
+	"dispatch_namespace":       {Required: []string{"namespace"}},
+	"durable_object_namespace": {Required: []string{"class_name"}, Optional: []string{"script_name", "environment"}},
+	"hyperdrive":               {Required: []string{"id"}},
+	"json":                     {Required: []string{"json"}},
+	"kv_namespace":             {Required: []string{"namespace_id"}},
+	"mtls_certificate":         {Required: []string{"certificate_id"}},
+	"plain_text":               {Required: []string{"text"}},
+	"queue":                    {Required: []string{"queue_name"}},
+	"r2_bucket":                {Required: []string{"bucket_name"}},
+	"secret_text":              {Required: []string{"text"}},
+	"service":                  {Required: []string{"service"}, Optional: []string{"environment"}},
+	"vectorize":                {Required: []string{"index_name"}},
+	"version_metadata":         {},
+}
+
+// bindingsValidator checks that every binding sets the attributes its type
+// requires and none that belong to other binding types.
+type bindingsValidator struct{}
+
+func (v bindingsValidator) Description(ctx context.Context) string {
+	return v.MarkdownDescription(ctx)
+}
+
+func (v bindingsValidator) MarkdownDescription(_ context.Context) string {
+	return "Each binding must set the attributes required by its `type`, and only those allowed for it."
+}
+
+func (v bindingsValidator) ValidateResource(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var bindings customfield.NestedObjectSet[WorkersScriptBindingsModel]
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("bindings"), &bindings)...)
+	if resp.Diagnostics.HasError() || bindings.IsNull() || bindings.IsUnknown() {
+		return
+	}
+
+	models, diags := bindings.AsStructSliceT(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	for _, binding := range models {
+		if binding.Type.IsUnknown() || binding.Name.IsUnknown() {
+			continue
+		}
+
+		if binding.Name.IsNull() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("bindings"),
+				"missing binding name",
+				fmt.Sprintf("Every binding must set %q.", "name"),
+			)
+			continue
+		}
+
+		if binding.Type.IsNull() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("bindings"),
+				"missing binding type",
+				fmt.Sprintf("Binding %q must set %q.", binding.Name.ValueString(), "type"),
+			)
+			continue
+		}
+
+		attributes := binding.attributes()
+		// d1 bindings took the database ID as `id` before `database_id`, which
+		// is still accepted for one release
+		if binding.Type.ValueString() == "d1" && binding.DatabaseID.IsNull() && !binding.ID.IsNull() {
+			resp.Diagnostics.AddAttributeWarning(
+				path.Root("bindings"),
+				"deprecated d1 binding attribute",
+				fmt.Sprintf("Binding %q sets the D1 database ID as %q, which is deprecated and will be removed in the next release. Set %q instead.", binding.Name.ValueString(), "id", "database_id"),
+			)
+			attributes["database_id"], attributes["id"] = binding.ID, types.StringNull()
+		}
+
+		resp.Diagnostics.Append(bindingSpecs.Validate(typespec.Object{
+			Kind:       "binding",
+			Subject:    fmt.Sprintf("Binding %q", binding.Name.ValueString()),
+			Type:       binding.Type.ValueString(),
+			Attributes: attributes,
+			Path:       func(string) path.Path { return path.Root("bindings") },
+		})...)
+	}
+}
+
+var _ validator.String = globValidator{}
+
+// globValidator checks that a part `glob` is a pattern globFiles can match.
+type globValidator struct{}
+
+func (v globValidator) Description(ctx context.Context) string {
+	return v.MarkdownDescription(ctx)
+}
+
+func (v globValidator) MarkdownDescription(_ context.Context) string {
+	return "Must be a valid glob, without `**`."
+}
+
+func (v globValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	if err := checkGlob(req.ConfigValue.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(req.Path, "invalid glob", err.Error())
+	}
+}
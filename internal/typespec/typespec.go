@@ -0,0 +1,122 @@
+// Package typespec validates objects whose `type` attribute decides which of
+// their other attributes apply, such as Worker script bindings and queue
+// consumers.
+package typespec
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+)
+
+// Spec lists the type specific attributes a type must set and may set. Any
+// other type specific attribute is rejected.
+type Spec struct {
+	Required []string
+	Optional []string
+}
+
+// Specs maps every supported type to its Spec.
+type Specs map[string]Spec
+
+// Types returns the supported types in alphabetical order.
+func (s Specs) Types() []string {
+	types := make([]string, 0, len(s))
+	for t := range s {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// Object is an object checked against the Spec of its type.
+type Object struct {
+	// Kind names the kind of object in diagnostic summaries, e.g. "binding".
+	Kind string
+
+	// Subject names the object in diagnostic details, e.g. `Binding "kv"`.
+	Subject string
+
+	// Type is the value of the `type` attribute of the object.
+	Type string
+
+	// Attributes holds the type specific attributes by name.
+	Attributes map[string]attr.Value
+
+	// Path returns the path diagnostics about an attribute are reported at.
+	Path func(name string) path.Path
+}
+
+// Validate checks that the object sets the attributes its type requires and
+// none that belong to other types. Types without a Spec are skipped, they are
+// reported by the attribute validator on `type`.
+func (s Specs) Validate(o Object) (diags diag.Diagnostics) {
+	spec, ok := s[o.Type]
+	if !ok {
+		return
+	}
+
+	names := make([]string, 0, len(o.Attributes))
+	for name := range o.Attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value := o.Attributes[name]
+		required := slices.Contains(spec.Required, name)
+		allowed := required || slices.Contains(spec.Optional, name)
+
+		if required && value.IsNull() {
+			diags.AddAttributeError(
+				o.Path(name),
+				fmt.Sprintf("missing %s attribute", o.Kind),
+				fmt.Sprintf("%s of type %q must set %q.", o.Subject, o.Type, name),
+			)
+		}
+
+		if !allowed && !value.IsNull() && !value.IsUnknown() {
+			diags.AddAttributeError(
+				o.Path(name),
+				fmt.Sprintf("unsupported %s attribute", o.Kind),
+				fmt.Sprintf(
+					"%s of type %q does not support %q. Supported attributes: %s.",
+					o.Subject,
+					o.Type,
+					name,
+					spec.supported(),
+				),
+			)
+		}
+	}
+
+	return
+}
+
+func (s Spec) supported() string {
+	names := append(slices.Clone(s.Required), s.Optional...)
+	if len(names) == 0 {
+		return "none"
+	}
+
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = fmt.Sprintf("%q", name)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// AttributePath converts a dotted attribute name to its path in the schema.
+func AttributePath(name string) path.Path {
+	steps := strings.Split(name, ".")
+	p := path.Root(steps[0])
+	for _, step := range steps[1:] {
+		p = p.AtName(step)
+	}
+	return p
+}
@@ -0,0 +1,83 @@
+package typespec_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/typespec"
+)
+
+var specs = typespec.Specs{
+	"a": {Required: []string{"x"}, Optional: []string{"y"}},
+	"b": {},
+}
+
+func TestTypes(t *testing.T) {
+	if got := specs.Types(); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Fatalf("unexpected types: %v", got)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cases := map[string]struct {
+		typ        string
+		attributes map[string]attr.Value
+		errors     []string
+	}{
+		"valid": {
+			typ:        "a",
+			attributes: map[string]attr.Value{"x": types.StringValue("x"), "y": types.StringValue("y"), "z": types.StringNull()},
+		},
+		"unknown type": {
+			typ:        "c",
+			attributes: map[string]attr.Value{"z": types.StringValue("z")},
+		},
+		"unknown unsupported": {
+			typ:        "b",
+			attributes: map[string]attr.Value{"x": types.StringUnknown()},
+		},
+		"missing": {
+			typ:        "a",
+			attributes: map[string]attr.Value{"x": types.StringNull()},
+			errors:     []string{`Thing "t" of type "a" must set "x".`},
+		},
+		"unsupported": {
+			typ:        "b",
+			attributes: map[string]attr.Value{"y": types.StringValue("y"), "x": types.StringValue("x")},
+			errors: []string{
+				`Thing "t" of type "b" does not support "x". Supported attributes: none.`,
+				`Thing "t" of type "b" does not support "y". Supported attributes: none.`,
+			},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			diags := specs.Validate(typespec.Object{
+				Kind:       "thing",
+				Subject:    `Thing "t"`,
+				Type:       c.typ,
+				Attributes: c.attributes,
+				Path:       typespec.AttributePath,
+			})
+
+			var errors []string
+			for _, d := range diags.Errors() {
+				errors = append(errors, d.Detail())
+			}
+			if !reflect.DeepEqual(errors, c.errors) {
+				t.Fatalf("unexpected errors:\n%s", strings.Join(errors, "\n"))
+			}
+		})
+	}
+}
+
+func TestAttributePath(t *testing.T) {
+	if got := typespec.AttributePath("settings.batch_size"); !got.Equal(path.Root("settings").AtName("batch_size")) {
+		t.Fatalf("unexpected path: %s", got)
+	}
+}
@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+
+	"github.com/cloudflare/cloudflare-go/v3"
+)
+
+// IsNotFound reports whether err is a Cloudflare API error signalling that the
+// requested object does not exist.
+//
+// A 404 status is always treated as not found. Some endpoints instead answer
+// with a 400 or 403 and a resource specific error code, so callers can pass the
+// API error codes that mean "not found" for the object they are reading.
+func IsNotFound(err error, codes ...int64) bool {
+	var apiErr *cloudflare.Error
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+
+	if apiErr.StatusCode == http.StatusNotFound {
+		return true
+	}
+
+	for _, e := range apiErr.Errors {
+		if slices.Contains(codes, e.Code) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -1,6 +1,9 @@
 package utils
 
-import "math/rand"
+import (
+	"math/rand"
+	"strings"
+)
 
 const (
 	// CharSetAlphaNum is the alphanumeric character set for use with
@@ -11,18 +14,28 @@ const (
 	// RandStringFromCharSet.
 	CharSetAlpha = "abcdefghijklmnopqrstuvwxyz"
 
-	// Length of the resource name we wish to generate.
+	// Length of the random part of the resource name we wish to generate.
 	ResourceNameLength = 10
+
+	// ResourceNamePrefix starts the name of every resource created by the
+	// acceptance tests, so that test sweepers can tell leaked resources apart.
+	ResourceNamePrefix = "tf-acc-"
 )
 
 // GenerateRandomResourceName builds a unique-ish resource identifier to use in
-// tests.
+// tests, starting with ResourceNamePrefix.
 func GenerateRandomResourceName() string {
 	result := make([]byte, ResourceNameLength)
 	for i := 0; i < ResourceNameLength; i++ {
 		result[i] = CharSetAlpha[randIntRange(0, len(CharSetAlpha))]
 	}
-	return string(result)
+	return ResourceNamePrefix + string(result)
+}
+
+// IsTestResourceName reports whether name was generated by
+// GenerateRandomResourceName.
+func IsTestResourceName(name string) bool {
+	return strings.HasPrefix(name, ResourceNamePrefix)
 }
 
 // RandStringFromCharSet generates a random string by selecting characters from
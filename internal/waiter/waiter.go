@@ -0,0 +1,196 @@
+// Package waiter polls a resource until it reaches a target state, for APIs
+// that only become consistent some time after a write.
+package waiter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cloudflare/cloudflare-go/v3"
+	"github.com/jasonpanosso/terraform-provider-cloudflare-extended/internal/logging"
+)
+
+const (
+	// DefaultMinInterval is the default delay before the second refresh.
+	DefaultMinInterval = 500 * time.Millisecond
+
+	// DefaultMaxInterval is the default upper bound of the delay between
+	// refreshes.
+	DefaultMaxInterval = 10 * time.Second
+)
+
+// RefreshFunc returns the current state of the waited for resource, and a
+// result returned by WaitForState once the state is a target one.
+type RefreshFunc func(ctx context.Context) (result any, state string, err error)
+
+// StateChangeConf describes how to wait for a resource to reach a target
+// state.
+type StateChangeConf struct {
+	// Description names what is waited for in logs and errors, e.g.
+	// "r2 event notification rules".
+	Description string
+
+	// Pending are the states the resource may pass through. Any other state
+	// that is not a target one ends the wait with an UnexpectedStateError. No
+	// pending states allow any state.
+	Pending []string
+
+	// Target are the states that end the wait.
+	Target []string
+
+	Refresh RefreshFunc
+
+	// Timeout bounds the wait, in addition to the deadline of the context. A
+	// zero timeout only uses the context.
+	Timeout time.Duration
+
+	// MinInterval is the delay before the second refresh, doubled after every
+	// following one. Defaults to DefaultMinInterval.
+	MinInterval time.Duration
+
+	// MaxInterval caps the delay between refreshes. Defaults to
+	// DefaultMaxInterval.
+	MaxInterval time.Duration
+
+	// Transient reports whether a refresh error may go away on the next
+	// refresh, rather than ending the wait. Defaults to IsTransient.
+	Transient func(err error) bool
+}
+
+// TimeoutError is returned when the resource did not reach a target state in
+// time.
+type TimeoutError struct {
+	Description string
+	LastState   string
+	LastError   error
+	Elapsed     time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	msg := fmt.Sprintf("timed out after %s waiting for %s", e.Elapsed.Round(time.Second), e.Description)
+	if e.LastState != "" {
+		msg += fmt.Sprintf(", last state %q", e.LastState)
+	}
+	if e.LastError != nil {
+		msg += fmt.Sprintf(", last error: %s", e.LastError)
+	}
+	return msg
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return e.LastError
+}
+
+// UnexpectedStateError is returned when the resource reaches a state that is
+// neither pending nor a target one.
+type UnexpectedStateError struct {
+	Description string
+	State       string
+	Expected    []string
+}
+
+func (e *UnexpectedStateError) Error() string {
+	return fmt.Sprintf("unexpected state %q of %s, wanted one of %s", e.State, e.Description, strings.Join(e.Expected, ", "))
+}
+
+// WaitForState refreshes the resource with exponential backoff until it
+// reaches a target state, returning the result of the last refresh.
+func (c StateChangeConf) WaitForState(ctx context.Context) (any, error) {
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+
+	interval := c.MinInterval
+	if interval <= 0 {
+		interval = DefaultMinInterval
+	}
+	maxInterval := c.MaxInterval
+	if maxInterval <= 0 {
+		maxInterval = DefaultMaxInterval
+	}
+	transient := c.Transient
+	if transient == nil {
+		transient = IsTransient
+	}
+
+	start := time.Now()
+	var lastState string
+	var lastErr error
+	for attempt := 1; ; attempt++ {
+		result, state, err := c.Refresh(ctx)
+		switch {
+		case ctx.Err() != nil:
+			// the refresh failed because the wait is over
+		case err != nil && !transient(err):
+			return nil, err
+		case err != nil:
+			lastErr = err
+		case slices.Contains(c.Target, state):
+			return result, nil
+		case len(c.Pending) > 0 && !slices.Contains(c.Pending, state):
+			return nil, &UnexpectedStateError{
+				Description: c.Description,
+				State:       state,
+				Expected:    append(slices.Clone(c.Pending), c.Target...),
+			}
+		default:
+			lastState, lastErr = state, nil
+		}
+
+		if ctx.Err() == nil {
+			logging.LogWait(ctx, c.Description, lastState, lastErr, attempt, interval)
+		}
+		if err := sleep(ctx, interval); err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				return nil, &TimeoutError{
+					Description: c.Description,
+					LastState:   lastState,
+					LastError:   lastErr,
+					Elapsed:     time.Since(start),
+				}
+			}
+			return nil, err
+		}
+
+		interval = min(2*interval, maxInterval)
+	}
+}
+
+// IsTransient reports whether an API error may go away when the request is
+// sent again: a connection error, a timeout, a rate limit or a server error.
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var apiErr *cloudflare.Error
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusRequestTimeout ||
+			apiErr.StatusCode == http.StatusTooManyRequests ||
+			apiErr.StatusCode >= http.StatusInternalServerError
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+func sleep(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,152 @@
+package waiter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/cloudflare/cloudflare-go/v3"
+)
+
+// fakeRefresh answers refreshes with the given states in order, an error
+// when the state is empty, and the last state once they run out.
+type fakeRefresh struct {
+	states []string
+	err    error
+	calls  int
+}
+
+func (f *fakeRefresh) refresh(ctx context.Context) (any, string, error) {
+	state := f.states[min(f.calls, len(f.states)-1)]
+	f.calls++
+	if state == "" {
+		return nil, "", f.err
+	}
+	return f.calls, state, nil
+}
+
+func fastConf(f *fakeRefresh) StateChangeConf {
+	return StateChangeConf{
+		Description: "thing",
+		Pending:     []string{"pending"},
+		Target:      []string{"ready"},
+		Refresh:     f.refresh,
+		MinInterval: time.Millisecond,
+		MaxInterval: 4 * time.Millisecond,
+	}
+}
+
+func apiError(status int) error {
+	req, _ := http.NewRequest(http.MethodGet, "https://api.cloudflare.com/client/v4/accounts/a/queues", nil)
+	return &cloudflare.Error{
+		StatusCode: status,
+		Request:    req,
+		Response:   &http.Response{StatusCode: status},
+	}
+}
+
+func TestWaitForState(t *testing.T) {
+	f := &fakeRefresh{states: []string{"pending", "pending", "ready"}}
+
+	result, err := fastConf(f).WaitForState(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != 3 || f.calls != 3 {
+		t.Errorf("expected the result of the third refresh, got %v after %d refreshes", result, f.calls)
+	}
+}
+
+func TestWaitForStateRetriesTransientErrors(t *testing.T) {
+	f := &fakeRefresh{states: []string{"", "", "ready"}, err: apiError(http.StatusServiceUnavailable)}
+
+	if _, err := fastConf(f).WaitForState(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if f.calls != 3 {
+		t.Errorf("expected 3 refreshes, got %d", f.calls)
+	}
+}
+
+func TestWaitForStateStopsOnPermanentErrors(t *testing.T) {
+	f := &fakeRefresh{states: []string{"pending", "", "ready"}, err: apiError(http.StatusForbidden)}
+
+	_, err := fastConf(f).WaitForState(context.Background())
+	if !errors.Is(err, f.err) {
+		t.Fatalf("expected the refresh error, got %v", err)
+	}
+	if f.calls != 2 {
+		t.Errorf("expected 2 refreshes, got %d", f.calls)
+	}
+}
+
+func TestWaitForStateUnexpectedState(t *testing.T) {
+	f := &fakeRefresh{states: []string{"pending", "failed"}}
+
+	_, err := fastConf(f).WaitForState(context.Background())
+	var stateErr *UnexpectedStateError
+	if !errors.As(err, &stateErr) || stateErr.State != "failed" {
+		t.Fatalf("expected an unexpected state error, got %v", err)
+	}
+}
+
+func TestWaitForStateTimeout(t *testing.T) {
+	f := &fakeRefresh{states: []string{"pending"}}
+	conf := fastConf(f)
+	conf.Timeout = 20 * time.Millisecond
+
+	_, err := conf.WaitForState(context.Background())
+	var timeoutErr *TimeoutError
+	if !errors.As(err, &timeoutErr) || timeoutErr.LastState != "pending" {
+		t.Fatalf("expected a timeout error in the pending state, got %v", err)
+	}
+	if f.calls < 2 {
+		t.Errorf("expected several refreshes before timing out, got %d", f.calls)
+	}
+}
+
+func TestWaitForStateCancel(t *testing.T) {
+	f := &fakeRefresh{states: []string{"pending"}}
+	conf := fastConf(f)
+	conf.MinInterval = time.Hour
+	conf.MaxInterval = time.Hour
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := conf.WaitForState(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the wait to be canceled, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected the wait to stop sleeping when canceled, took %s", elapsed)
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{apiError(http.StatusTooManyRequests), true},
+		{apiError(http.StatusRequestTimeout), true},
+		{apiError(http.StatusBadGateway), true},
+		{apiError(http.StatusNotFound), false},
+		{apiError(http.StatusBadRequest), false},
+		{fmt.Errorf("reading response: %w", io.ErrUnexpectedEOF), true},
+		{context.DeadlineExceeded, false},
+		{errors.New("invalid configuration"), false},
+	}
+
+	for _, tt := range tests {
+		if got := IsTransient(tt.err); got != tt.want {
+			t.Errorf("IsTransient(%v) = %t, want %t", tt.err, got, tt.want)
+		}
+	}
+}